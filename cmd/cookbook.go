@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/config"
+	"github.com/kodelint/shell-agent/internal/cookbook"
+	"github.com/kodelint/shell-agent/internal/feedback"
+	"github.com/kodelint/shell-agent/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cookbookFromLast     bool
+	cookbookFromFeedback bool
+	cookbookCommand      string
+	cookbookDescription  string
+	cookbookTags         []string
+	cookbookFilter       string
+)
+
+var cookbookCmd = &cobra.Command{
+	Use:   "cookbook",
+	Short: "Keep a curated cookbook of verified commands",
+	Long: `Keep a cookbook of named commands that are known to work in your environment.
+
+Unlike history, the cookbook only holds commands you chose to keep. An entry is
+verified when the feedback log records that its command worked. The cookbook
+is stored in ~/.shell-agent/cookbook.json.
+
+Examples:
+  shell-agent cookbook add disk-usage --from-last        # Keep the last generated command
+  shell-agent cookbook add backup --from-feedback        # Promote the last command that worked
+  shell-agent cookbook add ports --command "lsof -i -P" --description "open ports" --tag network
+  shell-agent cookbook list --tag network                # List entries tagged 'network'
+  shell-agent cookbook show disk-usage                   # Show an entry
+  shell-agent cookbook run disk-usage                    # Run an entry after confirmation
+  shell-agent cookbook remove disk-usage                 # Delete an entry`,
+}
+
+var cookbookAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a command to the cookbook",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		entry, err := newCookbookEntry(args[0])
+		if err != nil {
+			output.PrintError(err.Error())
+			os.Exit(1)
+		}
+
+		saved, err := mustCookbookStore().Add(entry)
+		if err != nil {
+			output.PrintError(fmt.Sprintf("Failed to add cookbook entry: %v", err))
+			os.Exit(1)
+		}
+
+		output.PrintSuccess(fmt.Sprintf("Added %s: %s", saved.Name, saved.Command))
+		if !saved.Verified {
+			output.PrintInfo("💡 Not verified yet: no feedback says this command worked")
+		}
+	},
+}
+
+var cookbookListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cookbook entries, optionally filtered by tag",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		list, err := mustCookbookStore().List(cookbookFilter)
+		if err != nil {
+			output.PrintError(fmt.Sprintf("Failed to list cookbook: %v", err))
+			os.Exit(1)
+		}
+
+		if len(list) == 0 {
+			output.PrintInfo("The cookbook is empty")
+			return
+		}
+
+		for _, e := range list {
+			line := fmt.Sprintf("%s %-20s %s", verifiedMark(e), e.Name, e.Command)
+			if len(e.Tags) > 0 {
+				line += fmt.Sprintf("  [%s]", strings.Join(e.Tags, ", "))
+			}
+			output.PrintInfo(line)
+		}
+	},
+}
+
+var cookbookShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a cookbook entry",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		entry := mustCookbookEntry(args[0])
+
+		output.PrintInfo(fmt.Sprintf("Name:        %s", entry.Name))
+		output.PrintInfo(fmt.Sprintf("Command:     %s", entry.Command))
+		if entry.Description != "" {
+			output.PrintInfo(fmt.Sprintf("Description: %s", entry.Description))
+		}
+		if len(entry.Tags) > 0 {
+			output.PrintInfo(fmt.Sprintf("Tags:        %s", strings.Join(entry.Tags, ", ")))
+		}
+		output.PrintInfo(fmt.Sprintf("Verified:    %t", entry.Verified))
+		output.PrintInfo(fmt.Sprintf("Added:       %s", entry.CreatedAt.Format("2006-01-02 15:04")))
+	},
+}
+
+var cookbookRunCmd = &cobra.Command{
+	Use:   "run <name>",
+	Short: "Run a cookbook entry",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		entry := mustCookbookEntry(args[0])
+
+		// Cookbook entries run through the same safety checks and confirmation as generated commands
+		response := &ai.CommandResponse{
+			Command:     entry.Command,
+			Explanation: entry.Description,
+			Confidence:  1,
+			Mode:        ai.ModeShell,
+		}
+		if cfg, err := config.Load(); err == nil {
+			ai.NewSafetyChecker(cfg).CheckCommand(response)
+		}
+		output.PrintResponse(response)
+
+		if _, err := executeResponse(response, nil); err != nil && !errors.Is(err, output.ErrDeclined) {
+			os.Exit(1)
+		}
+	},
+}
+
+var cookbookRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Delete a cookbook entry",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		entry := mustCookbookEntry(args[0])
+		if err := mustCookbookStore().Remove(entry.Name); err != nil {
+			output.PrintError(err.Error())
+			os.Exit(1)
+		}
+		output.PrintSuccess(fmt.Sprintf("Removed %s", entry.Name))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cookbookCmd)
+	cookbookCmd.AddCommand(cookbookAddCmd, cookbookListCmd, cookbookShowCmd, cookbookRunCmd, cookbookRemoveCmd)
+
+	cookbookAddCmd.Flags().BoolVar(&cookbookFromLast, "from-last", false, "Add the most recently generated command")
+	cookbookAddCmd.Flags().BoolVar(&cookbookFromFeedback, "from-feedback", false, "Promote the most recent command that feedback says worked")
+	cookbookAddCmd.Flags().StringVarP(&cookbookCommand, "command", "c", "", "Command to add")
+	cookbookAddCmd.Flags().StringVar(&cookbookDescription, "description", "", "What the command does (defaults to the prompt that generated it)")
+	cookbookAddCmd.Flags().StringSliceVarP(&cookbookTags, "tag", "t", nil, "Tag to attach (repeatable or comma-separated)")
+	cookbookAddCmd.MarkFlagsMutuallyExclusive("from-last", "from-feedback", "command")
+	cookbookAddCmd.MarkFlagsOneRequired("from-last", "from-feedback", "command")
+	cookbookListCmd.Flags().StringVarP(&cookbookFilter, "tag", "t", "", "Only list entries with this tag")
+}
+
+// newCookbookEntry builds an entry from the add flags. Entries are verified
+// when the feedback log says their command worked.
+func newCookbookEntry(name string) (cookbook.Entry, error) {
+	feedbackLog := loadFeedback()
+
+	var entry cookbook.Entry
+	switch {
+	case cookbookFromFeedback:
+		f, ok := cookbook.LastWorked(feedbackLog, "")
+		if !ok {
+			return entry, fmt.Errorf("no feedback says a command worked yet")
+		}
+		var err error
+		if entry, err = cookbook.FromFeedback(name, f); err != nil {
+			return entry, err
+		}
+	case cookbookFromLast:
+		last, err := lastHistoryEntry()
+		if err != nil {
+			return entry, err
+		}
+		entry = cookbook.Entry{Name: name, Command: last.Command, Description: last.Prompt}
+	default:
+		entry = cookbook.Entry{Name: name, Command: cookbookCommand}
+	}
+
+	if !entry.Verified {
+		_, entry.Verified = cookbook.LastWorked(feedbackLog, entry.Command)
+	}
+	if cookbookDescription != "" {
+		entry.Description = cookbookDescription
+	}
+	entry.Tags = cookbookTags
+	return entry, nil
+}
+
+// loadFeedback returns the feedback log; a missing or unreadable log counts as empty
+func loadFeedback() []feedback.Feedback {
+	manager, err := feedback.NewManager()
+	if err != nil {
+		return nil
+	}
+	list, _ := manager.LoadFeedback()
+	return list
+}
+
+// verifiedMark flags verified entries in the cookbook list
+func verifiedMark(entry cookbook.Entry) string {
+	if entry.Verified {
+		return "✅"
+	}
+	return "  "
+}
+
+func mustCookbookStore() *cookbook.Store {
+	store, err := cookbook.NewStore()
+	if err != nil {
+		output.PrintError(fmt.Sprintf("Failed to open cookbook: %v", err))
+		os.Exit(1)
+	}
+	return store
+}
+
+// mustCookbookEntry looks up a cookbook entry by name or exits
+func mustCookbookEntry(name string) *cookbook.Entry {
+	entry, err := mustCookbookStore().Get(name)
+	if err != nil {
+		output.PrintError(err.Error())
+		os.Exit(1)
+	}
+	return entry
+}
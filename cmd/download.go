@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 
@@ -58,6 +59,10 @@ func runDownload(cmd *cobra.Command, args []string) {
 
 		selectedModel, err := output.PromptModelSelection(modelManager.ListAvailableModels())
 		if err != nil {
+			if errors.Is(err, output.ErrNoTerminal) {
+				output.PrintError("Model selection needs a terminal; choose a model with --model, e.g. shell-agent download --model llama3.2:3b")
+				return
+			}
 			if err.Error() == "^C" {
 				output.PrintInfo("❌ Download cancelled")
 				return
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/config"
+	"github.com/kodelint/shell-agent/internal/logger"
+	"github.com/kodelint/shell-agent/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var evalParsingCmd = &cobra.Command{
+	Use:   "eval-parsing",
+	Short: "Measure how reliably models return parseable JSON",
+	Long: `Run a set of prompts through one or more models and report the fraction
+of responses that were parsed as JSON without falling back to heuristic
+command extraction, along with the average confidence.
+
+Examples:
+  shell-agent eval-parsing                                  # Evaluate the current model
+  shell-agent eval-parsing --models llama3.2:3b,codegemma:7b
+  shell-agent eval-parsing --prompts prompts.txt            # One prompt per line`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runEvalParsing(cmd, args)
+	},
+}
+
+var (
+	evalModels      []string
+	evalPromptsFile string
+)
+
+// defaultEvalPrompts is used when no prompts file is given
+var defaultEvalPrompts = []string{
+	"list all files in current directory",
+	"find all python files modified in last 7 days",
+	"show disk usage of current directory",
+	"compress folder into tar.gz",
+	"show running processes using port 8080",
+}
+
+func init() {
+	rootCmd.AddCommand(evalParsingCmd)
+
+	evalParsingCmd.Flags().StringSliceVar(&evalModels, "models", nil, "Comma-separated models to evaluate (default is the current model)")
+	evalParsingCmd.Flags().StringVar(&evalPromptsFile, "prompts", "", "File with one prompt per line")
+}
+
+func runEvalParsing(cmd *cobra.Command, args []string) {
+	log := logger.GetLogger()
+	log.Info("Starting parse evaluation")
+
+	prompts := defaultEvalPrompts
+	if evalPromptsFile != "" {
+		loaded, err := readPromptsFile(evalPromptsFile)
+		if err != nil {
+			output.PrintError(fmt.Sprintf("Failed to read prompts: %v", err))
+			os.Exit(1)
+		}
+		prompts = loaded
+	}
+
+	models := evalModels
+	if len(models) == 0 {
+		models = []string{config.GetDefaultModel()}
+	}
+
+	aiClient, err := ai.NewClient()
+	if err != nil {
+		output.PrintError(fmt.Sprintf("Failed to initialize AI client: %v", err))
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := aiClient.IsAvailable(ctx); err != nil {
+		output.PrintError(fmt.Sprintf("AI provider is not available: %v", err))
+		os.Exit(1)
+	}
+
+	output.PrintInfo(fmt.Sprintf("🧪 Evaluating %d prompt(s) across %d model(s)...", len(prompts), len(models)))
+	results := aiClient.EvaluateParsing(context.Background(), models, prompts)
+	output.PrintParseEvaluation(results)
+}
+
+// readPromptsFile reads non-empty, non-comment lines from a file
+func readPromptsFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var prompts []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		prompts = append(prompts, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(prompts) == 0 {
+		return nil, fmt.Errorf("no prompts found in %s", path)
+	}
+	return prompts, nil
+}
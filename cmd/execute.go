@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/output"
+	"github.com/spf13/viper"
+)
+
+// executeOptions builds the execution rules from the interactive and safety config.
+// shutdownSignals is paused during the explain-before-execute countdown; it may be nil.
+func executeOptions(shutdownSignals chan os.Signal) output.ExecOptions {
+	opts := output.ExecOptions{
+		AutoExecute:      viper.GetBool("interactive.auto_execute"),
+		ConfirmCommands:  viper.GetBool("interactive.confirm_commands"),
+		RequireConfirm:   viper.GetBool("safety.require_confirm"),
+		BlockDestructive: viper.GetBool("safety.block_destructive"),
+		BlockComplex:     viper.GetString("safety.complexity_action") == ai.ComplexityBlock,
+		ConfirmAboveRisk: viper.GetInt("safety.confirm_above_risk"),
+		Allowlist:        viper.GetStringSlice("safety.exec_allowlist"),
+		PageOutput:       viper.GetBool("interactive.page_output"),
+	}
+	if viper.GetBool("interactive.explain_before_execute") {
+		opts.Pause = func(response *ai.CommandResponse) bool {
+			return pauseBeforeExecute(response, shutdownSignals)
+		}
+	}
+	return opts
+}
+
+// executeResponse runs a generated command and prints why it did not run. It
+// reports whether the command was started, and returns output.ErrDeclined when
+// the user declined it or the command's error when it failed.
+func executeResponse(response *ai.CommandResponse, shutdownSignals chan os.Signal) (bool, error) {
+	err := output.RunResponse(response, executeOptions(shutdownSignals))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, output.ErrDeclined):
+		return false, err
+	case errors.Is(err, output.ErrCancelled):
+		output.PrintInfo("Execution cancelled")
+		return false, nil
+	case errors.Is(err, output.ErrBlocked), errors.Is(err, output.ErrTooComplex), errors.Is(err, output.ErrNotAllowed):
+		output.PrintError(err.Error())
+		return false, nil
+	}
+
+	output.PrintError(fmt.Sprintf("Command execution failed: %v", err))
+	return true, err
+}
+
+// previewCommand shows what a destructive command would affect without running
+// it. When quiet, commands that have no preview are passed over silently.
+func previewCommand(command string, quiet bool) {
+	preview, matched, err := ai.PreviewDestructive(command)
+	switch {
+	case errors.Is(err, ai.ErrNoPreview):
+		if !quiet {
+			output.PrintInfo("💡 Previews are available for rm, mv and find -delete commands")
+		}
+	case err != nil:
+		output.PrintWarning(fmt.Sprintf("Could not preview command: %v", err))
+	default:
+		output.PrintPreview(preview, matched)
+	}
+}
+
+// fillTemplate asks for the placeholder values of a --template command and
+// returns the filled command, checked for safety again. It reports false when
+// the command can't be filled in and must not run.
+func fillTemplate(aiClient *ai.Client, response *ai.CommandResponse) (*ai.CommandResponse, bool) {
+	names := ai.Placeholders(response.Command)
+	if !templateMode || len(names) == 0 {
+		return response, true
+	}
+
+	values, err := output.PlaceholderFill(names)
+	switch {
+	case errors.Is(err, output.ErrNoTerminal):
+		output.PrintWarning("Not executing: filling in placeholders needs a terminal")
+		return response, false
+	case err != nil:
+		output.PrintInfo("Execution cancelled")
+		return response, false
+	}
+
+	filled, err := aiClient.FillTemplate(response, values)
+	if err != nil {
+		output.PrintError(err.Error())
+		return response, false
+	}
+
+	output.PrintInfo(fmt.Sprintf("📝 Filled in: %s", filled.Command))
+	printFindings(filled)
+	return filled, true
+}
+
+// printFindings prints the findings of a command that was checked again after
+// the response was shown, e.g. a selected alternative
+func printFindings(response *ai.CommandResponse) {
+	for _, finding := range response.Findings {
+		output.PrintWarning(finding.Message)
+	}
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/config"
+	"github.com/kodelint/shell-agent/internal/favorites"
+	"github.com/kodelint/shell-agent/internal/history"
+	"github.com/kodelint/shell-agent/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	favoriteTags    []string
+	favoriteCommand string
+	favoriteFilter  string
+)
+
+var favoriteCmd = &cobra.Command{
+	Use:   "favorite",
+	Short: "Save and reuse generated commands",
+	Long: `Save generated commands as favorites, tag them, and run them again later.
+
+Favorites are stored in ~/.shell-agent/favorites.json. Without --command,
+'favorite add' saves the most recently generated command.
+
+Examples:
+  shell-agent favorite add --tag backup --tag nightly   # Save the last command
+  shell-agent favorite add --command "df -h" --tag disk # Save a specific command
+  shell-agent favorite list --tag backup                # List favorites tagged 'backup'
+  shell-agent favorite run 3                            # Run favorite #3
+  shell-agent favorite remove 3                         # Delete favorite #3`,
+}
+
+var favoriteAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Save the last generated command (or --command) as a favorite",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		command, prompt := favoriteCommand, ""
+		if command == "" {
+			last, err := lastHistoryEntry()
+			if err != nil {
+				output.PrintError(err.Error())
+				output.PrintInfo("💡 Generate a command first, or pass --command")
+				os.Exit(1)
+			}
+			command, prompt = last.Command, last.Prompt
+		}
+
+		favorite, err := mustFavoritesStore().Add(command, prompt, favoriteTags)
+		if err != nil {
+			output.PrintError(fmt.Sprintf("Failed to save favorite: %v", err))
+			os.Exit(1)
+		}
+		output.PrintSuccess(fmt.Sprintf("Saved favorite #%d: %s", favorite.ID, favorite.Command))
+	},
+}
+
+var favoriteListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List favorites, optionally filtered by tag",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		list, err := mustFavoritesStore().List(favoriteFilter)
+		if err != nil {
+			output.PrintError(fmt.Sprintf("Failed to list favorites: %v", err))
+			os.Exit(1)
+		}
+
+		if len(list) == 0 {
+			output.PrintInfo("No favorites found")
+			return
+		}
+
+		for _, f := range list {
+			line := fmt.Sprintf("#%-3d %s", f.ID, f.Command)
+			if len(f.Tags) > 0 {
+				line += fmt.Sprintf("  [%s]", strings.Join(f.Tags, ", "))
+			}
+			output.PrintInfo(line)
+		}
+	},
+}
+
+var favoriteRunCmd = &cobra.Command{
+	Use:   "run <id>",
+	Short: "Run a saved favorite",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		favorite := mustFavorite(args[0])
+
+		// Favorites run through the same safety checks and confirmation as generated commands
+		response := &ai.CommandResponse{
+			Command:     favorite.Command,
+			Explanation: favorite.Prompt,
+			Confidence:  1,
+			Mode:        ai.ModeShell,
+		}
+		if cfg, err := config.Load(); err == nil {
+			ai.NewSafetyChecker(cfg).CheckCommand(response)
+		}
+		output.PrintResponse(response)
+
+		if _, err := executeResponse(response, nil); err != nil && !errors.Is(err, output.ErrDeclined) {
+			os.Exit(1)
+		}
+	},
+}
+
+var favoriteRemoveCmd = &cobra.Command{
+	Use:   "remove <id>",
+	Short: "Delete a favorite",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		favorite := mustFavorite(args[0])
+		if err := mustFavoritesStore().Remove(favorite.ID); err != nil {
+			output.PrintError(err.Error())
+			os.Exit(1)
+		}
+		output.PrintSuccess(fmt.Sprintf("Removed favorite #%d", favorite.ID))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(favoriteCmd)
+	favoriteCmd.AddCommand(favoriteAddCmd, favoriteListCmd, favoriteRunCmd, favoriteRemoveCmd)
+
+	favoriteAddCmd.Flags().StringSliceVarP(&favoriteTags, "tag", "t", nil, "Tag to attach (repeatable or comma-separated)")
+	favoriteAddCmd.Flags().StringVarP(&favoriteCommand, "command", "c", "", "Command to save instead of the last generated one")
+	favoriteListCmd.Flags().StringVarP(&favoriteFilter, "tag", "t", "", "Only list favorites with this tag")
+}
+
+func mustFavoritesStore() *favorites.Store {
+	store, err := favorites.NewStore()
+	if err != nil {
+		output.PrintError(fmt.Sprintf("Failed to open favorites: %v", err))
+		os.Exit(1)
+	}
+	return store
+}
+
+// mustFavorite looks up a favorite by its numeric ID or exits
+func mustFavorite(arg string) *favorites.Favorite {
+	id, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	if err != nil {
+		output.PrintError(fmt.Sprintf("Invalid favorite ID %q", arg))
+		os.Exit(1)
+	}
+
+	favorite, err := mustFavoritesStore().Get(id)
+	if err != nil {
+		output.PrintError(err.Error())
+		os.Exit(1)
+	}
+	return favorite
+}
+
+// lastHistoryEntry returns the most recently generated command
+func lastHistoryEntry() (*history.Entry, error) {
+	store, err := history.NewStore()
+	if err != nil {
+		return nil, err
+	}
+
+	last, err := store.Last()
+	if errors.Is(err, history.ErrEmpty) {
+		return nil, fmt.Errorf("no command has been generated yet")
+	}
+	return last, err
+}
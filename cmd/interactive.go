@@ -2,7 +2,10 @@ package cmd
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"math/rand"
 	"os"
 	"os/signal"
 	"strings"
@@ -11,13 +14,27 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/config"
 	"github.com/kodelint/shell-agent/internal/feedback"
+	"github.com/kodelint/shell-agent/internal/history"
+	"github.com/kodelint/shell-agent/internal/idle"
 	"github.com/kodelint/shell-agent/internal/logger"
 	"github.com/kodelint/shell-agent/internal/output"
+	"github.com/kodelint/shell-agent/internal/report"
+	"github.com/kodelint/shell-agent/internal/shutdown"
 	"github.com/manifoldco/promptui"
+	"github.com/spf13/viper"
 )
 
-func runInteractiveMode() {
+// shutdownGracePeriod bounds how long an interrupt waits for pending writes
+const shutdownGracePeriod = 3 * time.Second
+
+// maxClarifications bounds how many clarifying questions are answered per request
+const maxClarifications = 3
+
+// runInteractiveMode starts the REPL. A non-nil conversation restores the
+// context of a previously saved session.
+func runInteractiveMode(conversation *ai.Conversation) {
 	log := logger.GetLogger()
 	log.Info("Starting interactive REPL mode")
 
@@ -31,31 +48,82 @@ func runInteractiveMode() {
 		os.Exit(1)
 	}
 
-	// Handle Ctrl+C gracefully
+	aiClient.SetTool(tool)
+	aiClient.SetTemplate(templateMode)
+	aiClient.SetObserver(&cliObserver{})
+
+	// Keep multi-turn context so follow-up requests can refer to earlier ones
+	if conversation == nil {
+		conversation = &ai.Conversation{}
+	}
+	aiClient.SetConversation(conversation)
+
+	// Handle Ctrl+C gracefully: cancel in-flight generation and let pending writes finish
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-c
+	go shutdown.Default().HandleSignals(c, shutdownGracePeriod, func() {
 		output.PrintInfo("\n🛑 Received interrupt signal")
 		output.PrintGoodbye()
 		os.Exit(0)
-	}()
+	})
+
+	declineAction, err := output.ParseDeclineAction(viper.GetString("interactive.on_decline"))
+	if err != nil {
+		output.PrintWarning(err.Error())
+	}
+	feedbackMode, err := output.ParseFeedbackMode(viper.GetString("interactive.feedback_mode"))
+	if err != nil {
+		output.PrintWarning(err.Error())
+		feedbackMode = output.FeedbackAlways
+	}
+	feedbackRate := viper.GetFloat64("interactive.feedback_sample_rate")
+
+	// The last generated command, for 'why' and refinements. The request it was
+	// generated for is kept apart from the refinements made to it.
+	var lastPrompt, lastRequest string
+	var lastConstraints []string
+	var lastResponse *ai.CommandResponse
 
 	scanner := bufio.NewScanner(os.Stdin)
 
+	// Close sessions left without input for interactive.idle_timeout minutes
+	idleMinutes := viper.GetInt("interactive.idle_timeout")
+	idleTimer := idle.NewTimer(time.Duration(idleMinutes)*time.Minute, nil, nil)
+
 	for {
+		// Generating, running and follow-up questions are not idle time
+		idleTimer.Reset()
 		output.PrintPrompt()
 
-		if !scanner.Scan() {
+		line, err := idleTimer.Scan(scanner)
+		if errors.Is(err, idle.ErrIdle) {
+			output.PrintInfo(fmt.Sprintf("\n⏰ No input for %d minute(s), closing the session", idleMinutes))
+			shutdown.Default().Shutdown(shutdownGracePeriod)
+			output.PrintGoodbye()
+			return
+		}
+		if err != nil {
 			break
 		}
 
-		input := strings.TrimSpace(scanner.Text())
+		input := strings.TrimSpace(line)
 
 		if input == "" {
 			continue
 		}
 
+		// Switch modes without leaving the REPL
+		if fields := strings.Fields(input); strings.EqualFold(fields[0], "mode") && len(fields) <= 2 {
+			switchMode(aiClient, fields[1:])
+			continue
+		}
+
+		// Save and load conversations
+		if fields := strings.Fields(input); strings.EqualFold(fields[0], "session") && len(fields) <= 3 {
+			handleSessionCommand(aiClient, fields[1:])
+			continue
+		}
+
 		// Handle special commands
 		switch strings.ToLower(input) {
 		case "exit", "quit", "q":
@@ -71,14 +139,43 @@ func runInteractiveMode() {
 			// Quick status check without exiting
 			runStatusInline()
 			continue
+		case "reset":
+			aiClient.Conversation().Reset()
+			lastPrompt, lastRequest, lastConstraints, lastResponse = "", "", nil, nil
+			output.PrintSuccess("Conversation context cleared")
+			continue
+		case "why":
+			// Ask the model to justify the last command
+			if lastResponse == nil {
+				output.PrintInfo("💡 Generate a command first, then ask 'why'")
+				continue
+			}
+			output.PrintThinking()
+			if _, err := aiClient.Why(shutdown.Context(), lastPrompt, lastResponse.Command, lastResponse.Alternatives); err != nil {
+				output.PrintError(fmt.Sprintf("Error explaining command: %v", err))
+			}
+			continue
+		case "preview":
+			// Show the impact of the last command without running it
+			if lastResponse == nil || lastResponse.Command == "" {
+				output.PrintInfo("💡 Generate a command first, then ask for a 'preview'")
+				continue
+			}
+			previewCommand(lastResponse.Command, false)
+			continue
 		}
 
-		// Store the user's original prompt for feedback
-		userPrompt := input
+		// A short modifier right after a command refines that command
+		request, constraints := input, []string(nil)
+		if lastResponse != nil && ai.IsRefinement(input) {
+			request = lastRequest
+			constraints = append(append([]string(nil), lastConstraints...), input)
+			input = ai.AppendRefinement(request, lastResponse.Command, constraints)
+		}
 
 		// Process the command with AI
 		output.PrintThinking()
-		response, err := aiClient.GenerateCommand(input)
+		response, err := aiClient.GenerateCommandContext(shutdown.Context(), input)
 		if err != nil {
 			output.PrintError(fmt.Sprintf("Error generating command: %v", err))
 			if strings.Contains(err.Error(), "no AI model available") {
@@ -87,15 +184,74 @@ func runInteractiveMode() {
 			continue
 		}
 
-		output.PrintResponse(response)
+		// Answer clarifying questions until the model produces a command
+		for clarifications := 0; response.NeedsClarification() && clarifications < maxClarifications; clarifications++ {
+			output.PrintAnswerPrompt()
+			if !scanner.Scan() {
+				break
+			}
+			answer := strings.TrimSpace(scanner.Text())
+			if answer == "" {
+				break
+			}
 
-		// Ask if user wants to execute the command
-		if output.PromptExecuteCommand() {
-			output.PrintInfo("🚀 Executing command...")
-			err := output.ExecuteCommand(response.Command)
+			input = ai.AppendClarification(input, response.Clarification, answer)
+			if constraints == nil {
+				request = input
+			}
+
+			output.PrintThinking()
+			response, err = aiClient.GenerateCommandContext(shutdown.Context(), input)
 			if err != nil {
-				output.PrintError(fmt.Sprintf("Command execution failed: %v", err))
+				break
 			}
+		}
+		if err != nil {
+			output.PrintError(fmt.Sprintf("Error generating command: %v", err))
+			continue
+		}
+
+		// Store the user's request, without model instructions, for history and feedback
+		userPrompt := ai.DescribeRefinement(request, constraints)
+
+		// Explain and review only describe a command, and a question has nothing to run
+		if response.Mode != ai.ModeShell || response.NeedsClarification() {
+			continue
+		}
+		response = simplifyResponse(aiClient, input, response)
+
+		// Let the user pick among ranked candidates, best one preselected. The
+		// pick is checked again, so its own findings decide how it may run.
+		picked := true
+		if len(response.Candidates) > 1 {
+			selected, err := output.PromptCandidateSelection(response.Candidates)
+			if err != nil {
+				picked = false
+			} else if selected != response.Command {
+				response = aiClient.SelectCandidate(response, selected)
+				printFindings(response)
+			}
+		}
+		recordHistory(userPrompt, response)
+		lastPrompt, lastResponse = userPrompt, response
+		lastRequest, lastConstraints = request, constraints
+		if !picked {
+			continue
+		}
+
+		// A templated command is filled in before it can be previewed or run
+		var filled bool
+		if response, filled = fillTemplate(aiClient, response); !filled {
+			continue
+		}
+
+		if dangerousPreview {
+			previewCommand(response.Command, true)
+		}
+
+		// Ask if user wants to execute the command
+		ran, err := executeResponse(response, c)
+		if ran && output.ShouldAskFeedback(feedbackMode, err, feedbackRate, rand.Float64) {
 			// FEEDBACK LOGIC
 			// After execution, prompt the user for feedback
 			feedbackStatus, err := output.PromptForFeedback()
@@ -129,6 +285,10 @@ func runInteractiveMode() {
 				}
 				output.PrintSuccess("✅ Feedback submitted. Thank you!")
 			}
+		} else if errors.Is(err, output.ErrDeclined) {
+			if err := output.HandleDecline(declineAction, userPrompt, response.Command); err != nil {
+				output.PrintWarning(err.Error())
+			}
 		}
 	}
 
@@ -191,14 +351,264 @@ func runSingleCommand(args []string) {
 		}
 		os.Exit(1)
 	}
+	aiClient.SetTool(tool)
+	aiClient.SetTemplate(templateMode)
+	// A plan replaces the usual output, so nothing else may be printed
+	if emit != emitPlan {
+		aiClient.SetObserver(&cliObserver{})
+	}
 
+	started := time.Now()
 	response, err := aiClient.GenerateCommand(input)
 	if err != nil {
 		output.PrintError(fmt.Sprintf("Error generating command: %v", err))
 		os.Exit(1)
 	}
 
-	output.PrintResponse(response)
+	if emit == emitPlan {
+		if response.Mode == ai.ModeShell && response.Command != "" {
+			recordHistory(input, response)
+		}
+		if err := output.PrintPlanJSON(output.BuildPlan(input, response, started, time.Since(started))); err != nil {
+			output.PrintError(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	if response.NeedsClarification() {
+		output.PrintInfo("💡 Re-run with more detail, or use interactive mode to answer the question")
+		return
+	}
+	if response.Mode == ai.ModeShell {
+		response = simplifyResponse(aiClient, input, response)
+		recordHistory(input, response)
+	}
+
+	if explainFlags && response.Mode == ai.ModeShell {
+		flags, err := aiClient.ExplainFlags(context.Background(), response.Command)
+		if err != nil {
+			output.PrintWarning(fmt.Sprintf("Could not explain flags: %v", err))
+		} else {
+			output.PrintFlagExplanations(flags)
+		}
+	}
+
+	if appendTo != "" {
+		appendFunction(input, response)
+	}
+
+	if printScript || scriptOut != "" {
+		writeScript(input, response)
+	}
+
+	if dangerousPreview && response.Mode == ai.ModeShell {
+		previewCommand(response.Command, true)
+	}
+
+	// --exec runs the command with the same confirmation rules as interactive mode
+	if execute && response.Mode == ai.ModeShell {
+		var filled bool
+		if response, filled = fillTemplate(aiClient, response); !filled {
+			return
+		}
+		_, err := executeResponse(response, nil)
+		if errors.Is(err, output.ErrDeclined) {
+			declineAction, parseErr := output.ParseDeclineAction(viper.GetString("interactive.on_decline"))
+			if parseErr == nil {
+				err = output.HandleDecline(declineAction, input, response.Command)
+			} else {
+				err = parseErr
+			}
+			if err != nil {
+				output.PrintWarning(err.Error())
+			}
+			return
+		}
+		if err != nil {
+			os.Exit(1)
+		}
+	}
+}
+
+// simplifyResponse offers to regenerate a command that is over the complexity
+// budget with an instruction to keep it simple. The original response is kept
+// when the user declines or regeneration fails.
+func simplifyResponse(aiClient *ai.Client, input string, response *ai.CommandResponse) *ai.CommandResponse {
+	if !response.TooComplex() || !output.PromptRegenerateSimpler() {
+		return response
+	}
+
+	output.PrintThinking()
+	simpler, err := aiClient.GenerateCommandContext(shutdown.Context(), ai.AppendSimplification(input))
+	if err != nil {
+		output.PrintWarning(fmt.Sprintf("Could not regenerate the command: %v", err))
+		return response
+	}
+	return simpler
+}
+
+// appendFunction appends a generated function to the --append-to rc file,
+// unless the safety checks flag it as dangerous. The rc file runs in every new
+// shell, so the body is checked even when confirmations are turned off, and
+// anything but a parsed function is refused.
+func appendFunction(request string, response *ai.CommandResponse) {
+	switch {
+	case response.Mode != ai.ModeFunction:
+		output.PrintError(fmt.Sprintf("Not appending to %s: the response is not a function", appendTo))
+		os.Exit(1)
+	case response.Fallback:
+		output.PrintError(fmt.Sprintf("Not appending to %s: the model response could not be parsed, so the function was guessed", appendTo))
+		os.Exit(1)
+	case strings.TrimSpace(response.Command) == "":
+		output.PrintError(fmt.Sprintf("Not appending to %s: the model returned no function", appendTo))
+		os.Exit(1)
+	}
+
+	refuseCritical("Not appending to "+appendTo, response.Command)
+
+	if err := output.AppendFunction(appendTo, request, response.Command); err != nil {
+		output.PrintError(err.Error())
+		os.Exit(1)
+	}
+	output.PrintSuccess(fmt.Sprintf("✅ Appended to %s; run 'source %s' to use it", appendTo, appendTo))
+}
+
+// refuseCritical exits with reason when the safety checks flag command as
+// critical. Commands saved to be run later are checked even when confirmations
+// are turned off.
+func refuseCritical(reason, command string) {
+	cfg, err := config.Load()
+	if err != nil {
+		output.PrintError(fmt.Sprintf("Failed to load config: %v", err))
+		os.Exit(1)
+	}
+
+	for _, finding := range ai.NewSafetyChecker(cfg).Findings(command) {
+		if finding.Severity == ai.SeverityCritical {
+			output.PrintError(fmt.Sprintf("%s: %s", reason, finding.Message))
+			os.Exit(1)
+		}
+	}
+}
+
+// writeScript prints the command as a script for --script and writes it to the
+// --script-out file. An executable script is not written for a command the
+// safety checks flag as critical.
+func writeScript(request string, response *ai.CommandResponse) {
+	script := output.RenderScript(request, response, output.ScriptOptions{
+		Shebang:    viper.GetString("script.shebang"),
+		StrictMode: viper.GetString("script.strict_mode"),
+	})
+
+	if printScript {
+		output.PrintScript(script)
+	}
+	if scriptOut != "" {
+		for _, step := range response.ScriptSteps() {
+			refuseCritical("Not writing "+scriptOut, step.Command)
+		}
+		if err := output.WriteScript(scriptOut, script); err != nil {
+			output.PrintError(err.Error())
+			os.Exit(1)
+		}
+		output.PrintSuccess(fmt.Sprintf("✅ Wrote %s", scriptOut))
+	}
+}
+
+// recordHistory remembers a generated command so it can be referred to later,
+// e.g. by 'favorite add' or 'report --last'. Failures are logged and otherwise ignored.
+func recordHistory(prompt string, response *ai.CommandResponse) {
+	// A response without a command has nothing to refer back to
+	if strings.TrimSpace(response.Command) != "" {
+		store, err := history.NewStore()
+		if err == nil {
+			err = store.Record(history.Entry{
+				ID:           uuid.New().String(),
+				Timestamp:    time.Now(),
+				Prompt:       prompt,
+				Command:      response.Command,
+				Model:        response.Model,
+				Alternatives: response.Alternatives,
+			})
+		}
+		if err != nil {
+			logger.GetLogger().WithError(err).Warn("Failed to record command history")
+		}
+	}
+
+	// The last request is kept in full, with the model exchange, for 'report --last',
+	// even when no command came back
+	err := report.SaveLast(report.LastRequest{
+		Timestamp: time.Now(),
+		Request:   prompt,
+		Trace:     response.Trace,
+		Result:    response,
+	})
+	if err != nil {
+		logger.GetLogger().WithError(err).Warn("Failed to record the last request")
+	}
+}
+
+// pauseBeforeExecute restates the command and counts down before it runs. While
+// counting down, Ctrl+C aborts the command instead of exiting the REPL.
+func pauseBeforeExecute(response *ai.CommandResponse, shutdownSignals chan os.Signal) bool {
+	output.PrintFinalCheck(response)
+
+	if shutdownSignals != nil {
+		signal.Stop(shutdownSignals)
+		defer signal.Notify(shutdownSignals, os.Interrupt, syscall.SIGTERM)
+	}
+
+	abort := make(chan os.Signal, 1)
+	signal.Notify(abort, os.Interrupt)
+	defer signal.Stop(abort)
+
+	return output.Countdown(viper.GetInt("interactive.explain_countdown"), abort, nil) == nil
+}
+
+// printAttemptSummary shows retries, re-prompts and escalations in verbose mode
+func printAttemptSummary(response *ai.CommandResponse) {
+	if !viper.GetBool("verbose") {
+		return
+	}
+	if summary := ai.SummarizeAttempts(response.Attempts); summary != "" {
+		output.PrintInfo("🔁 " + summary)
+	}
+}
+
+// maxThinkingPreview bounds how much of a model's thinking --verbose shows
+const maxThinkingPreview = 500
+
+// printThinking previews the model's thinking in verbose mode
+func printThinking(response *ai.CommandResponse) {
+	if !viper.GetBool("verbose") || response.Thinking == "" {
+		return
+	}
+
+	thinking := []rune(response.Thinking)
+	preview := string(thinking)
+	if len(thinking) > maxThinkingPreview {
+		preview = string(thinking[:maxThinkingPreview]) + "…"
+	}
+	output.PrintInfo("🧠 Thinking: " + preview)
+}
+
+// switchMode shows the current mode or switches to the named one
+func switchMode(aiClient *ai.Client, args []string) {
+	if len(args) == 0 {
+		output.PrintInfo(fmt.Sprintf("Current mode: %s", aiClient.Mode()))
+		return
+	}
+
+	mode, err := ai.ParseMode(args[0])
+	if err != nil {
+		output.PrintError(err.Error())
+		return
+	}
+
+	aiClient.SetMode(mode)
+	output.PrintSuccess(fmt.Sprintf("Switched to %s mode", mode))
 }
 
 func runStatusInline() {
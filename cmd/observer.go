@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/logger"
+	"github.com/kodelint/shell-agent/internal/output"
+	"github.com/sirupsen/logrus"
+)
+
+// cliObserver renders generation events with the terminal output. The output
+// is printed once the response is complete, so it takes no tokens and the
+// response is not streamed.
+type cliObserver struct{}
+
+func (o *cliObserver) ConsumesTokens() bool {
+	return false
+}
+
+func (o *cliObserver) OnToken(token string) {}
+
+func (o *cliObserver) OnSafetyFinding(finding ai.Finding) {
+	logger.GetLogger().WithFields(logrus.Fields{
+		"rule":     finding.Rule,
+		"severity": finding.Severity,
+	}).Debug("Safety finding")
+}
+
+func (o *cliObserver) OnComplete(response *ai.CommandResponse) {
+	logger.GetLogger().Debug("Generation complete")
+
+	output.PrintResponse(response)
+	printAttemptSummary(response)
+	printThinking(response)
+}
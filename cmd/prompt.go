@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	promptInput    string
+	promptMode     string
+	promptTool     string
+	promptFunction bool
+	promptTemplate bool
+)
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Inspect the prompts sent to the model",
+}
+
+var promptShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective system prompt",
+	Long: `Print the system prompt in effect after config overrides and, with --input,
+the prompt that would be sent for that request. --mode, --tool, --function and
+--template change the prompts as they would for a request. The model is not called.
+
+Examples:
+  shell-agent prompt show
+  shell-agent prompt show --input "find large log files"
+  shell-agent prompt show --mode explain --input "tar -xzvf logs.tgz"
+  shell-agent prompt show --tool kubectl --input "show pods that keep restarting"`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		switch {
+		case promptFunction:
+			viper.Set("ai.mode", string(ai.ModeFunction))
+		case promptMode != "":
+			viper.Set("ai.mode", promptMode)
+		}
+
+		aiClient, err := ai.NewClient()
+		if err != nil {
+			output.PrintError(fmt.Sprintf("Failed to initialize AI client: %v", err))
+			os.Exit(1)
+		}
+		aiClient.SetTool(promptTool)
+		aiClient.SetTemplate(promptTemplate)
+
+		enhanced := ""
+		if promptInput != "" {
+			enhanced = aiClient.EnhancedPrompt(promptInput)
+		}
+		output.PrintInfo(fmt.Sprintf("Model: %s, mode: %s", aiClient.RequestModel(), aiClient.Mode()))
+		output.PrintPromptPreview(aiClient.SystemPrompt(), enhanced)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(promptCmd)
+	promptCmd.AddCommand(promptShowCmd)
+
+	promptShowCmd.Flags().StringVar(&promptInput, "input", "", "Also show the prompt that would be sent for this request")
+	promptShowCmd.Flags().StringVar(&promptMode, "mode", "", "Mode: 'shell' (generate), 'explain' or 'review' an existing command, or 'function'")
+	promptShowCmd.Flags().StringVar(&promptTool, "tool", "", "Show the prompts for commands that use this CLI tool")
+	promptShowCmd.Flags().BoolVar(&promptFunction, "function", false, "Show the prompts for generating a shell function")
+	promptShowCmd.Flags().BoolVar(&promptTemplate, "template", false, "Show the prompts for generating a command with {{placeholders}}")
+}
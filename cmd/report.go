@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kodelint/shell-agent/internal/output"
+	"github.com/kodelint/shell-agent/internal/report"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	reportLast bool
+	reportDir  string
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Export a reproduction bundle for a bug report",
+	Long: `Write a JSON bundle with everything needed to reproduce a bad command: the
+version, the resolved config with secrets redacted, the status and system
+information, the exact prompt and raw model response, and the parsed result.
+
+The prompt may include the working directory and file names, so review the
+bundle before attaching it to an issue.
+
+Examples:
+  shell-agent "find large log files"
+  shell-agent report --last                  # Write the bundle to the current directory
+  shell-agent report --last --dir /tmp       # Write it somewhere else`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		last, err := report.LoadLast()
+		if err != nil {
+			output.PrintError(err.Error())
+			os.Exit(1)
+		}
+
+		bundle := report.Build(version, viper.AllSettings(), output.BuildStatus(), last)
+		path, err := report.Write(reportDir, bundle)
+		if err != nil {
+			output.PrintError(err.Error())
+			os.Exit(1)
+		}
+
+		output.PrintSuccess(fmt.Sprintf("✅ Wrote %s", path))
+		output.PrintInfo("💡 Review it before sharing: the prompt may mention local paths")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportCmd.Flags().BoolVar(&reportLast, "last", false, "Report the most recent request")
+	reportCmd.Flags().StringVar(&reportDir, "dir", ".", "Directory to write the bundle to")
+	reportCmd.MarkFlagRequired("last")
+}
@@ -2,19 +2,44 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/config"
 	"github.com/kodelint/shell-agent/internal/logger"
+	"github.com/kodelint/shell-agent/internal/output"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
-	cfgFile string
-	debug   bool
-	verbose bool
+	cfgFile          string
+	debug            bool
+	verbose          bool
+	inputEncoding    string
+	mode             string
+	tool             string
+	functionMode     bool
+	appendTo         string
+	execute          bool
+	printScript      bool
+	scriptOut        string
+	explainFlags     bool
+	dangerousPreview bool
+	emit             string
+	promptFile       string
+	watchPromptFile  bool
+	templateMode     bool
+	outputRoutes     map[string]string
 )
 
+// version is the build version, reported by --version and in bug reports
+var version = "dev"
+
+// emitPlan is the --emit value that prints an output.Plan as JSON
+const emitPlan = "plan"
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "shell-agent",
@@ -26,16 +51,80 @@ Examples:
   shell-agent                                    # Start interactive mode
   shell-agent "list all files in current directory"
   shell-agent "find all python files modified in last 7 days"
-  shell-agent "compress folder into tar.gz"`,
+  shell-agent "compress folder into tar.gz"
+  shell-agent --mode explain "tar -xzvf archive.tar.gz"
+  shell-agent --mode review "chmod -R 777 /var/www"
+  shell-agent --tool kubectl "show pods that keep restarting"
+  shell-agent --function --append-to ~/.bashrc "back up a folder with a timestamp"
+  cmd=$(shell-agent --route explanation=stderr,warning=stderr,status=stderr "list files")
+  shell-agent --strict-json "archive the logs directory"   # Fail instead of guessing
+  shell-agent --exec "show disk usage of this directory"   # Generate, confirm and run
+  shell-agent --explain-flags "list files with sizes"      # Explain each flag
+  shell-agent --template --exec "resize an image to 800px"  # Fill in {{placeholders}}, then run
+  shell-agent --emit plan "find large log files"           # JSON for other tools
+  shell-agent --watch --prompt-file req.txt                 # Regenerate whenever req.txt is saved
+  shell-agent --script-out backup.sh "back up ~/notes to /mnt/backup and verify it"`,
+	// Any arguments that aren't a subcommand are a request for single-command mode
+	Args: cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
+		if functionMode {
+			viper.Set("ai.mode", string(ai.ModeFunction))
+		}
+		if watchPromptFile {
+			if promptFile == "" || len(args) > 0 {
+				output.PrintError("--watch requires --prompt-file and no request argument")
+				os.Exit(1)
+			}
+			runWatchMode(promptFile)
+			return
+		}
+		if promptFile != "" {
+			if len(args) > 0 {
+				output.PrintError("--prompt-file cannot be combined with a request argument")
+				os.Exit(1)
+			}
+			request, err := readPromptFile(promptFile)
+			if err == nil && request == "" {
+				err = fmt.Errorf("%s is empty", promptFile)
+			}
+			if err != nil {
+				output.PrintError(err.Error())
+				os.Exit(1)
+			}
+			args = []string{request}
+		}
+		if appendTo != "" && (len(args) == 0 || viper.GetString("ai.mode") != string(ai.ModeFunction)) {
+			output.PrintError("--append-to requires --function and a request")
+			os.Exit(1)
+		}
+		if (printScript || scriptOut != "") && (len(args) == 0 || viper.GetString("ai.mode") != string(ai.ModeShell)) {
+			output.PrintError("--script and --script-out require a request in shell mode")
+			os.Exit(1)
+		}
+		if emit != "" && (emit != emitPlan || len(args) == 0) {
+			output.PrintError(fmt.Sprintf("--emit %q: only 'plan' is supported, and it requires a request", emit))
+			os.Exit(1)
+		}
+
 		if len(args) == 0 {
-			runInteractiveMode()
+			runInteractiveMode(nil)
 		} else {
 			runSingleCommand(args)
 		}
 	},
 }
 
+// NewRootCommand returns the root command with all subcommands attached.
+func NewRootCommand() *cobra.Command {
+	return rootCmd
+}
+
+// SetVersion sets the version reported by --version and in bug reports
+func SetVersion(v string) {
+	version = v
+	rootCmd.Version = v
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
 	err := rootCmd.Execute()
@@ -51,10 +140,28 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.shell-agent.yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "Enable debug logging")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.PersistentFlags().StringToStringVar(&outputRoutes, "route", nil, "Route message categories to stdout or stderr, e.g. explanation=stderr,warning=stderr")
+	rootCmd.PersistentFlags().StringVar(&inputEncoding, "input-encoding", "", "Handling of non-UTF-8 input: 'sanitize' (replace invalid bytes) or 'strict' (reject)")
+	rootCmd.Flags().StringVar(&mode, "mode", "", "Mode: 'shell' (generate), 'explain' or 'review' an existing command, or 'function'")
+	rootCmd.Flags().StringVar(&tool, "tool", "", "Generate commands that use this CLI tool, e.g. docker or kubectl")
+	rootCmd.Flags().BoolVar(&functionMode, "function", false, "Generate a reusable shell function instead of a single command")
+	rootCmd.Flags().StringVar(&appendTo, "append-to", "", "Append the generated function to this rc file, e.g. ~/.bashrc (requires --function)")
+	rootCmd.Flags().BoolVar(&execute, "exec", false, "Run the generated command after confirmation (single-command mode)")
+	rootCmd.Flags().BoolVar(&printScript, "script", false, "Print the command as a shell script with error handling")
+	rootCmd.Flags().StringVar(&scriptOut, "script-out", "", "Write the command as an executable shell script to this file")
+	rootCmd.Flags().BoolVar(&explainFlags, "explain-flags", false, "Annotate each flag of the generated command with its meaning")
+	rootCmd.Flags().BoolVar(&dangerousPreview, "dangerous-preview", false, "Show what rm, mv and find -delete commands would affect before running them")
+	rootCmd.Flags().StringVar(&emit, "emit", "", "Print a machine-readable result instead of the usual output: 'plan' (JSON)")
+	rootCmd.Flags().StringVar(&promptFile, "prompt-file", "", "Read the request from this file")
+	rootCmd.Flags().BoolVar(&watchPromptFile, "watch", false, "Regenerate the command each time the --prompt-file is saved")
+	rootCmd.Flags().BoolVar(&templateMode, "template", false, "Generate a command with {{placeholders}} for the values you vary, filled in before it runs")
+	rootCmd.Flags().Bool("strict-json", false, "Fail instead of guessing a command when the model response is not valid JSON")
 
 	// Bind flags to viper
 	viper.BindPFlag("debug", rootCmd.PersistentFlags().Lookup("debug"))
 	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
+	viper.BindPFlag("ai.mode", rootCmd.Flags().Lookup("mode"))
+	viper.BindPFlag("ai.strict_parsing", rootCmd.Flags().Lookup("strict-json"))
 }
 
 // initConfig reads in config file and ENV variables.
@@ -73,12 +180,70 @@ func initConfig() {
 
 	viper.AutomaticEnv()
 
-	if err := viper.ReadInConfig(); err == nil {
-		if viper.GetBool("debug") {
-			fmt.Printf("Using config file: %s\n", viper.ConfigFileUsed())
-		}
+	// The --input-encoding flag overrides ai.sanitize_input from the config file
+	switch inputEncoding {
+	case "":
+	case "sanitize":
+		viper.Set("ai.sanitize_input", true)
+	case "strict":
+		viper.Set("ai.sanitize_input", false)
+	default:
+		cobra.CheckErr(fmt.Errorf("invalid --input-encoding %q: use 'sanitize' or 'strict'", inputEncoding))
+	}
+
+	// A malformed config file stops here rather than running on defaults
+	found, err := config.ReadConfigFile()
+	cobra.CheckErr(err)
+
+	// A plan on stdout must stay valid JSON, so everything else goes to stderr
+	diagnostics := io.Writer(os.Stdout)
+	if emit == emitPlan {
+		diagnostics = os.Stderr
+	}
+	if found && viper.GetBool("debug") {
+		fmt.Fprintf(diagnostics, "Using config file: %s\n", viper.ConfigFileUsed())
 	}
 
 	// Initialize logger
 	logger.InitLogger(viper.GetBool("debug"), viper.GetBool("verbose"))
+	logger.SetOutput(diagnostics)
+
+	configureOutputRoutes()
+	warnOnInvalidConfig()
+}
+
+// warnOnInvalidConfig prints configuration warnings to stderr without stopping
+// the command
+func warnOnInvalidConfig() {
+	cfg, err := config.Load()
+	cobra.CheckErr(err)
+
+	for _, warning := range cfg.Validate() {
+		output.PrintDiagnostic(warning)
+	}
+}
+
+// configureOutputRoutes applies the output.* config, overridden by --route
+func configureOutputRoutes() {
+	cfg, err := config.Load()
+	cobra.CheckErr(err)
+
+	routes := map[string]string{
+		string(output.CategoryCommand):     cfg.Output.Command,
+		string(output.CategoryExplanation): cfg.Output.Explanation,
+		string(output.CategoryWarning):     cfg.Output.Warning,
+		string(output.CategoryStatus):      cfg.Output.Status,
+	}
+	for category, stream := range outputRoutes {
+		routes[category] = stream
+	}
+	if emit == emitPlan {
+		for _, category := range []output.Category{output.CategoryExplanation, output.CategoryWarning, output.CategoryStatus} {
+			routes[string(category)] = "stderr"
+		}
+	}
+
+	for category, stream := range routes {
+		cobra.CheckErr(output.SetRoute(output.Category(category), stream))
+	}
 }
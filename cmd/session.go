@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/output"
+	"github.com/kodelint/shell-agent/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Manage saved interactive sessions",
+	Long: `Manage conversations saved from interactive mode.
+
+Sessions are saved from the REPL with 'session save <name>' and stored in
+~/.shell-agent/sessions. Loading a session restores its context so
+follow-up requests work as if the conversation never ended.
+
+Examples:
+  shell-agent session list             # List saved sessions
+  shell-agent session load deploy      # Resume a session in interactive mode
+  shell-agent session delete deploy    # Delete a saved session`,
+}
+
+var sessionListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved sessions",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		store := mustSessionStore()
+		listSessions(store)
+	},
+}
+
+var sessionLoadCmd = &cobra.Command{
+	Use:   "load <name>",
+	Short: "Resume a saved session in interactive mode",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store := mustSessionStore()
+		saved, err := store.Load(args[0])
+		if err != nil {
+			output.PrintError(err.Error())
+			os.Exit(1)
+		}
+
+		output.PrintSuccess(fmt.Sprintf("Loaded session '%s' (%d messages)", saved.Name, len(saved.Messages)))
+		runInteractiveMode(&ai.Conversation{Messages: saved.Messages})
+	},
+}
+
+var sessionDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a saved session",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store := mustSessionStore()
+		if err := store.Delete(args[0]); err != nil {
+			output.PrintError(err.Error())
+			os.Exit(1)
+		}
+		output.PrintSuccess(fmt.Sprintf("Deleted session '%s'", args[0]))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sessionCmd)
+	sessionCmd.AddCommand(sessionListCmd, sessionLoadCmd, sessionDeleteCmd)
+}
+
+func mustSessionStore() *session.Store {
+	store, err := session.NewStore()
+	if err != nil {
+		output.PrintError(fmt.Sprintf("Failed to open session store: %v", err))
+		os.Exit(1)
+	}
+	return store
+}
+
+func listSessions(store *session.Store) {
+	names, err := store.List()
+	if err != nil {
+		output.PrintError(err.Error())
+		return
+	}
+	if len(names) == 0 {
+		output.PrintInfo("No saved sessions")
+		return
+	}
+	for _, name := range names {
+		output.PrintInfo(name)
+	}
+}
+
+// handleSessionCommand implements the REPL 'session save|load|list' built-ins
+func handleSessionCommand(aiClient *ai.Client, args []string) {
+	store, err := session.NewStore()
+	if err != nil {
+		output.PrintError(fmt.Sprintf("Failed to open session store: %v", err))
+		return
+	}
+
+	if len(args) == 1 && args[0] == "list" {
+		listSessions(store)
+		return
+	}
+
+	if len(args) != 2 {
+		output.PrintInfo("Usage: session save <name> | session load <name> | session list")
+		return
+	}
+
+	name := args[1]
+	conversation := aiClient.Conversation()
+
+	switch args[0] {
+	case "save":
+		if err := store.Save(name, conversation.Messages); err != nil {
+			output.PrintError(err.Error())
+			return
+		}
+		output.PrintSuccess(fmt.Sprintf("Saved session '%s' (%d messages)", name, len(conversation.Messages)))
+	case "load":
+		saved, err := store.Load(name)
+		if err != nil {
+			output.PrintError(err.Error())
+			return
+		}
+		conversation.Messages = saved.Messages
+		output.PrintSuccess(fmt.Sprintf("Loaded session '%s' (%d messages)", name, len(saved.Messages)))
+	default:
+		output.PrintInfo("Usage: session save <name> | session load <name> | session list")
+	}
+}
@@ -35,6 +35,12 @@ Examples:
 	},
 }
 
+const (
+	// ollamaStartTimeout bounds how long setup waits for a started service
+	ollamaStartTimeout = 30 * time.Second
+	ollamaPollInterval = 500 * time.Millisecond
+)
+
 var (
 	setupOllamaOnly bool
 	setupModelOnly  bool
@@ -149,9 +155,10 @@ ai:
   max_tokens: 2048
   temperature: 0.1
   
+  # Defaults to $OLLAMA_HOST when set, otherwise localhost:11434
   ollama:
-    host: "localhost"
-    port: 11434
+    # host: "localhost"
+    # port: 11434
 
 interactive:
   confirm_commands: true
@@ -162,6 +169,11 @@ interactive:
 safety:
   require_confirm: true
   block_destructive: false
+  warn_missing_binary: true
+  max_pipes: 3
+  max_subshells: 2
+  max_command_length: 300
+  complexity_action: "warn"
 
 logging:
   level: "info"
@@ -203,16 +215,33 @@ func startOllama() error {
 		return fmt.Errorf("failed to start Ollama: %w", err)
 	}
 
-	// Wait a moment for service to start
-	time.Sleep(3 * time.Second)
-
-	// Check if it's running
-	if isOllamaRunning() {
-		output.PrintSuccess("✅ Ollama service started successfully")
-		return nil
+	// Poll until the service answers, so slow starts don't fail setup
+	if err := waitForOllama(); err != nil {
+		return fmt.Errorf("ollama service failed to start properly: %w", err)
 	}
 
-	return fmt.Errorf("ollama service failed to start properly")
+	output.PrintSuccess("✅ Ollama service started successfully")
+	return nil
+}
+
+// waitForOllama polls the Ollama service until it responds or ollamaStartTimeout
+// passes, printing a dot per failed attempt
+func waitForOllama() error {
+	ctx, cancel := context.WithTimeout(context.Background(), ollamaStartTimeout)
+	defer cancel()
+
+	cfg, _ := config.Load()
+	client := ai.NewOllamaClient(cfg)
+
+	waited := false
+	err := client.WaitUntilAvailable(ctx, ollamaPollInterval, func(attempt int) {
+		waited = true
+		output.PrintWaitingDot("⏳ Waiting for Ollama", attempt)
+	})
+	if waited {
+		output.PrintWaitingDone()
+	}
+	return err
 }
 
 func installOllama() error {
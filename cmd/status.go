@@ -1,9 +1,9 @@
 package cmd
 
 import (
-	"github.com/kodelint/shell-agent/internal/ai"
+	"os"
+
 	"github.com/kodelint/shell-agent/internal/output"
-	"github.com/kodelint/shell-agent/internal/system"
 	"github.com/spf13/cobra"
 )
 
@@ -18,31 +18,35 @@ var statusCmd = &cobra.Command{
 
 Examples:
   shell-agent status          # Show complete status
-  shell-agent status --model  # Show only model status`,
+  shell-agent status --model  # Show only model status
+  shell-agent status --json   # Print the full status as JSON`,
 	Run: func(cmd *cobra.Command, args []string) {
 		runStatus(cmd, args)
 	},
 }
 
 var (
-	modelOnly bool
+	modelOnly  bool
+	statusJSON bool
 )
 
 func init() {
 	rootCmd.AddCommand(statusCmd)
 
 	statusCmd.Flags().BoolVar(&modelOnly, "model", false, "Show only model status")
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "Print the status as JSON")
 }
 
 func runStatus(cmd *cobra.Command, args []string) {
-	modelManager := ai.NewModelManager()
-	systemInfo := system.NewSystemInfo()
-
-	status := &output.StatusInfo{
-		ModelManager: modelManager,
-		SystemInfo:   systemInfo,
-		ModelOnly:    modelOnly,
+	status := output.BuildStatus()
+
+	if statusJSON {
+		if err := output.PrintStatusJSON(status); err != nil {
+			output.PrintError(err.Error())
+			os.Exit(1)
+		}
+		return
 	}
 
-	output.PrintStatus(status)
+	output.PrintStatus(status, modelOnly)
 }
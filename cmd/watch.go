@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/output"
+	"github.com/kodelint/shell-agent/internal/watch"
+)
+
+// readPromptFile returns the request held in a --prompt-file
+func readPromptFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// runWatchMode generates a command from the prompt file and again each time
+// the file is saved, until interrupted
+func runWatchMode(path string) {
+	aiClient, err := ai.NewClient()
+	if err != nil {
+		output.PrintError(fmt.Sprintf("Failed to initialize AI client: %v", err))
+		if strings.Contains(err.Error(), "no AI model available") {
+			output.PrintInfo("💡 Run 'shell-agent download' to install an AI model first")
+		}
+		os.Exit(1)
+	}
+	aiClient.SetTool(tool)
+	aiClient.SetTemplate(templateMode)
+	aiClient.SetObserver(&cliObserver{})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	triggers := watch.Debounce(watch.Poll(ctx, path, watch.DefaultInterval), watch.DefaultQuiet, nil)
+
+	output.PrintInfo(fmt.Sprintf("👀 Watching %s, press Ctrl+C to stop", path))
+	for {
+		generateFromFile(ctx, aiClient, path)
+
+		if _, ok := <-triggers; !ok {
+			output.PrintGoodbye()
+			return
+		}
+		output.PrintInfo(fmt.Sprintf("🔄 %s changed, regenerating", path))
+	}
+}
+
+// generateFromFile generates and prints a command for the current contents of
+// the prompt file. Errors are printed and watching continues.
+func generateFromFile(ctx context.Context, aiClient *ai.Client, path string) {
+	input, err := readPromptFile(path)
+	if err != nil {
+		output.PrintError(err.Error())
+		return
+	}
+	if input == "" {
+		output.PrintInfo("💡 The prompt file is empty; write a request and save it")
+		return
+	}
+
+	output.PrintThinking()
+	if _, err := aiClient.GenerateCommandContext(ctx, input); err != nil && ctx.Err() == nil {
+		output.PrintError(fmt.Sprintf("Error generating command: %v", err))
+	}
+}
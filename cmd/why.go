@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var whyCmd = &cobra.Command{
+	Use:   "why",
+	Short: "Explain why the last generated command was chosen",
+	Long: `Ask the model to justify the most recently generated command and compare
+it with the alternatives it offered. No new command is generated.
+
+Examples:
+  shell-agent "find large log files"
+  shell-agent why`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		last, err := lastHistoryEntry()
+		if err != nil {
+			output.PrintError(err.Error())
+			os.Exit(1)
+		}
+
+		aiClient, err := ai.NewClient()
+		if err != nil {
+			output.PrintError(fmt.Sprintf("Failed to initialize AI client: %v", err))
+			os.Exit(1)
+		}
+		aiClient.SetObserver(&cliObserver{})
+
+		if _, err := aiClient.Why(cmd.Context(), last.Prompt, last.Command, last.Alternatives); err != nil {
+			output.PrintError(fmt.Sprintf("Error explaining command: %v", err))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(whyCmd)
+}
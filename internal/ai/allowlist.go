@@ -0,0 +1,50 @@
+package ai
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/kodelint/shell-agent/internal/shellwords"
+)
+
+// DisallowedPrograms returns the programs in command that are not on the
+// allowlist, checking every command in a pipeline or list. Command substitution
+// could run anything, so it is reported as disallowed, as is a command that
+// cannot be tokenized.
+func DisallowedPrograms(command string, allowlist []string) []string {
+	if strings.Contains(command, "$(") || strings.Contains(command, "`") {
+		return []string{"command substitution"}
+	}
+
+	words, err := shellwords.SplitCommand(command)
+	if err != nil {
+		return []string{command}
+	}
+
+	allowed := make(map[string]bool, len(allowlist))
+	for _, name := range allowlist {
+		allowed[strings.TrimSpace(name)] = true
+	}
+
+	var disallowed []string
+	for _, simple := range splitSimpleCommands(words) {
+		program := leadingProgram(simple)
+		if program == "" || allowed[program] || allowed[filepath.Base(program)] {
+			continue
+		}
+		disallowed = append(disallowed, program)
+	}
+	return disallowed
+}
+
+// leadingProgram returns the program a simple command runs, skipping
+// environment assignments such as LANG=C
+func leadingProgram(words []string) string {
+	for _, word := range words {
+		if name, _, ok := strings.Cut(word, "="); ok && name != "" && !strings.ContainsAny(name, "/-") {
+			continue
+		}
+		return word
+	}
+	return ""
+}
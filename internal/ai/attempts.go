@@ -0,0 +1,178 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AttemptKind identifies an extra step taken while generating a command
+type AttemptKind string
+
+const (
+	// AttemptNetworkRetry is a repeat of a request that failed with a network error
+	AttemptNetworkRetry AttemptKind = "network-retry"
+	// AttemptReparse is a re-prompt after a response could not be parsed as JSON
+	AttemptReparse AttemptKind = "reparse"
+	// AttemptEscalation is a retry with the configured escalation model
+	AttemptEscalation AttemptKind = "escalation"
+)
+
+// retryBackoff is the delay before the first network retry; it doubles per retry
+const retryBackoff = 250 * time.Millisecond
+
+const reparseInstruction = `
+
+Your previous response was not valid JSON. Respond ONLY with the JSON object described in the system prompt, with no other text.`
+
+// Attempt records one decision made while generating a command
+type Attempt struct {
+	Kind   AttemptKind `json:"kind"`
+	Model  string      `json:"model"`
+	Detail string      `json:"detail,omitempty"`
+}
+
+// SummarizeAttempts renders an attempt log as a single line, e.g.
+// "2 network retries, 1 reparse, escalated to codegemma:7b". It is empty when
+// the first attempt succeeded.
+func SummarizeAttempts(attempts []Attempt) string {
+	var retries, reparses int
+	var escalatedTo string
+
+	for _, attempt := range attempts {
+		switch attempt.Kind {
+		case AttemptNetworkRetry:
+			retries++
+		case AttemptReparse:
+			reparses++
+		case AttemptEscalation:
+			escalatedTo = attempt.Model
+		}
+	}
+
+	var parts []string
+	if retries > 0 {
+		parts = append(parts, pluralize(retries, "network retry", "network retries"))
+	}
+	if reparses > 0 {
+		parts = append(parts, pluralize(reparses, "reparse", "reparses"))
+	}
+	if escalatedTo != "" {
+		parts = append(parts, "escalated to "+escalatedTo)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+// generateWithRecovery sends a request and recovers from transient failures: it retries
+// network errors, re-prompts when the response is not valid JSON and escalates to a
+// stronger model when confidence is low. Every extra step is recorded in the attempt log.
+func (c *Client) generateWithRecovery(ctx context.Context, req GenerateRequest) (*CommandResponse, []Attempt, error) {
+	var attempts []Attempt
+
+	response, err := c.generateWithRetry(ctx, req, &attempts)
+	if err != nil {
+		return nil, attempts, err
+	}
+
+	for i := 0; response.Fallback && i < c.config.AI.ReparseAttempts; i++ {
+		attempts = append(attempts, Attempt{Kind: AttemptReparse, Model: req.Model})
+		c.logger.WithField("model", req.Model).Info("Response was not valid JSON, re-prompting")
+
+		reparseReq := req
+		reparseReq.Prompt = req.Prompt + reparseInstruction
+		reparsed, err := c.generateWithRetry(ctx, reparseReq, &attempts)
+		if err != nil {
+			c.logger.WithError(err).Warn("Re-prompt failed, keeping fallback response")
+			break
+		}
+		response = reparsed
+	}
+
+	if escalation := c.escalationModel(req.Model, response); escalation != "" {
+		attempts = append(attempts, Attempt{
+			Kind:   AttemptEscalation,
+			Model:  escalation,
+			Detail: fmt.Sprintf("confidence %.2f below %.2f", response.Confidence, c.config.AI.EscalationThreshold),
+		})
+		c.logger.WithFields(logrus.Fields{
+			"from": req.Model,
+			"to":   escalation,
+		}).Info("Escalating to a stronger model")
+
+		escalationReq := req
+		escalationReq.Model = escalation
+		escalated, err := c.generateWithRetry(ctx, escalationReq, &attempts)
+		if err != nil {
+			c.logger.WithError(err).Warn("Escalation failed, keeping original response")
+		} else {
+			response = escalated
+			response.Model = escalation
+		}
+	}
+
+	return response, attempts, nil
+}
+
+// generateWithRetry retries requests that fail with network errors, with exponential backoff
+func (c *Client) generateWithRetry(ctx context.Context, req GenerateRequest, attempts *[]Attempt) (*CommandResponse, error) {
+	for retry := 0; ; retry++ {
+		response, err := c.provider.Generate(ctx, c.prepareRequest(req))
+		if err == nil {
+			if response.Model == "" {
+				response.Model = req.Model
+			}
+			return response, nil
+		}
+
+		if retry >= c.config.AI.MaxRetries || !isNetworkError(err) || ctx.Err() != nil {
+			return nil, err
+		}
+
+		*attempts = append(*attempts, Attempt{Kind: AttemptNetworkRetry, Model: req.Model, Detail: err.Error()})
+		c.logger.WithError(err).WithField("retry", retry+1).Warn("Network error, retrying")
+
+		select {
+		case <-time.After(retryBackoff << retry):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// escalationModel returns the model to escalate to, or "" when the response is good enough
+func (c *Client) escalationModel(current string, response *CommandResponse) string {
+	escalation := c.config.AI.EscalationModel
+	if escalation == "" || escalation == current {
+		return ""
+	}
+
+	if !response.Fallback && response.Confidence >= c.config.AI.EscalationThreshold {
+		return ""
+	}
+
+	if c.supports(CapabilityLocalModels) && !c.modelManager.IsModelAvailableInOllama(escalation) {
+		c.logger.WithField("model", escalation).Warn("Escalation model is not available in Ollama")
+		return ""
+	}
+
+	return escalation
+}
+
+// isNetworkError reports whether err is a transport failure worth retrying
+func isNetworkError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,98 @@
+package ai
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kodelint/shell-agent/internal/shellwords"
+	"github.com/kodelint/shell-agent/internal/system"
+)
+
+// RuleMissingBinary flags programs that are not installed on this system
+const RuleMissingBinary = "missing-binary"
+
+// shellBuiltins are run by the shell itself and are never on PATH
+var shellBuiltins = map[string]bool{
+	".": true, ":": true, "[": true, "[[": true, "alias": true, "bg": true, "bind": true,
+	"break": true, "builtin": true, "case": true, "cd": true, "command": true, "continue": true,
+	"declare": true, "dirs": true, "do": true, "done": true, "echo": true, "elif": true,
+	"else": true, "esac": true, "eval": true, "exec": true, "exit": true, "export": true,
+	"false": true, "fc": true, "fg": true, "fi": true, "for": true, "function": true,
+	"getopts": true, "hash": true, "history": true, "if": true, "jobs": true, "let": true,
+	"local": true, "popd": true, "printf": true, "pushd": true, "pwd": true, "read": true,
+	"readonly": true, "return": true, "select": true, "set": true, "shift": true, "source": true,
+	"test": true, "then": true, "time": true, "times": true, "trap": true, "true": true,
+	"type": true, "typeset": true, "ulimit": true, "umask": true, "unalias": true, "unset": true,
+	"until": true, "wait": true, "while": true, "{": true, "}": true,
+}
+
+// commandPrograms returns the program each simple command of command runs, in
+// order. sudo and environment assignments are skipped.
+func commandPrograms(command string) []string {
+	words, err := shellwords.SplitCommand(command)
+	if err != nil {
+		words = strings.Fields(command)
+	}
+
+	var programs []string
+	for _, simple := range splitSimpleCommands(words) {
+		program := leadingProgram(simple)
+		if program == "sudo" && len(simple) > 1 {
+			program = leadingProgram(simple[1:])
+		}
+		if program != "" {
+			programs = append(programs, program)
+		}
+	}
+	return programs
+}
+
+// MissingBinaries returns the programs command runs that are neither shell
+// builtins nor found on PATH, without duplicates
+func MissingBinaries(command string) []string {
+	seen := make(map[string]bool)
+	var missing []string
+	for _, program := range commandPrograms(command) {
+		// Substitutions and subshells can't be resolved without running them
+		if seen[program] || shellBuiltins[filepath.Base(program)] || strings.ContainsAny(program, "$`(){}") {
+			continue
+		}
+		seen[program] = true
+
+		if _, err := exec.LookPath(program); err != nil {
+			missing = append(missing, program)
+		}
+	}
+	return missing
+}
+
+// checkBinaries adds an advisory finding for programs that are not installed,
+// which usually means the command was written for another operating system
+func (c *Client) checkBinaries(response *CommandResponse) {
+	// cmd.exe built-ins such as dir are not executables, so PATH says nothing about them
+	if system.GOOS() == "windows" {
+		return
+	}
+
+	var missing []string
+	for _, program := range MissingBinaries(response.Command) {
+		// A missing --tool is already reported on its own
+		if program != c.tool {
+			missing = append(missing, program)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	finding := Finding{
+		Rule:     RuleMissingBinary,
+		Severity: SeverityWarning,
+		Pattern:  strings.Join(missing, ", "),
+		Message:  fmt.Sprintf("🔍 Not installed on this system: %s. The command may be meant for another OS; try regenerating it for %s", strings.Join(missing, ", "), system.GOOS()),
+	}
+	response.Findings = append(response.Findings, finding)
+	appendWarning(response, finding.Message)
+}
@@ -0,0 +1,111 @@
+package ai
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Candidate is a command offered for a request: the primary command or one of its alternatives
+type Candidate struct {
+	Command    string  `json:"command"`
+	Confidence float64 `json:"confidence"`
+	// Dangerous is set when the safety checks raised a critical finding
+	Dangerous bool `json:"dangerous,omitempty"`
+}
+
+// dangerPenalty is subtracted from the score of dangerous candidates, so a
+// confident but destructive command ranks below a reasonable safe one
+const dangerPenalty = 0.5
+
+// alternativeDiscount scales the primary confidence for alternatives the model
+// gave no confidence for, so they rank just below the primary command
+const alternativeDiscount = 0.9
+
+// Score is the value candidates are ranked by
+func (c Candidate) Score() float64 {
+	if c.Dangerous {
+		return c.Confidence - dangerPenalty
+	}
+	return c.Confidence
+}
+
+// RankCandidates returns the candidates ordered best-first by score. Ties keep
+// their original order, so the model's own preference breaks them.
+func RankCandidates(candidates []Candidate) []Candidate {
+	ranked := append([]Candidate(nil), candidates...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score() > ranked[j].Score()
+	})
+	return ranked
+}
+
+// rankCandidates collects the primary command and its alternatives, flags the
+// dangerous ones and stores them best-first on the response
+func (c *Client) rankCandidates(response *CommandResponse) {
+	if response.Command == "" || len(response.Alternatives) == 0 {
+		return
+	}
+
+	candidates := []Candidate{{Command: response.Command, Confidence: response.Confidence}}
+	seen := map[string]bool{response.Command: true}
+
+	for _, alt := range response.Alternatives {
+		if seen[alt] {
+			continue
+		}
+		seen[alt] = true
+
+		confidence, ok := response.alternativeConfidence[alt]
+		if !ok {
+			confidence = response.Confidence * alternativeDiscount
+		}
+		candidates = append(candidates, Candidate{Command: alt, Confidence: confidence})
+	}
+
+	for i := range candidates {
+		for _, finding := range c.safetyChecker.Findings(candidates[i].Command) {
+			if finding.Severity == SeverityCritical {
+				candidates[i].Dangerous = true
+				break
+			}
+		}
+	}
+
+	response.Candidates = RankCandidates(candidates)
+}
+
+// SelectCandidate returns a copy of response with command, one of its
+// candidates, in place of the primary command. The checks are run again, since
+// the findings and risk score of the response describe the primary command.
+// The steps, flags and placeholders are derived from the selected command, and
+// the explanation is labelled as the primary command's.
+func (c *Client) SelectCandidate(response *CommandResponse, command string) *CommandResponse {
+	// Selecting again starts from the response the model gave
+	if response.primary != nil {
+		response = response.primary
+	}
+	if command == response.Command {
+		return response
+	}
+
+	selected := c.recheck(response, command)
+	selected.primary = response
+	selected.Steps = nil
+	selected.Flags = nil
+	selected.Categories = CommandCategories(command)
+	if response.Placeholders != nil {
+		selected.Placeholders = Placeholders(command)
+	}
+	if response.Explanation != "" {
+		selected.Explanation = fmt.Sprintf("(Explains the original command, %s) %s", response.Command, response.Explanation)
+	}
+	for _, candidate := range response.Candidates {
+		if candidate.Command == command {
+			selected.Confidence = candidate.Confidence
+		}
+	}
+	if selected.HasCritical() && selected.Confidence > 0.5 {
+		selected.Confidence = 0.5
+	}
+	return selected
+}
@@ -0,0 +1,110 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrUnsupported is returned when a feature needs a capability the active provider lacks
+var ErrUnsupported = errors.New("not supported by the AI provider")
+
+// Capability is an optional feature a provider may support
+type Capability string
+
+const (
+	// CapabilityStreaming streams output tokens as they are generated
+	CapabilityStreaming Capability = "streaming"
+	// CapabilityStructuredOutput constrains the response to JSON
+	CapabilityStructuredOutput Capability = "structured-output"
+	// CapabilityThinking returns a reasoning model's thinking separately
+	CapabilityThinking Capability = "thinking"
+	// CapabilityKeepAlive controls how long the model stays loaded after a request
+	CapabilityKeepAlive Capability = "keep-alive"
+	// CapabilityLocalModels lists, downloads and pins models installed locally
+	CapabilityLocalModels Capability = "local-models"
+	// CapabilityEmbeddings computes embedding vectors for text
+	CapabilityEmbeddings Capability = "embeddings"
+)
+
+// Capabilities is the set of features a provider supports
+type Capabilities map[Capability]bool
+
+// NewCapabilities returns a set holding the given capabilities
+func NewCapabilities(capabilities ...Capability) Capabilities {
+	set := make(Capabilities, len(capabilities))
+	for _, capability := range capabilities {
+		set[capability] = true
+	}
+	return set
+}
+
+// Has reports whether the set includes capability
+func (c Capabilities) Has(capability Capability) bool {
+	return c[capability]
+}
+
+// List returns the capabilities in the set, sorted by name
+func (c Capabilities) List() []Capability {
+	list := make([]Capability, 0, len(c))
+	for capability, ok := range c {
+		if ok {
+			list = append(list, capability)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	return list
+}
+
+// Embedder is implemented by providers with CapabilityEmbeddings
+type Embedder interface {
+	Embed(ctx context.Context, model string, inputs []string) ([][]float64, error)
+}
+
+// Capabilities returns what the active provider supports
+func (c *Client) Capabilities() Capabilities {
+	return c.provider.Capabilities()
+}
+
+// supports reports whether the active provider has capability
+func (c *Client) supports(capability Capability) bool {
+	return c.provider.Capabilities().Has(capability)
+}
+
+// prepareRequest fills in the provider options from the config and then drops
+// any the provider does not support, so unsupported parameters are never sent.
+// Every request goes through here before reaching the provider.
+func (c *Client) prepareRequest(req GenerateRequest) GenerateRequest {
+	req.JSON = true
+	req.Think = c.config.AI.Ollama.Think
+	req.KeepAlive = c.config.AI.Ollama.KeepAlive
+
+	if !c.supports(CapabilityStreaming) {
+		req.OnToken = nil
+	}
+	if !c.supports(CapabilityStructuredOutput) {
+		req.JSON = false
+	}
+	if !c.supports(CapabilityThinking) {
+		req.Think = false
+	}
+	if !c.supports(CapabilityKeepAlive) {
+		req.KeepAlive = ""
+	}
+	return req
+}
+
+// Embed returns an embedding vector for each input using the default model.
+// Providers without embeddings return an error wrapping ErrUnsupported
+// without sending anything, so callers can skip the feature.
+func (c *Client) Embed(ctx context.Context, inputs []string) ([][]float64, error) {
+	embedder, ok := c.provider.(Embedder)
+	if !ok || !c.supports(CapabilityEmbeddings) {
+		return nil, fmt.Errorf("embeddings: %w (%s)", ErrUnsupported, c.config.AI.Provider)
+	}
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+	return embedder.Embed(ctx, c.config.AI.DefaultModel, inputs)
+}
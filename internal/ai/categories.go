@@ -0,0 +1,104 @@
+package ai
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Command categories used by safety.confidence_adjustments
+const (
+	CategoryNavigation     = "navigation"
+	CategoryRead           = "read"
+	CategorySearch         = "search"
+	CategoryTextProcessing = "text-processing"
+	CategoryFilesystem     = "filesystem"
+	CategoryArchive        = "archive"
+	CategoryNetwork        = "network"
+	CategoryProcess        = "process"
+	CategoryPackage        = "package"
+	CategoryVCS            = "vcs"
+	CategoryContainer      = "container"
+	CategoryOther          = "other"
+)
+
+// programClass is what a program is known to do. It drives both the command
+// categories and the risk score, so the two cannot disagree.
+type programClass struct {
+	category string
+	// destructive programs delete or overwrite data, or kill processes
+	destructive bool
+}
+
+// programClasses maps programs to their class; anything else is CategoryOther.
+// Programs in CategoryNetwork count as network access when scoring risk.
+var programClasses = map[string]programClass{
+	"ls": {category: CategoryNavigation}, "pwd": {category: CategoryNavigation}, "cd": {category: CategoryNavigation}, "tree": {category: CategoryNavigation},
+	"cat": {category: CategoryRead}, "head": {category: CategoryRead}, "tail": {category: CategoryRead}, "less": {category: CategoryRead}, "more": {category: CategoryRead}, "wc": {category: CategoryRead}, "stat": {category: CategoryRead}, "file": {category: CategoryRead},
+	"grep": {category: CategorySearch}, "egrep": {category: CategorySearch}, "rg": {category: CategorySearch}, "find": {category: CategorySearch}, "fd": {category: CategorySearch}, "locate": {category: CategorySearch}, "which": {category: CategorySearch},
+	"awk": {category: CategoryTextProcessing}, "gawk": {category: CategoryTextProcessing}, "sed": {category: CategoryTextProcessing}, "cut": {category: CategoryTextProcessing}, "tr": {category: CategoryTextProcessing}, "sort": {category: CategoryTextProcessing}, "uniq": {category: CategoryTextProcessing}, "xargs": {category: CategoryTextProcessing}, "jq": {category: CategoryTextProcessing}, "perl": {category: CategoryTextProcessing},
+	"cp": {category: CategoryFilesystem}, "mv": {category: CategoryFilesystem}, "mkdir": {category: CategoryFilesystem}, "touch": {category: CategoryFilesystem}, "ln": {category: CategoryFilesystem}, "chmod": {category: CategoryFilesystem}, "chown": {category: CategoryFilesystem}, "du": {category: CategoryFilesystem}, "df": {category: CategoryFilesystem},
+	"rm": {CategoryFilesystem, true}, "rmdir": {CategoryFilesystem, true}, "dd": {CategoryFilesystem, true}, "shred": {CategoryFilesystem, true}, "truncate": {CategoryFilesystem, true}, "fdisk": {CategoryFilesystem, true}, "wipefs": {CategoryFilesystem, true}, "mkfs": {CategoryFilesystem, true},
+	"tar": {category: CategoryArchive}, "zip": {category: CategoryArchive}, "unzip": {category: CategoryArchive}, "gzip": {category: CategoryArchive}, "gunzip": {category: CategoryArchive}, "bzip2": {category: CategoryArchive}, "xz": {category: CategoryArchive}, "7z": {category: CategoryArchive},
+	"curl": {category: CategoryNetwork}, "wget": {category: CategoryNetwork}, "ssh": {category: CategoryNetwork}, "scp": {category: CategoryNetwork}, "sftp": {category: CategoryNetwork}, "rsync": {category: CategoryNetwork}, "ping": {category: CategoryNetwork}, "dig": {category: CategoryNetwork}, "nc": {category: CategoryNetwork}, "ncat": {category: CategoryNetwork}, "telnet": {category: CategoryNetwork}, "ftp": {category: CategoryNetwork}, "netstat": {category: CategoryNetwork}, "ss": {category: CategoryNetwork},
+	"ps": {category: CategoryProcess}, "top": {category: CategoryProcess}, "htop": {category: CategoryProcess}, "lsof": {category: CategoryProcess}, "systemctl": {category: CategoryProcess},
+	"kill": {CategoryProcess, true}, "killall": {CategoryProcess, true}, "pkill": {CategoryProcess, true},
+	"apt": {category: CategoryPackage}, "apt-get": {category: CategoryPackage}, "brew": {category: CategoryPackage}, "yum": {category: CategoryPackage}, "dnf": {category: CategoryPackage}, "pacman": {category: CategoryPackage}, "pip": {category: CategoryPackage}, "npm": {category: CategoryPackage},
+	"git":    {category: CategoryVCS},
+	"docker": {category: CategoryContainer}, "podman": {category: CategoryContainer}, "kubectl": {category: CategoryContainer}, "helm": {category: CategoryContainer},
+}
+
+// classifyProgram returns the class of a program, given by name or path.
+// mkfs.ext4 and friends are classed as mkfs.
+func classifyProgram(program string) programClass {
+	program = filepath.Base(program)
+	if strings.HasPrefix(program, "mkfs.") {
+		program = "mkfs"
+	}
+	if class, ok := programClasses[program]; ok {
+		return class
+	}
+	return programClass{category: CategoryOther}
+}
+
+// CommandCategories returns the distinct categories of the programs a command
+// runs, in order of appearance. sudo and environment assignments are skipped.
+func CommandCategories(command string) []string {
+	seen := make(map[string]bool)
+	var categories []string
+	for _, program := range commandPrograms(command) {
+		category := classifyProgram(program).category
+		if !seen[category] {
+			seen[category] = true
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
+
+// AdjustConfidence adds the adjustment of each of the response's categories to
+// its confidence, clamped to [0, 1]. Categories without an adjustment are unchanged.
+func AdjustConfidence(response *CommandResponse, adjustments map[string]float64) {
+	if len(adjustments) == 0 {
+		return
+	}
+
+	adjusted := false
+	confidence := response.Confidence
+	for _, category := range response.Categories {
+		if delta, ok := adjustments[category]; ok {
+			confidence += delta
+			adjusted = true
+		}
+	}
+	if !adjusted {
+		return
+	}
+
+	switch {
+	case confidence < 0:
+		confidence = 0
+	case confidence > 1:
+		confidence = 1
+	}
+	response.Confidence = confidence
+}
@@ -2,22 +2,37 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"runtime"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/kodelint/shell-agent/internal/config"
 	"github.com/kodelint/shell-agent/internal/logger"
+	"github.com/kodelint/shell-agent/internal/system"
 	"github.com/sirupsen/logrus"
 )
 
+// ErrParseFailure is returned in strict parsing mode when the model response is not the expected JSON
+var ErrParseFailure = errors.New("model response could not be parsed as JSON")
+
 type Client struct {
 	ollamaClient  *OllamaClient
+	provider      Provider
 	modelManager  *ModelManager
 	config        *config.Config
 	logger        *logrus.Entry
 	safetyChecker *SafetyChecker
+	mode          Mode
+	conversation  *Conversation
+	// tool, when set, is the CLI the generated command must use
+	tool     string
+	observer Observer
+	// template asks for {{name}} placeholders instead of guessed values
+	template bool
 }
 
 type CommandResponse struct {
@@ -26,155 +41,479 @@ type CommandResponse struct {
 	Warning      string   `json:"warning"`
 	Confidence   float64  `json:"confidence"`
 	Alternatives []string `json:"alternatives,omitempty"`
+	// Categories classify the programs the command runs, e.g. navigation or text-processing
+	Categories []string `json:"categories,omitempty"`
+	// Flags explain each flag and argument of the command, for --explain-flags
+	Flags []FlagExplanation `json:"flags,omitempty"`
+	// Steps break a multi-step answer into commands, when the model provides them
+	Steps []Step `json:"steps,omitempty"`
+	// Clarification is a question the model asked instead of generating a command
+	Clarification string `json:"clarification,omitempty"`
+	// Mode is the mode that produced this response
+	Mode Mode `json:"mode,omitempty"`
+	// Issues and Risk are populated by review mode
+	Issues []string `json:"issues,omitempty"`
+	Risk   string   `json:"risk,omitempty"`
+	// Model is the model that produced the response
+	Model string `json:"model,omitempty"`
+	// Attempts logs retries, re-prompts and escalations made to get this response
+	Attempts []Attempt `json:"attempts,omitempty"`
+	// Findings are the structured results of the safety checks
+	Findings []Finding `json:"findings,omitempty"`
+	// Candidates are the command and its alternatives, best first
+	Candidates []Candidate `json:"candidates,omitempty"`
+	// RiskScore combines the safety signals into a number from 0 to 100
+	RiskScore   int          `json:"risk_score,omitempty"`
+	RiskFactors []RiskFactor `json:"risk_factors,omitempty"`
+	// Placeholders are the {{name}} values to fill in before running a templated command
+	Placeholders []string `json:"placeholders,omitempty"`
+	// Thinking is the reasoning a model produced before its answer, kept out of parsing
+	Thinking string `json:"thinking,omitempty"`
+	// Fallback reports that the model response was not valid JSON and the
+	// command was extracted heuristically
+	Fallback bool `json:"fallback,omitempty"`
+	// Trace is the raw exchange with the model; it is left out of JSON output
+	Trace *Trace `json:"-"`
+
+	// alternativeConfidence holds confidences the model gave for individual alternatives
+	alternativeConfidence map[string]float64
+	// primary is the response a selected candidate was taken from
+	primary *CommandResponse
+}
+
+// NeedsClarification reports whether the model asked a question instead of
+// producing a command. Such responses must not be offered for execution.
+func (r *CommandResponse) NeedsClarification() bool {
+	return r.Command == "" && r.Clarification != ""
 }
 
-// SafetyChecker validates commands for safety
-type SafetyChecker struct {
-	dangerousPatterns []string
-	config            *config.Config
-	logger            *logrus.Entry
+// AppendClarification adds the model's question and the user's answer to a request
+// so it can be regenerated with the extra context
+func AppendClarification(input, question, answer string) string {
+	return fmt.Sprintf("%s\n\nClarifying question: %s\nAnswer: %s", input, question, answer)
 }
 
 func NewClient() (*Client, error) {
+	return NewClientWithProvider(nil)
+}
+
+// NewClientWithProvider creates a client that generates through the given provider.
+// A nil provider uses the one named by ai.provider.
+func NewClientWithProvider(provider Provider) (*Client, error) {
 	cfg, err := config.Load()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
+	ollamaClient := NewOllamaClient(cfg)
+	if provider == nil {
+		switch cfg.AI.Provider {
+		case "", "ollama":
+			provider = ollamaClient
+		case "openai":
+			provider = NewOpenAIClient(cfg)
+		default:
+			return nil, fmt.Errorf("unsupported AI provider %q: use 'ollama' or 'openai'", cfg.AI.Provider)
+		}
+	}
+
+	mode, err := ParseMode(cfg.AI.Mode)
+	if err != nil {
+		return nil, err
+	}
+
 	client := &Client{
-		ollamaClient:  NewOllamaClient(cfg),
+		ollamaClient:  ollamaClient,
+		provider:      provider,
 		modelManager:  NewModelManager(),
 		config:        cfg,
 		logger:        logger.GetLogger().WithField("component", "ai-client"),
 		safetyChecker: NewSafetyChecker(cfg),
+		mode:          mode,
 	}
 
 	return client, nil
 }
 
-func NewSafetyChecker(cfg *config.Config) *SafetyChecker {
-	return &SafetyChecker{
-		dangerousPatterns: cfg.Safety.DangerousCommands,
-		config:            cfg,
-		logger:            logger.GetLogger().WithField("component", "safety-checker"),
-	}
+// SetMode switches the mode used for subsequent requests
+func (c *Client) SetMode(mode Mode) {
+	c.mode = mode
 }
 
-func (c *Client) GenerateCommand(input string) (*CommandResponse, error) {
-	c.logger.WithField("input", input).Info("Generating command")
+// Mode returns the mode used for requests
+func (c *Client) Mode() Mode {
+	return c.mode
+}
 
-	// Check if Ollama is available
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// SetTool constrains generated commands to the named CLI tool; "" removes the constraint
+func (c *Client) SetTool(tool string) {
+	c.tool = strings.TrimSpace(tool)
+}
 
-	if err := c.ollamaClient.IsAvailable(ctx); err != nil {
-		return nil, fmt.Errorf("ollama service is not available: %w\n\nPlease ensure Ollama is installed and running:\n- Install: https://ollama.ai/download\n- Start: 'ollama serve'", err)
-	}
+// Tool returns the CLI tool generated commands are constrained to
+func (c *Client) Tool() string {
+	return c.tool
+}
 
-	// Check if model is available
-	currentModel := c.modelManager.GetCurrentModel()
-	if currentModel == nil {
-		return nil, fmt.Errorf("no AI model configured. Please run 'shell-agent download' first")
+func (c *Client) GenerateCommand(input string) (*CommandResponse, error) {
+	return c.GenerateCommandContext(context.Background(), input)
+}
+
+// GenerateCommandContext generates a command, aborting when ctx is cancelled
+func (c *Client) GenerateCommandContext(parent context.Context, input string) (*CommandResponse, error) {
+	// Validate the encoding before the input reaches logging or the request body
+	input, sanitized, err := SanitizeInput(input, c.config.AI.SanitizeInput)
+	if err != nil {
+		return nil, fmt.Errorf("%w: re-encode the input as UTF-8 or use --input-encoding=sanitize to replace invalid bytes", err)
+	}
+	if sanitized {
+		c.logger.Warn("Input contained invalid UTF-8, invalid bytes were replaced")
 	}
 
-	// Verify model exists in Ollama
-	if !c.modelManager.IsModelAvailableInOllama(currentModel.Name) {
-		return nil, fmt.Errorf("model '%s' is not available in Ollama. Please run 'shell-agent download' to install it", currentModel.Name)
+	c.logger.WithField("input", input).Info("Generating command")
+
+	modelName, err := c.prepareModel(parent)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create context with timeout
-	ctx, cancel = context.WithTimeout(context.Background(), time.Duration(c.config.AI.Timeout)*time.Second)
+	ctx, cancel := context.WithTimeout(parent, time.Duration(c.config.AI.Timeout)*time.Second)
 	defer cancel()
 
 	// Enhance prompt with system context
-	enhancedPrompt := c.enhancePrompt(input)
+	enhancedPrompt := c.enhancePrompt(input, modelName)
 
-	// Generate command using Ollama
-	response, err := c.ollamaClient.Generate(ctx, currentModel.Name, enhancedPrompt)
+	req := GenerateRequest{
+		Model:  modelName,
+		Prompt: enhancedPrompt,
+		System: c.systemPromptFor(c.mode),
+		Mode:   c.mode,
+	}
+	req.OnToken = c.streamsTo()
+
+	// Generate command using the provider
+	response, attempts, err := c.generateWithRecovery(ctx, req)
 	if err != nil {
+		// The provider's own error rarely says the model name is the problem
+		if mismatch := c.modelMismatch(); mismatch != "" && !c.supports(CapabilityLocalModels) {
+			return nil, fmt.Errorf("failed to generate command: %w (%s)", err, mismatch)
+		}
 		return nil, fmt.Errorf("failed to generate command: %w", err)
 	}
+	response.Attempts = attempts
+
+	// Scripts would rather fail than act on a guessed command
+	if response.Fallback && c.config.AI.StrictParsing {
+		return nil, fmt.Errorf("%w (model '%s')", ErrParseFailure, response.Model)
+	}
 
-	// Apply safety checks
-	if c.config.Safety.RequireConfirm {
-		c.safetyChecker.CheckCommand(response)
+	// Explain and review operate on the command the user supplied
+	if !c.mode.Generates() {
+		response.Command = input
 	}
 
+	// Calibrate confidence by command category before safety checks can cap it
+	if c.mode.Generates() && response.Command != "" {
+		response.Categories = CommandCategories(response.Command)
+		AdjustConfidence(response, c.config.Safety.ConfidenceAdjustments)
+	}
+
+	c.checkCommand(response)
+
+	// Drop alternatives beyond what the model was asked for
+	if limit := c.AlternativesFor(response.Model); len(response.Alternatives) > limit {
+		response.Alternatives = response.Alternatives[:limit]
+	}
+
+	// A templated command is filled in by the user before it runs
+	if c.template && c.mode == ModeShell {
+		response.Placeholders = Placeholders(response.Command)
+	}
+
+	// Order the command and its alternatives for display and selection
+	if c.mode == ModeShell {
+		c.rankCandidates(response)
+	}
+
+	if sanitized {
+		appendWarning(response, "⚠️ Your request contained invalid UTF-8; invalid bytes were replaced before sending")
+	}
+
+	// The command is only useful if the requested tool is installed
+	if c.mode == ModeShell && c.tool != "" {
+		if _, err := exec.LookPath(c.tool); err != nil {
+			appendWarning(response, fmt.Sprintf("⚠️ '%s' is not installed or not on your PATH", c.tool))
+		}
+	}
+
+	if c.observer != nil {
+		for _, finding := range response.Findings {
+			c.observer.OnSafetyFinding(finding)
+		}
+	}
+
+	c.recordTurn(input, response)
+
 	c.logger.WithFields(logrus.Fields{
 		"command":    response.Command,
 		"confidence": response.Confidence,
-		"model":      currentModel.Name,
+		"model":      response.Model,
+		"attempts":   len(response.Attempts),
 	}).Info("Generated command successfully")
 
+	if c.observer != nil {
+		c.observer.OnComplete(response)
+	}
+
 	return response, nil
 }
 
-func (c *Client) enhancePrompt(input string) string {
-	// Add system context
-	osInfo := runtime.GOOS
-	prompt := fmt.Sprintf(`Operating System: %s
+// checkCommand runs the checks on the command of a response: the safety rules
+// and, for generated shell commands, missing paths and programs and the
+// complexity budget
+func (c *Client) checkCommand(response *CommandResponse) {
+	// Apply safety checks. They always run, since block_destructive, the risk
+	// score and --emit plan rely on them; require_confirm only decides whether
+	// critical commands are confirmed.
+	c.safetyChecker.CheckCommand(response)
 
-User Request: %s
+	if c.mode != ModeShell || response.Command == "" {
+		return
+	}
 
-Please provide a shell command that accomplishes this request. Consider:
-1. The operating system is %s
-2. Use safe, commonly available commands
-3. Provide clear explanations
-4. Warn about any potential risks
-5. Suggest alternatives if helpful
+	// Point out paths that don't exist before the user runs the command
+	c.checkPaths(response)
 
-Respond in JSON format as specified in the system prompt.`, osInfo, input, osInfo)
+	// Flag programs that aren't installed, e.g. apt on macOS
+	if c.config.Safety.WarnMissingBinary {
+		c.checkBinaries(response)
+	}
 
-	return prompt
+	// Discourage gnarly one-liners
+	if c.config.Safety.ComplexityAction != ComplexityOff {
+		c.checkComplexity(response)
+	}
 }
 
-func (s *SafetyChecker) CheckCommand(response *CommandResponse) {
-	if response.Command == "" {
+// recheck returns a copy of response for another command, with the findings,
+// warnings and risk of the original replaced by those of the new command
+func (c *Client) recheck(response *CommandResponse, command string) *CommandResponse {
+	checked := *response
+	checked.Command = command
+	checked.Findings = nil
+	checked.RiskScore, checked.RiskFactors = 0, nil
+	checked.Warning = withoutFindings(response.Warning, response.Findings)
+	c.checkCommand(&checked)
+	return &checked
+}
+
+// checkPaths adds an advisory finding for relative paths that don't exist in the working directory
+func (c *Client) checkPaths(response *CommandResponse) {
+	workDir, err := os.Getwd()
+	if err != nil {
 		return
 	}
 
-	command := strings.ToLower(response.Command)
-
-	// Check for dangerous patterns
-	for _, pattern := range s.dangerousPatterns {
-		if strings.Contains(command, strings.ToLower(pattern)) {
-			warning := fmt.Sprintf("⚠️ DANGER: This command contains '%s' which can be destructive", pattern)
-			if response.Warning != "" {
-				response.Warning = response.Warning + "\n" + warning
-			} else {
-				response.Warning = warning
-			}
-
-			// Lower confidence for dangerous commands
-			if response.Confidence > 0.5 {
-				response.Confidence = 0.5
-			}
-
-			s.logger.WithFields(logrus.Fields{
-				"command": response.Command,
-				"pattern": pattern,
-			}).Warn("Dangerous command pattern detected")
-			break
-		}
+	missing := MissingPaths(response.Command, workDir)
+	if len(missing) == 0 {
+		return
 	}
 
-	// Additional safety checks
-	if strings.Contains(command, "sudo") && !strings.Contains(response.Warning, "sudo") {
-		addWarning := "⚠️ This command requires administrative privileges"
-		if response.Warning != "" {
-			response.Warning = response.Warning + "\n" + addWarning
-		} else {
-			response.Warning = addWarning
+	finding := Finding{
+		Rule:     RuleMissingPath,
+		Severity: SeverityWarning,
+		Pattern:  strings.Join(missing, ", "),
+		Message:  fmt.Sprintf("📁 Not found in %s: %s", workDir, strings.Join(missing, ", ")),
+	}
+	response.Findings = append(response.Findings, finding)
+	appendWarning(response, finding.Message)
+}
+
+// userShell returns the name of the user's login shell, defaulting to bash
+func userShell() string {
+	if shell := filepath.Base(os.Getenv("SHELL")); shell != "" && shell != "." {
+		return shell
+	}
+	return "bash"
+}
+
+// appendWarning adds a line to the response warning
+func appendWarning(response *CommandResponse, warning string) {
+	if response.Warning != "" {
+		response.Warning = response.Warning + "\n" + warning
+	} else {
+		response.Warning = warning
+	}
+}
+
+// prepareModel checks that Ollama is running and the configured model is
+// installed, and returns the model name. Providers without local models use
+// ai.default_model as is.
+func (c *Client) prepareModel(parent context.Context) (string, error) {
+	// Hosted providers serve their models themselves; a local model name is
+	// still sent, since custom deployments may use any name
+	if !c.supports(CapabilityLocalModels) {
+		if mismatch := c.modelMismatch(); mismatch != "" {
+			c.logger.Warn(mismatch)
 		}
+		return c.config.AI.DefaultModel, nil
+	}
+
+	// Check if Ollama is available
+	ctx, cancel := context.WithTimeout(parent, 5*time.Second)
+	defer cancel()
+
+	if err := c.ollamaClient.IsAvailable(ctx); err != nil {
+		return "", fmt.Errorf("ollama service is not available: %w\n\nPlease ensure Ollama is installed and running:\n- Install: https://ollama.ai/download\n- Start: 'ollama serve'", err)
+	}
+
+	// Check if model is available
+	currentModel := c.modelManager.GetCurrentModel()
+	if currentModel == nil {
+		return "", fmt.Errorf("no AI model configured. Please run 'shell-agent download' first")
 	}
 
-	// Check for recursive operations
-	if strings.Contains(command, "-r") && (strings.Contains(command, "rm") || strings.Contains(command, "chmod") || strings.Contains(command, "chown")) {
-		addWarning := "⚠️ This command will operate recursively on directories"
-		if response.Warning != "" {
-			response.Warning = response.Warning + "\n" + addWarning
-		} else {
-			response.Warning = addWarning
+	// A default model meant for another provider silently falls back to a local one
+	if mismatch := c.modelMismatch(); mismatch != "" && currentModel.Name != c.config.AI.DefaultModel {
+		c.logger.WithField("using", currentModel.Name).Warn(mismatch)
+	}
+
+	// Verify model exists in Ollama
+	if !c.modelManager.IsModelAvailableInOllama(currentModel.Name) {
+		return "", fmt.Errorf("model '%s' is not available in Ollama. Please run 'shell-agent download' to install it", currentModel.Name)
+	}
+
+	// A re-pulled tag may be a different build than the one that was pinned
+	if err := c.checkDigest(parent, currentModel.Name); err != nil {
+		return "", err
+	}
+
+	return currentModel.Name, nil
+}
+
+// modelMismatch returns why ai.default_model looks wrong for ai.provider, or ""
+func (c *Client) modelMismatch() string {
+	return config.ModelProviderMismatch(c.config.AI.Provider, c.config.AI.DefaultModel)
+}
+
+// checkDigest applies ai.pin_model_digest to the model about to be used
+func (c *Client) checkDigest(parent context.Context, modelName string) error {
+	switch c.config.AI.PinModelDigest {
+	case "", PinDigestOff:
+		return nil
+	case PinDigestWarn, PinDigestRefuse:
+	default:
+		return fmt.Errorf("invalid ai.pin_model_digest %q: must be one of %s, %s, %s", c.config.AI.PinModelDigest, PinDigestOff, PinDigestWarn, PinDigestRefuse)
+	}
+
+	ctx, cancel := context.WithTimeout(parent, 5*time.Second)
+	defer cancel()
+
+	err := c.modelManager.CheckDigest(ctx, modelName)
+	if err != nil && c.config.AI.PinModelDigest == PinDigestWarn {
+		c.logger.Warn(err.Error())
+		return nil
+	}
+	return err
+}
+
+// SystemPrompt returns the system prompt sent with requests in the current mode
+func (c *Client) SystemPrompt() string {
+	return c.systemPromptFor(c.mode)
+}
+
+// EnhancedPrompt returns the prompt that would be sent for input in the current
+// mode, without sending it
+func (c *Client) EnhancedPrompt(input string) string {
+	return c.enhancePrompt(input, c.RequestModel())
+}
+
+// RequestModel returns the model a request would be sent to. Unlike a real
+// request, it does not check that Ollama is running or the model installed.
+func (c *Client) RequestModel() string {
+	if c.supports(CapabilityLocalModels) {
+		if current := c.modelManager.GetCurrentModel(); current != nil {
+			return current.Name
 		}
 	}
+	return c.config.AI.DefaultModel
+}
+
+// AlternativesFor returns how many alternative commands to ask model for:
+// ai.alternatives when set, otherwise the model's catalog entry
+func (c *Client) AlternativesFor(model string) int {
+	if c.config.AI.Alternatives >= 0 {
+		return c.config.AI.Alternatives
+	}
+	if entry := CatalogEntry(model); entry != nil {
+		return entry.Alternatives
+	}
+	return defaultAlternatives
+}
+
+func (c *Client) enhancePrompt(input, model string) string {
+	return c.conversationContext() + c.requestPrompt(input, model)
+}
+
+// requestPrompt builds the prompt for a single request to model in the current mode
+func (c *Client) requestPrompt(input, model string) string {
+	// Add system context
+	osInfo := system.GOOS()
+
+	switch c.mode {
+	case ModeExplain:
+		return fmt.Sprintf(`Operating System: %s
+
+Command: %s
+
+Please explain what this command does. Respond in JSON format as specified in the system prompt.`, osInfo, input)
+	case ModeReview:
+		return fmt.Sprintf(`Operating System: %s
+
+Command: %s
+
+Please review this command for safety and portability issues. Respond in JSON format as specified in the system prompt.`, osInfo, input)
+	case ModeFunction:
+		return fmt.Sprintf(`Operating System: %s
+Shell: %s
+%s
+User Request: %s
+
+Please write a named shell function definition (not a single command) for this shell that accomplishes this request. Respond in JSON format as specified in the system prompt.`, osInfo, userShell(), c.workspaceContext(), input)
+	}
+
+	prompt := fmt.Sprintf(`Operating System: %s
+%s
+User Request: %s
+
+Please provide a shell command that accomplishes this request. Consider:
+1. The operating system is %s
+2. Use safe, commonly available commands
+3. Provide clear explanations
+4. Warn about any potential risks
+%s
+`, osInfo, c.workspaceContext(), input, osInfo, alternativesInstruction(c.AlternativesFor(model)))
+
+	// Optional instructions continue the numbered list
+	next := 6
+	if c.template {
+		prompt += fmt.Sprintf(templateInstruction, next)
+		next++
+	}
+	if c.tool != "" {
+		prompt += fmt.Sprintf("%d. The command MUST use the '%s' command-line tool; do not solve the request with a different tool\n", next, c.tool)
+	}
+
+	prompt += "\nRespond in JSON format as specified in the system prompt."
+
+	return prompt
+}
+
+// alternativesInstruction is the prompt line asking for count alternatives
+func alternativesInstruction(count int) string {
+	if count <= 0 {
+		return "5. Do not suggest alternatives"
+	}
+	return fmt.Sprintf("5. Suggest up to %d alternatives if helpful", count)
 }
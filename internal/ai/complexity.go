@@ -0,0 +1,148 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// RuleComplexity flags commands that exceed the complexity budget
+const RuleComplexity = "complexity"
+
+// Values for safety.complexity_action
+const (
+	ComplexityOff   = "off"
+	ComplexityWarn  = "warn"
+	ComplexityBlock = "block"
+)
+
+// simplerInstruction is added to a request regenerated because its command was too complex
+const simplerInstruction = "Use a simpler, multi-step approach: prefer several short commands in \"steps\" over one long pipeline."
+
+// Complexity measures how hard a command is to read
+type Complexity struct {
+	// Pipes counts unquoted | operators (|| is not a pipe)
+	Pipes int
+	// Subshells counts $(...), backticks, (...) and process substitutions
+	Subshells int
+	// Length is the command's length in characters
+	Length int
+}
+
+// ComplexityLimits is the complexity budget; a zero limit is not checked
+type ComplexityLimits struct {
+	MaxPipes     int
+	MaxSubshells int
+	MaxLength    int
+}
+
+// MeasureComplexity counts the pipes and subshells of a command, ignoring
+// anything inside single quotes. Substitutions inside double quotes still run,
+// so they are counted.
+func MeasureComplexity(command string) Complexity {
+	command = strings.TrimSpace(command)
+	complexity := Complexity{Length: utf8.RuneCountInString(command)}
+
+	runes := []rune(command)
+	backticks := 0
+	inSingle, inDouble := false, false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		next := func(offset int) rune {
+			if i+offset < len(runes) {
+				return runes[i+offset]
+			}
+			return 0
+		}
+
+		switch {
+		case inSingle:
+			if r == '\'' {
+				inSingle = false
+			}
+		case r == '\\':
+			i++
+		case r == '\'' && !inDouble:
+			inSingle = true
+		case r == '"':
+			inDouble = !inDouble
+		case r == '`':
+			backticks++
+		case r == '$' && next(1) == '(':
+			// $(( )) is arithmetic, not a subshell
+			if next(2) == '(' {
+				i += 2
+				continue
+			}
+			complexity.Subshells++
+			i++
+		case inDouble:
+		case r == '(':
+			complexity.Subshells++
+		case r == '|':
+			if next(1) == '|' {
+				i++
+				continue
+			}
+			complexity.Pipes++
+		}
+	}
+	complexity.Subshells += backticks / 2
+
+	return complexity
+}
+
+// Exceeds describes each limit the command goes over, or returns nil when it
+// is within budget
+func (c Complexity) Exceeds(limits ComplexityLimits) []string {
+	var reasons []string
+	if limits.MaxPipes > 0 && c.Pipes > limits.MaxPipes {
+		reasons = append(reasons, fmt.Sprintf("%d pipes (limit %d)", c.Pipes, limits.MaxPipes))
+	}
+	if limits.MaxSubshells > 0 && c.Subshells > limits.MaxSubshells {
+		reasons = append(reasons, fmt.Sprintf("%d subshells (limit %d)", c.Subshells, limits.MaxSubshells))
+	}
+	if limits.MaxLength > 0 && c.Length > limits.MaxLength {
+		reasons = append(reasons, fmt.Sprintf("%d characters (limit %d)", c.Length, limits.MaxLength))
+	}
+	return reasons
+}
+
+// TooComplex reports whether the command went over the complexity budget
+func (r *CommandResponse) TooComplex() bool {
+	for _, finding := range r.Findings {
+		if finding.Rule == RuleComplexity {
+			return true
+		}
+	}
+	return false
+}
+
+// AppendSimplification asks the model to redo a request with a simpler command
+func AppendSimplification(input string) string {
+	return input + "\n\n" + simplerInstruction
+}
+
+// checkComplexity adds an advisory finding when the command is over the
+// safety.max_* complexity budget
+func (c *Client) checkComplexity(response *CommandResponse) {
+	limits := ComplexityLimits{
+		MaxPipes:     c.config.Safety.MaxPipes,
+		MaxSubshells: c.config.Safety.MaxSubshells,
+		MaxLength:    c.config.Safety.MaxCommandLength,
+	}
+
+	reasons := MeasureComplexity(response.Command).Exceeds(limits)
+	if len(reasons) == 0 {
+		return
+	}
+
+	finding := Finding{
+		Rule:     RuleComplexity,
+		Severity: SeverityWarning,
+		Pattern:  strings.Join(reasons, ", "),
+		Message:  fmt.Sprintf("🧩 Hard to read: %s. Consider a simpler, multi-step approach", strings.Join(reasons, ", ")),
+	}
+	response.Findings = append(response.Findings, finding)
+	appendWarning(response, finding.Message)
+}
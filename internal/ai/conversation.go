@@ -0,0 +1,81 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxContextMessages bounds how many prior messages are included in a prompt
+const maxContextMessages = 10
+
+// Message is a single turn in a conversation
+type Message struct {
+	Role    string `json:"role"` // "user" or "assistant"
+	Content string `json:"content"`
+}
+
+// Conversation holds prior turns so follow-up requests have context
+type Conversation struct {
+	Messages []Message `json:"messages"`
+}
+
+// Add appends a message to the conversation
+func (c *Conversation) Add(role, content string) {
+	c.Messages = append(c.Messages, Message{Role: role, Content: content})
+}
+
+// Reset clears all messages
+func (c *Conversation) Reset() {
+	c.Messages = nil
+}
+
+// SetConversation enables multi-turn context for subsequent requests. A nil
+// conversation disables it.
+func (c *Client) SetConversation(conversation *Conversation) {
+	c.conversation = conversation
+}
+
+// Conversation returns the active conversation, or nil when context is disabled
+func (c *Client) Conversation() *Conversation {
+	return c.conversation
+}
+
+// conversationContext renders the most recent messages for inclusion in a prompt
+func (c *Client) conversationContext() string {
+	if c.conversation == nil || len(c.conversation.Messages) == 0 {
+		return ""
+	}
+
+	messages := c.conversation.Messages
+	if len(messages) > maxContextMessages {
+		messages = messages[len(messages)-maxContextMessages:]
+	}
+
+	var b strings.Builder
+	b.WriteString("Previous conversation (use it to resolve follow-up requests):\n")
+	for _, msg := range messages {
+		role := "User"
+		if msg.Role == "assistant" {
+			role = "Assistant"
+		}
+		fmt.Fprintf(&b, "%s: %s\n", role, msg.Content)
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// recordTurn adds a completed request and its answer to the conversation
+func (c *Client) recordTurn(input string, response *CommandResponse) {
+	if c.conversation == nil {
+		return
+	}
+
+	answer := response.Command
+	if response.NeedsClarification() {
+		answer = response.Clarification
+	}
+
+	c.conversation.Add("user", input)
+	c.conversation.Add("assistant", answer)
+}
@@ -0,0 +1,104 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrDigestMismatch is returned when the installed model is not the build that was pinned at download time
+var ErrDigestMismatch = errors.New("model digest differs from the pinned digest")
+
+// Values for ai.pin_model_digest
+const (
+	PinDigestOff    = "off"
+	PinDigestWarn   = "warn"
+	PinDigestRefuse = "refuse"
+)
+
+// ModelMetadata is the local record written when a model is downloaded
+type ModelMetadata struct {
+	Name         string `json:"name"`
+	OllamaName   string `json:"ollama_name"`
+	Description  string `json:"description"`
+	Size         string `json:"size"`
+	Type         string `json:"type"`
+	Recommended  bool   `json:"recommended"`
+	DownloadedAt string `json:"downloaded_at"`
+	Source       string `json:"source"`
+	// Digest is the Ollama digest of the model when it was downloaded
+	Digest string `json:"digest,omitempty"`
+}
+
+// metadataPath returns where the metadata for a model is stored
+func (m *ModelManager) metadataPath(modelName string) string {
+	return filepath.Join(m.modelsPath, modelName, "metadata.json")
+}
+
+// LoadMetadata reads the local metadata recorded for a model
+func (m *ModelManager) LoadMetadata(modelName string) (*ModelMetadata, error) {
+	data, err := os.ReadFile(m.metadataPath(modelName))
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata ModelMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata for %s: %w", modelName, err)
+	}
+	return &metadata, nil
+}
+
+// liveDigest returns the digest Ollama reports for a model, or "" if it isn't listed
+func liveDigest(modelName string, models []OllamaModel) string {
+	for _, model := range models {
+		if model.Name == modelName || model.Name == modelName+":latest" {
+			return model.Digest
+		}
+	}
+	return ""
+}
+
+// DigestMismatch reports whether the model Ollama lists has a different digest
+// than the pinned one, returning the live digest. A model without a pinned or
+// live digest cannot be compared and never mismatches.
+func DigestMismatch(pinned, modelName string, models []OllamaModel) (string, bool) {
+	live := liveDigest(modelName, models)
+	if pinned == "" || live == "" {
+		return live, false
+	}
+	return live, live != pinned
+}
+
+// CheckDigest compares the digest pinned in a model's metadata with the one Ollama
+// currently serves, returning an error wrapping ErrDigestMismatch if they differ
+func (m *ModelManager) CheckDigest(ctx context.Context, modelName string) error {
+	metadata, err := m.LoadMetadata(modelName)
+	if err != nil || metadata.Digest == "" {
+		m.logger.WithField("model", modelName).Debug("No pinned digest, skipping digest check")
+		return nil
+	}
+
+	models, err := m.ollamaClient.ListModels(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list Ollama models: %w", err)
+	}
+
+	if live, mismatch := DigestMismatch(metadata.Digest, modelName, models); mismatch {
+		return fmt.Errorf("%w: '%s' was pinned at %s but Ollama now serves %s; run 'shell-agent download' to re-pin it", ErrDigestMismatch, modelName, shortDigest(metadata.Digest), shortDigest(live))
+	}
+	return nil
+}
+
+// shortDigest abbreviates a digest for messages
+func shortDigest(digest string) string {
+	digest = strings.TrimPrefix(digest, "sha256:")
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
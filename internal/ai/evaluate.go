@@ -0,0 +1,87 @@
+package ai
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ParseEvaluation summarizes how reliably a model returned parseable JSON
+type ParseEvaluation struct {
+	Model         string  `json:"model"`
+	Total         int     `json:"total"`
+	Parsed        int     `json:"parsed"`
+	Fallbacks     int     `json:"fallbacks"`
+	Errors        int     `json:"errors"`
+	AvgConfidence float64 `json:"avg_confidence"`
+}
+
+// SuccessRate returns the fraction of prompts whose response parsed without the fallback
+func (e ParseEvaluation) SuccessRate() float64 {
+	if e.Total == 0 {
+		return 0
+	}
+	return float64(e.Parsed) / float64(e.Total)
+}
+
+// IsAvailable reports whether the provider answers. Only local providers are
+// checked; hosted ones report errors on the first request instead.
+func (c *Client) IsAvailable(ctx context.Context) error {
+	if !c.supports(CapabilityLocalModels) {
+		return nil
+	}
+	return c.ollamaClient.IsAvailable(ctx)
+}
+
+// EvaluateParsing runs every prompt through every model and reports, per model, how
+// often the response was parsed as JSON rather than through the fallback heuristics.
+// The average confidence only covers successfully parsed responses.
+func (c *Client) EvaluateParsing(ctx context.Context, models, prompts []string) []ParseEvaluation {
+	results := make([]ParseEvaluation, 0, len(models))
+
+	for _, model := range models {
+		eval := ParseEvaluation{Model: model}
+		var confidenceSum float64
+
+		for _, prompt := range prompts {
+			eval.Total++
+
+			genCtx, cancel := context.WithTimeout(ctx, time.Duration(c.config.AI.Timeout)*time.Second)
+			response, err := c.provider.Generate(genCtx, c.prepareRequest(GenerateRequest{
+				Model:  model,
+				Prompt: c.enhancePrompt(prompt, model),
+				System: c.systemPromptFor(c.mode),
+				Mode:   c.mode,
+			}))
+			cancel()
+
+			if err != nil {
+				eval.Errors++
+				c.logger.WithError(err).WithField("model", model).Debug("Generation failed during parse evaluation")
+				continue
+			}
+
+			if response.Fallback {
+				eval.Fallbacks++
+				continue
+			}
+
+			eval.Parsed++
+			confidenceSum += response.Confidence
+		}
+
+		if eval.Parsed > 0 {
+			eval.AvgConfidence = confidenceSum / float64(eval.Parsed)
+		}
+
+		c.logger.WithFields(logrus.Fields{
+			"model":        model,
+			"success_rate": eval.SuccessRate(),
+		}).Info("Parse evaluation completed")
+
+		results = append(results, eval)
+	}
+
+	return results
+}
@@ -0,0 +1,92 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/system"
+)
+
+// FlagExplanation is the meaning of one flag or argument of a command
+type FlagExplanation struct {
+	Flag    string `json:"flag"`
+	Meaning string `json:"meaning"`
+}
+
+const flagsSystemPrompt = `You are a shell command expert. Your job is to explain each flag and argument of a shell command.
+
+IMPORTANT RULES:
+1. List every flag and argument in the order it appears, including combined short flags separately (-la is -l and -a)
+2. Give each a one-line meaning
+3. Do not suggest a new command
+
+Response format should be JSON with these fields:
+{
+  "flags": [{"flag": "-l", "meaning": "use the long listing format"}],
+  "confidence": 0.95
+}
+`
+
+// ExplainFlags asks the model for a one-line meaning of each flag and argument of command
+func (c *Client) ExplainFlags(parent context.Context, command string) ([]FlagExplanation, error) {
+	if strings.TrimSpace(command) == "" {
+		return nil, fmt.Errorf("there is no command to explain")
+	}
+
+	modelName, err := c.prepareModel(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(parent, time.Duration(c.config.AI.Timeout)*time.Second)
+	defer cancel()
+
+	req := GenerateRequest{
+		Model: modelName,
+		Prompt: fmt.Sprintf(`Operating System: %s
+
+Command: %s
+
+Explain each flag and argument of this command. Respond in JSON format as specified in the system prompt.`, system.GOOS(), command),
+		System: flagsSystemPrompt,
+		Mode:   ModeExplain,
+	}
+
+	response, _, err := c.generateWithRecovery(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain flags: %w", err)
+	}
+	if len(response.Flags) == 0 {
+		return nil, fmt.Errorf("model '%s' did not return flag explanations", modelName)
+	}
+	return response.Flags, nil
+}
+
+// parseFlags reads the "flags" field, a list of {"flag": ..., "meaning": ...}
+// objects or of "flag: meaning" strings
+func parseFlags(value interface{}) []FlagExplanation {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var flags []FlagExplanation
+	for _, item := range items {
+		var flag FlagExplanation
+		switch item := item.(type) {
+		case string:
+			name, meaning, _ := strings.Cut(item, ":")
+			flag = FlagExplanation{Flag: strings.TrimSpace(name), Meaning: strings.TrimSpace(meaning)}
+		case map[string]interface{}:
+			name, _ := item["flag"].(string)
+			meaning, _ := item["meaning"].(string)
+			flag = FlagExplanation{Flag: strings.TrimSpace(name), Meaning: strings.TrimSpace(meaning)}
+		}
+		if flag.Flag != "" {
+			flags = append(flags, flag)
+		}
+	}
+	return flags
+}
@@ -0,0 +1,25 @@
+package ai
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidEncoding is returned when a prompt is not valid UTF-8 and sanitizing is disabled
+var ErrInvalidEncoding = errors.New("input is not valid UTF-8")
+
+// SanitizeInput validates that the input is valid UTF-8. When sanitize is true, invalid
+// bytes are replaced with the Unicode replacement character and the returned bool reports
+// whether anything was replaced. When sanitize is false, invalid input is rejected.
+func SanitizeInput(input string, sanitize bool) (string, bool, error) {
+	if utf8.ValidString(input) {
+		return input, false, nil
+	}
+
+	if !sanitize {
+		return "", false, ErrInvalidEncoding
+	}
+
+	return strings.ToValidUTF8(input, string(utf8.RuneError)), true, nil
+}
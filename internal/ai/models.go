@@ -2,6 +2,7 @@ package ai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -23,86 +24,139 @@ type ModelInfo struct {
 	Path        string
 	OllamaName  string // The actual model name in Ollama
 	Recommended bool   // Whether this model is recommended for shell commands
+	// Alternatives is how many alternative commands to ask this model for; small
+	// models are asked for none because theirs are rarely useful
+	Alternatives int
+}
+
+// ModelLister lists the models installed in Ollama
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]OllamaModel, error)
 }
 
 type ModelManager struct {
 	modelsPath   string
 	ollamaClient *OllamaClient
+	lister       ModelLister
 	logger       *logrus.Entry
 	config       *config.Config
 }
 
 func NewModelManager() *ModelManager {
+	return NewModelManagerWithLister(nil)
+}
+
+// NewModelManagerWithLister creates a model manager that checks installed models
+// through lister. A nil lister uses the configured Ollama client.
+func NewModelManagerWithLister(lister ModelLister) *ModelManager {
 	cfg, _ := config.Load()
+	ollamaClient := NewOllamaClient(cfg)
+	if lister == nil {
+		lister = ollamaClient
+	}
+
 	return &ModelManager{
 		modelsPath:   config.GetModelPath(),
-		ollamaClient: NewOllamaClient(cfg),
+		ollamaClient: ollamaClient,
+		lister:       lister,
 		logger:       logger.GetLogger().WithField("component", "model-manager"),
 		config:       cfg,
 	}
 }
 
+// defaultAlternatives is how many alternatives to ask models outside the catalog for
+const defaultAlternatives = 2
+
+// modelCatalog lists the models shell-agent knows how to download and use
+var modelCatalog = []ModelInfo{
+	{
+		Name:         "llama3.2:3b",
+		OllamaName:   "llama3.2:3b",
+		Description:  "Llama 3.2 3B - Fast and efficient for command generation",
+		Size:         "2.0GB",
+		Type:         "Language Model",
+		Alternatives: 2,
+		Recommended:  true,
+	},
+	{
+		Name:         "llama3.2:1b",
+		OllamaName:   "llama3.2:1b",
+		Description:  "Llama 3.2 1B - Ultra-fast and lightweight",
+		Size:         "1.3GB",
+		Type:         "Language Model",
+		Alternatives: 0,
+		Recommended:  true,
+	},
+	{
+		Name:         "codegemma:7b",
+		OllamaName:   "codegemma:7b",
+		Description:  "CodeGemma 7B - Specialized for code and shell commands",
+		Size:         "5.0GB",
+		Type:         "Code Model",
+		Alternatives: 3,
+		Recommended:  true,
+	},
+	{
+		Name:         "llama3.1:8b",
+		OllamaName:   "llama3.1:8b",
+		Description:  "Llama 3.1 8B - Balanced performance and accuracy",
+		Size:         "4.7GB",
+		Type:         "Language Model",
+		Alternatives: 3,
+		Recommended:  false,
+	},
+	{
+		Name:         "mistral:7b",
+		OllamaName:   "mistral:7b",
+		Description:  "Mistral 7B - Good general purpose model",
+		Size:         "4.1GB",
+		Type:         "Language Model",
+		Alternatives: 2,
+		Recommended:  false,
+	},
+	{
+		Name:         "phi3:mini",
+		OllamaName:   "phi3:mini",
+		Description:  "Phi-3 Mini - Microsoft's compact model",
+		Size:         "2.3GB",
+		Type:         "Small Model",
+		Alternatives: 1,
+		Recommended:  false,
+	},
+}
+
+// ListAvailableModels returns the catalog with each model's download state. A
+// model is downloaded when it has local metadata and is installed in Ollama;
+// Ollama is asked for its models at most once.
 func (m *ModelManager) ListAvailableModels() []ModelInfo {
-	models := []ModelInfo{
-		{
-			Name:        "llama3.2:3b",
-			OllamaName:  "llama3.2:3b",
-			Description: "Llama 3.2 3B - Fast and efficient for command generation",
-			Size:        "2.0GB",
-			Type:        "Language Model",
-			Recommended: true,
-			Downloaded:  m.isModelDownloaded("llama3.2:3b"),
-		},
-		{
-			Name:        "llama3.2:1b",
-			OllamaName:  "llama3.2:1b",
-			Description: "Llama 3.2 1B - Ultra-fast and lightweight",
-			Size:        "1.3GB",
-			Type:        "Language Model",
-			Recommended: true,
-			Downloaded:  m.isModelDownloaded("llama3.2:1b"),
-		},
-		{
-			Name:        "codegemma:7b",
-			OllamaName:  "codegemma:7b",
-			Description: "CodeGemma 7B - Specialized for code and shell commands",
-			Size:        "5.0GB",
-			Type:        "Code Model",
-			Recommended: true,
-			Downloaded:  m.isModelDownloaded("codegemma:7b"),
-		},
-		{
-			Name:        "llama3.1:8b",
-			OllamaName:  "llama3.1:8b",
-			Description: "Llama 3.1 8B - Balanced performance and accuracy",
-			Size:        "4.7GB",
-			Type:        "Language Model",
-			Recommended: false,
-			Downloaded:  m.isModelDownloaded("llama3.1:8b"),
-		},
-		{
-			Name:        "mistral:7b",
-			OllamaName:  "mistral:7b",
-			Description: "Mistral 7B - Good general purpose model",
-			Size:        "4.1GB",
-			Type:        "Language Model",
-			Recommended: false,
-			Downloaded:  m.isModelDownloaded("mistral:7b"),
-		},
-		{
-			Name:        "phi3:mini",
-			OllamaName:  "phi3:mini",
-			Description: "Phi-3 Mini - Microsoft's compact model",
-			Size:        "2.3GB",
-			Type:        "Small Model",
-			Recommended: false,
-			Downloaded:  m.isModelDownloaded("phi3:mini"),
-		},
+	models := make([]ModelInfo, len(modelCatalog))
+	copy(models, modelCatalog)
+
+	var installed []OllamaModel
+	fetched := false
+	for i := range models {
+		if !m.hasLocalMetadata(models[i].Name) {
+			continue
+		}
+		if !fetched {
+			installed, fetched = m.installedModels(), true
+		}
+		models[i].Downloaded = isInstalled(models[i].Name, installed)
 	}
 
 	return models
 }
 
+// CatalogEntry returns the catalog entry for a model, or nil for models outside the catalog
+func CatalogEntry(name string) *ModelInfo {
+	for _, model := range modelCatalog {
+		if model.Name == name || model.OllamaName == name {
+			return &model
+		}
+	}
+	return nil
+}
+
 func (m *ModelManager) GetCurrentModel() *ModelInfo {
 	defaultModel := config.GetDefaultModel()
 	models := m.ListAvailableModels()
@@ -204,8 +258,16 @@ func (m *ModelManager) DownloadModel(modelName string) error {
 		return fmt.Errorf("failed to download model %s: %w", modelName, err)
 	}
 
+	// Record the digest so later runs can detect a re-pulled tag
+	var digest string
+	if models, err := m.ollamaClient.ListModels(ctx); err == nil {
+		digest = liveDigest(modelInfo.OllamaName, models)
+	} else {
+		m.logger.WithError(err).Warn("Failed to read the model digest")
+	}
+
 	// Create local metadata (optional, for our tracking)
-	if err := m.createModelMetadata(modelInfo); err != nil {
+	if err := m.createModelMetadata(modelInfo, digest); err != nil {
 		m.logger.WithError(err).Warn("Failed to create local metadata, but model is available in Ollama")
 	}
 
@@ -218,7 +280,7 @@ func (m *ModelManager) DownloadModel(modelName string) error {
 	return nil
 }
 
-func (m *ModelManager) createModelMetadata(modelInfo *ModelInfo) error {
+func (m *ModelManager) createModelMetadata(modelInfo *ModelInfo, digest string) error {
 	// Ensure models directory exists
 	if err := os.MkdirAll(m.modelsPath, 0755); err != nil {
 		return fmt.Errorf("failed to create models directory %s: %w", m.modelsPath, err)
@@ -230,54 +292,52 @@ func (m *ModelManager) createModelMetadata(modelInfo *ModelInfo) error {
 	}
 
 	// Create metadata file
-	metadataFile := filepath.Join(modelPath, "metadata.json")
-	file, err := os.Create(metadataFile)
+	metadata, err := json.MarshalIndent(ModelMetadata{
+		Name:         modelInfo.Name,
+		OllamaName:   modelInfo.OllamaName,
+		Description:  modelInfo.Description,
+		Size:         modelInfo.Size,
+		Type:         modelInfo.Type,
+		Recommended:  modelInfo.Recommended,
+		DownloadedAt: time.Now().Format(time.RFC3339),
+		Source:       "ollama",
+		Digest:       digest,
+	}, "", "    ")
 	if err != nil {
-		return fmt.Errorf("failed to create metadata file: %w", err)
+		return fmt.Errorf("failed to encode metadata: %w", err)
 	}
-	defer file.Close()
-
-	metadata := fmt.Sprintf(`{
-    "name": "%s",
-    "ollama_name": "%s",
-    "description": "%s",
-    "size": "%s",
-    "type": "%s",
-    "recommended": %t,
-    "downloaded_at": "%s",
-    "source": "ollama"
-}`, modelInfo.Name, modelInfo.OllamaName, modelInfo.Description,
-		modelInfo.Size, modelInfo.Type, modelInfo.Recommended, time.Now().Format(time.RFC3339))
-
-	_, err = file.WriteString(metadata)
-	return err
-}
 
-func (m *ModelManager) isModelDownloaded(modelName string) bool {
-	// Check both local metadata and Ollama availability
-	return m.hasLocalMetadata(modelName) && m.IsModelAvailableInOllama(modelName)
+	if err := os.WriteFile(m.metadataPath(modelInfo.Name), metadata, 0644); err != nil {
+		return fmt.Errorf("failed to create metadata file: %w", err)
+	}
+	return nil
 }
 
 func (m *ModelManager) hasLocalMetadata(modelName string) bool {
-	metadataPath := filepath.Join(m.modelsPath, modelName, "metadata.json")
-	_, err := os.Stat(metadataPath)
+	_, err := os.Stat(m.metadataPath(modelName))
 	return err == nil
 }
 
 func (m *ModelManager) IsModelAvailableInOllama(modelName string) bool {
+	return isInstalled(modelName, m.installedModels())
+}
+
+// installedModels lists the models installed in Ollama, or nil when Ollama
+// cannot be reached
+func (m *ModelManager) installedModels() []OllamaModel {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err := m.ollamaClient.IsAvailable(ctx); err != nil {
-		return false
-	}
-
-	models, err := m.ollamaClient.ListModels(ctx)
+	models, err := m.lister.ListModels(ctx)
 	if err != nil {
 		m.logger.WithError(err).Debug("Failed to list Ollama models")
-		return false
+		return nil
 	}
+	return models
+}
 
+// isInstalled reports whether modelName is among the installed models
+func isInstalled(modelName string, models []OllamaModel) bool {
 	for _, model := range models {
 		// Check both exact match and name without tag
 		if model.Name == modelName {
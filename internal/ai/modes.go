@@ -0,0 +1,118 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mode selects what the assistant does with the user's input
+type Mode string
+
+const (
+	// ModeShell generates a shell command from a natural language request
+	ModeShell Mode = "shell"
+	// ModeExplain explains an existing shell command
+	ModeExplain Mode = "explain"
+	// ModeReview critiques an existing shell command for safety and portability
+	ModeReview Mode = "review"
+	// ModeFunction generates a reusable, named shell function
+	ModeFunction Mode = "function"
+)
+
+// Modes lists all supported modes
+var Modes = []Mode{ModeShell, ModeExplain, ModeReview, ModeFunction}
+
+// Generates reports whether the mode produces new code rather than describing the user's command
+func (m Mode) Generates() bool {
+	return m == ModeShell || m == ModeFunction || m == ""
+}
+
+// ParseMode converts a string into a Mode, defaulting to ModeShell when empty
+func ParseMode(s string) (Mode, error) {
+	if s == "" {
+		return ModeShell, nil
+	}
+	for _, mode := range Modes {
+		if strings.EqualFold(s, string(mode)) {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("unknown mode %q: use shell, explain, review or function", s)
+}
+
+const explainSystemPrompt = `You are a shell command expert. Your job is to explain existing shell commands clearly and accurately.
+
+IMPORTANT RULES:
+1. Explain what the command does as a whole, then what each part and flag does
+2. Do not suggest a different command
+3. Mention any side effects or risks
+
+Response format should be JSON with these fields:
+{
+  "explanation": "what the command does, part by part",
+  "warning": "any side effects or risks (optional)",
+  "confidence": 0.95
+}
+`
+
+const reviewSystemPrompt = `You are a careful shell command reviewer. Your job is to critique existing shell commands for safety and portability issues.
+
+IMPORTANT RULES:
+1. Identify destructive, irreversible or privilege-escalating behavior
+2. Identify portability problems across operating systems and shells
+3. Point out quoting, globbing and error-handling pitfalls
+4. Do not generate a replacement command
+
+Response format should be JSON with these fields:
+{
+  "summary": "one sentence overall assessment",
+  "issues": ["each safety or portability issue found"],
+  "risk": "low, medium or high",
+  "confidence": 0.95
+}
+`
+
+const functionSystemPrompt = `You are a shell scripting expert. Your job is to turn requests into reusable, named shell functions.
+
+IMPORTANT RULES:
+1. Respond with one complete function definition that can be pasted into a shell rc file
+2. Give the function a short, descriptive snake_case name
+3. Take inputs as positional parameters and validate them
+4. Quote variables and return a non-zero status on failure
+5. Warn about potentially dangerous operations
+
+Response format should be JSON with these fields:
+{
+  "command": "the full multi-line function definition",
+  "explanation": "what the function does and how to call it",
+  "warning": "any safety warnings or considerations (optional)",
+  "confidence": 0.95
+}
+`
+
+const clarificationPrompt = `
+CLARIFYING QUESTIONS:
+If the request is ambiguous and you would have low confidence in any command, do not guess.
+Instead leave "command" empty and respond with a short question for the user:
+{
+  "clarification": "the question to ask the user",
+  "confidence": 0.0
+}
+`
+
+// systemPromptFor returns the system prompt for a mode
+func (c *Client) systemPromptFor(mode Mode) string {
+	switch mode {
+	case ModeExplain:
+		return explainSystemPrompt
+	case ModeReview:
+		return reviewSystemPrompt
+	case ModeFunction:
+		return functionSystemPrompt
+	default:
+		if c.config.AI.AskWhenAmbiguous {
+			return c.config.AI.SystemPrompt + clarificationPrompt
+		}
+		return c.config.AI.SystemPrompt
+	}
+}
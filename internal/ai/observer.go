@@ -0,0 +1,38 @@
+package ai
+
+// Observer receives progress events while a command is generated. Library
+// consumers use it to follow a generation without parsing stdout.
+type Observer interface {
+	// OnToken is called for each piece of model output as it streams in.
+	// Re-prompts and escalations stream their tokens too.
+	OnToken(token string)
+	// OnSafetyFinding is called for each safety finding on the final command
+	OnSafetyFinding(finding Finding)
+	// OnComplete is called once with the final response
+	OnComplete(response *CommandResponse)
+}
+
+// TokenConsumer is implemented by observers that can decline the token events.
+// Observers without it receive every token.
+type TokenConsumer interface {
+	// ConsumesTokens reports whether OnToken should be called at all
+	ConsumesTokens() bool
+}
+
+// SetObserver registers an observer for subsequent requests; nil removes it.
+// Responses are streamed from the provider when the observer consumes tokens.
+func (c *Client) SetObserver(observer Observer) {
+	c.observer = observer
+}
+
+// streamsTo returns the observer's token callback, or nil when there is no
+// observer or it declines tokens, so the response is not streamed
+func (c *Client) streamsTo() func(token string) {
+	if c.observer == nil {
+		return nil
+	}
+	if consumer, ok := c.observer.(TokenConsumer); ok && !consumer.ConsumesTokens() {
+		return nil
+	}
+	return c.observer.OnToken
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -31,6 +32,10 @@ type OllamaRequest struct {
 	Stream  bool                   `json:"stream"`
 	Options map[string]interface{} `json:"options,omitempty"`
 	Format  string                 `json:"format,omitempty"`
+	// Think asks reasoning models to return their thinking separately from the response
+	Think bool `json:"think,omitempty"`
+	// KeepAlive is how long Ollama keeps the model loaded after the request
+	KeepAlive string `json:"keep_alive,omitempty"`
 }
 
 // OllamaResponse represents the response from Ollama API
@@ -38,6 +43,7 @@ type OllamaResponse struct {
 	Model              string    `json:"model"`
 	CreatedAt          time.Time `json:"created_at"`
 	Response           string    `json:"response"`
+	Thinking           string    `json:"thinking,omitempty"`
 	Done               bool      `json:"done"`
 	Context            []int     `json:"context,omitempty"`
 	TotalDuration      int64     `json:"total_duration,omitempty"`
@@ -86,7 +92,10 @@ type OllamaPullResponse struct {
 
 // NewOllamaClient creates a new Ollama client
 func NewOllamaClient(cfg *config.Config) *OllamaClient {
-	baseURL := fmt.Sprintf("http://%s:%d", cfg.AI.Ollama.Host, cfg.AI.Ollama.Port)
+	baseURL := cfg.AI.Ollama.BaseURL
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("http://%s:%d", cfg.AI.Ollama.Host, cfg.AI.Ollama.Port)
+	}
 
 	return &OllamaClient{
 		baseURL: baseURL,
@@ -118,6 +127,38 @@ func (c *OllamaClient) IsAvailable(ctx context.Context) error {
 	return nil
 }
 
+// availabilityProbeTimeout bounds each check made while polling for Ollama
+const availabilityProbeTimeout = 3 * time.Second
+
+// PollAvailable runs check every interval until it succeeds or ctx is done, in
+// which case the last check error is returned. progress, when set, is called
+// with the attempt number after each failed check.
+func PollAvailable(ctx context.Context, interval time.Duration, check func(context.Context) error, progress func(attempt int)) error {
+	for attempt := 1; ; attempt++ {
+		probeCtx, cancel := context.WithTimeout(ctx, availabilityProbeTimeout)
+		err := check(probeCtx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		if progress != nil {
+			progress(attempt)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("gave up after %d attempts: %w", attempt, err)
+		case <-time.After(interval):
+		}
+	}
+}
+
+// WaitUntilAvailable polls IsAvailable until Ollama responds or ctx is done
+func (c *OllamaClient) WaitUntilAvailable(ctx context.Context, interval time.Duration, progress func(attempt int)) error {
+	return PollAvailable(ctx, interval, c.IsAvailable, progress)
+}
+
 // ListModels retrieves all available models from Ollama
 func (c *OllamaClient) ListModels(ctx context.Context) ([]OllamaModel, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/api/tags", nil)
@@ -136,7 +177,7 @@ func (c *OllamaClient) ListModels(ctx context.Context) ([]OllamaModel, error) {
 	}
 
 	var listResp OllamaListResponse
-	if err := json.NewDecoder(resp.Body).Decode(&listResp); err != nil {
+	if err := json.NewDecoder(c.limitBody(resp.Body)).Decode(&listResp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
@@ -171,9 +212,12 @@ func (c *OllamaClient) PullModel(ctx context.Context, modelName string, progress
 		return fmt.Errorf("ollama API returned status %d", resp.StatusCode)
 	}
 
-	decoder := json.NewDecoder(resp.Body)
+	// The pull stream lasts as long as the download, so the limit applies to each message
+	body := c.limitBody(resp.Body)
+	decoder := json.NewDecoder(body)
 	for {
 		var pullResp OllamaPullResponse
+		body.reset()
 		if err := decoder.Decode(&pullResp); err != nil {
 			if err == io.EOF {
 				break
@@ -207,20 +251,90 @@ func (c *OllamaClient) PullModel(ctx context.Context, modelName string, progress
 	return nil
 }
 
+// ollamaCapabilities are the features of the Ollama API
+var ollamaCapabilities = NewCapabilities(
+	CapabilityStreaming,
+	CapabilityStructuredOutput,
+	CapabilityThinking,
+	CapabilityKeepAlive,
+	CapabilityLocalModels,
+	CapabilityEmbeddings,
+)
+
+// Capabilities reports that Ollama supports every optional feature
+func (c *OllamaClient) Capabilities() Capabilities {
+	return ollamaCapabilities
+}
+
+// ollamaEmbedRequest is the payload of /api/embed
+type ollamaEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// ollamaEmbedResponse is the response of /api/embed
+type ollamaEmbedResponse struct {
+	Embeddings [][]float64 `json:"embeddings"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// Embed returns an embedding vector for each input
+func (c *OllamaClient) Embed(ctx context.Context, model string, inputs []string) ([][]float64, error) {
+	reqBody, err := json.Marshal(ollamaEmbedRequest{Model: model, Input: inputs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/embed", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var embedResp ollamaEmbedResponse
+	if err := json.NewDecoder(c.limitBody(resp.Body)).Decode(&embedResp); err != nil {
+		return nil, fmt.Errorf("failed to decode embeddings: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || embedResp.Error != "" {
+		return nil, fmt.Errorf("ollama API returned status %d: %s", resp.StatusCode, embedResp.Error)
+	}
+	if len(embedResp.Embeddings) != len(inputs) {
+		return nil, fmt.Errorf("ollama returned %d embeddings for %d inputs", len(embedResp.Embeddings), len(inputs))
+	}
+	return embedResp.Embeddings, nil
+}
+
 // Generate sends a prompt to Ollama and returns the response
-func (c *OllamaClient) Generate(ctx context.Context, modelName, prompt string) (*CommandResponse, error) {
+func (c *OllamaClient) Generate(ctx context.Context, genReq GenerateRequest) (*CommandResponse, error) {
+	modelName, prompt := genReq.Model, genReq.Prompt
+
+	system := genReq.System
+	if system == "" {
+		system = c.config.AI.SystemPrompt
+	}
+
 	// Prepare the request
 	ollamaReq := OllamaRequest{
-		Model:  modelName,
-		Prompt: prompt,
-		System: c.config.AI.SystemPrompt,
-		Stream: false,
-		Format: "json",
+		Model:     modelName,
+		Prompt:    prompt,
+		System:    system,
+		Stream:    genReq.OnToken != nil,
+		Think:     genReq.Think,
+		KeepAlive: genReq.KeepAlive,
 		Options: map[string]interface{}{
 			"temperature": c.config.AI.Temperature,
 			"num_predict": c.config.AI.MaxTokens,
 		},
 	}
+	if genReq.JSON {
+		ollamaReq.Format = "json"
+	}
 
 	reqBody, err := json.Marshal(ollamaReq)
 	if err != nil {
@@ -244,18 +358,16 @@ func (c *OllamaClient) Generate(ctx context.Context, modelName, prompt string) (
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("ollama API returned status %d: %s", resp.StatusCode, string(body))
-	}
+	body := c.limitBody(resp.Body)
 
-	var ollamaResp OllamaResponse
-	if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if resp.StatusCode != http.StatusOK {
+		errBody, _ := io.ReadAll(body)
+		return nil, fmt.Errorf("ollama API returned status %d: %s", resp.StatusCode, string(errBody))
 	}
 
-	if ollamaResp.Error != "" {
-		return nil, fmt.Errorf("ollama error: %s", ollamaResp.Error)
+	ollamaResp, err := decodeGenerateResponse(body, genReq.OnToken)
+	if err != nil {
+		return nil, err
 	}
 
 	c.logger.WithFields(logrus.Fields{
@@ -266,12 +378,128 @@ func (c *OllamaClient) Generate(ctx context.Context, modelName, prompt string) (
 		"eval_count":        ollamaResp.EvalCount,
 	}).Info("Received response from Ollama")
 
+	// Reasoning models may inline their thinking instead of using the thinking field
+	text, thinking := StripThinking(ollamaResp.Response)
+	if ollamaResp.Thinking != "" {
+		thinking = strings.TrimSpace(ollamaResp.Thinking + "\n" + thinking)
+	}
+
 	// Parse the JSON response
-	return c.parseOllamaResponse(ollamaResp.Response)
+	cmdResp, err := c.parseOllamaResponse(text, genReq.Mode)
+	if err != nil {
+		return nil, err
+	}
+	cmdResp.Thinking = thinking
+	cmdResp.Trace = &Trace{
+		Provider:    "ollama",
+		Model:       modelName,
+		System:      system,
+		Prompt:      prompt,
+		RawResponse: ollamaResp.Response,
+	}
+	return cmdResp, nil
+}
+
+// ErrResponseTooLarge is returned when an Ollama response exceeds ai.max_response_bytes
+var ErrResponseTooLarge = errors.New("response body exceeds the size limit")
+
+// limitedBody fails reads once more than limit bytes have been read since the last reset
+type limitedBody struct {
+	r     io.Reader
+	limit int64
+	read  int64
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.limit <= 0 {
+		return l.r.Read(p)
+	}
+	if l.read > l.limit {
+		return 0, fmt.Errorf("%w of %d bytes (ai.max_response_bytes)", ErrResponseTooLarge, l.limit)
+	}
+
+	// Read at most one byte past the limit, enough to detect an oversized body
+	if remaining := l.limit + 1 - l.read; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.read > l.limit {
+		return 0, fmt.Errorf("%w of %d bytes (ai.max_response_bytes)", ErrResponseTooLarge, l.limit)
+	}
+	return n, err
+}
+
+// reset starts counting towards the limit again
+func (l *limitedBody) reset() {
+	l.read = 0
+}
+
+// limitBody guards a response body against exhausting memory. A limit of 0 disables the guard.
+func (c *OllamaClient) limitBody(body io.Reader) *limitedBody {
+	return &limitedBody{r: body, limit: c.config.AI.MaxResponseBytes}
+}
+
+// decodeGenerateResponse reads a generate response body. Ollama normally sends a
+// single object when streaming is off, but some proxies and versions still send
+// newline-delimited chunks, so every chunk is read and the Response fields are
+// concatenated until a chunk reports done or the body ends. The same reader
+// handles streamed responses, passing each chunk to onToken when set. The
+// returned response carries the metadata of the last chunk.
+func decodeGenerateResponse(body io.Reader, onToken func(string)) (*OllamaResponse, error) {
+	decoder := json.NewDecoder(body)
+
+	var result OllamaResponse
+	var text, thinking strings.Builder
+	chunks := 0
+
+	for {
+		var chunk OllamaResponse
+		if err := decoder.Decode(&chunk); err != nil {
+			if err == io.EOF && chunks > 0 {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		chunks++
+
+		if chunk.Error != "" {
+			return nil, fmt.Errorf("ollama error: %s", chunk.Error)
+		}
+
+		text.WriteString(chunk.Response)
+		thinking.WriteString(chunk.Thinking)
+		result = chunk
+
+		if onToken != nil && chunk.Response != "" {
+			onToken(chunk.Response)
+		}
+
+		if chunk.Done {
+			break
+		}
+	}
+
+	result.Response = text.String()
+	result.Thinking = thinking.String()
+	return &result, nil
 }
 
 // parseOllamaResponse parses the JSON response from Ollama into CommandResponse
-func (c *OllamaClient) parseOllamaResponse(response string) (*CommandResponse, error) {
+func (c *OllamaClient) parseOllamaResponse(response string, mode Mode) (*CommandResponse, error) {
+	if mode == ModeExplain || mode == ModeReview {
+		return c.parseAnalysisResponse(response, mode), nil
+	}
+
+	cmdResp, err := c.parseCommandResponse(response)
+	if err == nil && mode == ModeFunction {
+		cmdResp.Mode = ModeFunction
+	}
+	return cmdResp, err
+}
+
+// parseCommandResponse parses a response whose "command" field holds the result
+func (c *OllamaClient) parseCommandResponse(response string) (*CommandResponse, error) {
 	// Clean the response - sometimes Ollama adds extra text
 	response = strings.TrimSpace(response)
 
@@ -293,7 +521,7 @@ func (c *OllamaClient) parseOllamaResponse(response string) (*CommandResponse, e
 		return c.fallbackParseResponse(response), nil
 	}
 
-	cmdResp := &CommandResponse{}
+	cmdResp := &CommandResponse{Mode: ModeShell}
 
 	// Extract fields with type assertions
 	if cmd, ok := result["command"].(string); ok {
@@ -308,6 +536,10 @@ func (c *OllamaClient) parseOllamaResponse(response string) (*CommandResponse, e
 		cmdResp.Warning = warning
 	}
 
+	if clarification, ok := result["clarification"].(string); ok {
+		cmdResp.Clarification = strings.TrimSpace(clarification)
+	}
+
 	if confidence, ok := result["confidence"].(float64); ok {
 		cmdResp.Confidence = confidence
 	} else {
@@ -316,20 +548,97 @@ func (c *OllamaClient) parseOllamaResponse(response string) (*CommandResponse, e
 
 	if alternatives, ok := result["alternatives"].([]interface{}); ok {
 		for _, alt := range alternatives {
-			if altStr, ok := alt.(string); ok {
-				cmdResp.Alternatives = append(cmdResp.Alternatives, altStr)
+			switch alt := alt.(type) {
+			case string:
+				cmdResp.Alternatives = append(cmdResp.Alternatives, alt)
+			case map[string]interface{}:
+				// Alternatives may also be {"command": ..., "confidence": ...}
+				altCmd, ok := alt["command"].(string)
+				if !ok || strings.TrimSpace(altCmd) == "" {
+					continue
+				}
+				altCmd = strings.TrimSpace(altCmd)
+				cmdResp.Alternatives = append(cmdResp.Alternatives, altCmd)
+				if confidence, ok := alt["confidence"].(float64); ok {
+					if cmdResp.alternativeConfidence == nil {
+						cmdResp.alternativeConfidence = map[string]float64{}
+					}
+					cmdResp.alternativeConfidence[altCmd] = confidence
+				}
 			}
 		}
 	}
 
+	cmdResp.Steps = parseSteps(result["steps"])
+
+	// A multi-step answer may only list its steps
+	if cmdResp.Command == "" && len(cmdResp.Steps) > 0 {
+		commands := make([]string, len(cmdResp.Steps))
+		for i, step := range cmdResp.Steps {
+			commands[i] = step.Command
+		}
+		cmdResp.Command = strings.Join(commands, " && ")
+	}
+
 	// Validate the response
-	if cmdResp.Command == "" && cmdResp.Warning == "" {
+	if cmdResp.Command == "" && cmdResp.Warning == "" && cmdResp.Clarification == "" {
 		return c.fallbackParseResponse(response), nil
 	}
 
 	return cmdResp, nil
 }
 
+// parseAnalysisResponse parses explain and review mode responses, which describe
+// an existing command rather than producing a new one
+func (c *OllamaClient) parseAnalysisResponse(response string, mode Mode) *CommandResponse {
+	response = strings.TrimSpace(response)
+	cmdResp := &CommandResponse{Mode: mode, Confidence: 0.8}
+
+	var result map[string]interface{}
+	startIdx := strings.Index(response, "{")
+	endIdx := strings.LastIndex(response, "}")
+	if startIdx == -1 || endIdx == -1 || json.Unmarshal([]byte(response[startIdx:endIdx+1]), &result) != nil {
+		// Without JSON the whole response is the best explanation we have
+		c.logger.Warn("Failed to parse JSON analysis response, using raw text")
+		cmdResp.Explanation = response
+		cmdResp.Confidence = 0.3
+		cmdResp.Fallback = true
+		return cmdResp
+	}
+
+	if explanation, ok := result["explanation"].(string); ok {
+		cmdResp.Explanation = explanation
+	}
+
+	if summary, ok := result["summary"].(string); ok && cmdResp.Explanation == "" {
+		cmdResp.Explanation = summary
+	}
+
+	if warning, ok := result["warning"].(string); ok {
+		cmdResp.Warning = warning
+	}
+
+	if risk, ok := result["risk"].(string); ok {
+		cmdResp.Risk = strings.ToLower(strings.TrimSpace(risk))
+	}
+
+	if confidence, ok := result["confidence"].(float64); ok {
+		cmdResp.Confidence = confidence
+	}
+
+	if issues, ok := result["issues"].([]interface{}); ok {
+		for _, issue := range issues {
+			if issueStr, ok := issue.(string); ok {
+				cmdResp.Issues = append(cmdResp.Issues, issueStr)
+			}
+		}
+	}
+
+	cmdResp.Flags = parseFlags(result["flags"])
+
+	return cmdResp
+}
+
 // fallbackParseResponse provides a fallback when JSON parsing fails
 func (c *OllamaClient) fallbackParseResponse(response string) *CommandResponse {
 	// Simple heuristic to extract command from text
@@ -369,10 +678,12 @@ func (c *OllamaClient) fallbackParseResponse(response string) *CommandResponse {
 	}
 
 	return &CommandResponse{
+		Mode:        ModeShell,
 		Command:     command,
 		Explanation: "AI response was not in expected format, extracted command using fallback method",
 		Warning:     "Please verify this command before executing",
 		Confidence:  0.3,
+		Fallback:    true,
 	}
 }
 
@@ -0,0 +1,164 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/config"
+	"github.com/kodelint/shell-agent/internal/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// OpenAIAPIKeyEnv is read for the API key when ai.openai.api_key is not set
+const OpenAIAPIKeyEnv = "OPENAI_API_KEY"
+
+// openAICapabilities are the features shell-agent uses from a generic
+// OpenAI-compatible chat completions endpoint
+var openAICapabilities = NewCapabilities(CapabilityStructuredOutput)
+
+// OpenAIClient generates commands through an OpenAI-compatible chat completions API
+type OpenAIClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+	config  *config.Config
+	// parser reads responses, which follow the same JSON contract as Ollama's
+	parser *OllamaClient
+	logger *logrus.Entry
+}
+
+// openAIMessage is a single chat message
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// openAIResponseFormat asks for a JSON object response
+type openAIResponseFormat struct {
+	Type string `json:"type"`
+}
+
+// openAIRequest is the payload of /chat/completions
+type openAIRequest struct {
+	Model          string                `json:"model"`
+	Messages       []openAIMessage       `json:"messages"`
+	Temperature    float64               `json:"temperature"`
+	MaxTokens      int                   `json:"max_tokens,omitempty"`
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+}
+
+// openAIResponse is the response of /chat/completions
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// NewOpenAIClient creates a client for the ai.openai endpoint
+func NewOpenAIClient(cfg *config.Config) *OpenAIClient {
+	apiKey := cfg.AI.OpenAI.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv(OpenAIAPIKeyEnv)
+	}
+
+	return &OpenAIClient{
+		baseURL: strings.TrimRight(cfg.AI.OpenAI.BaseURL, "/"),
+		apiKey:  apiKey,
+		client: &http.Client{
+			Timeout: time.Duration(cfg.AI.Timeout) * time.Second,
+		},
+		config: cfg,
+		parser: NewOllamaClient(cfg),
+		logger: logger.GetLogger().WithField("component", "openai-client"),
+	}
+}
+
+// Capabilities reports that only structured output is supported: there is no
+// streaming, thinking, keep-alive, local model management or embeddings
+func (c *OpenAIClient) Capabilities() Capabilities {
+	return openAICapabilities
+}
+
+// Generate sends a prompt to the chat completions endpoint and returns the response
+func (c *OpenAIClient) Generate(ctx context.Context, genReq GenerateRequest) (*CommandResponse, error) {
+	system := genReq.System
+	if system == "" {
+		system = c.config.AI.SystemPrompt
+	}
+
+	openAIReq := openAIRequest{
+		Model: genReq.Model,
+		Messages: []openAIMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: genReq.Prompt},
+		},
+		Temperature: c.config.AI.Temperature,
+		MaxTokens:   c.config.AI.MaxTokens,
+	}
+	if genReq.JSON {
+		openAIReq.ResponseFormat = &openAIResponseFormat{Type: "json_object"}
+	}
+
+	reqBody, err := json.Marshal(openAIReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	c.logger.WithField("model", genReq.Model).Info("Sending request to OpenAI")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to openai: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(c.parser.limitBody(resp.Body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var openAIResp openAIResponse
+	if err := json.Unmarshal(body, &openAIResp); err != nil || resp.StatusCode != http.StatusOK {
+		if openAIResp.Error != nil {
+			return nil, fmt.Errorf("openai API returned status %d: %s", resp.StatusCode, openAIResp.Error.Message)
+		}
+		return nil, fmt.Errorf("openai API returned status %d: %s", resp.StatusCode, string(body))
+	}
+	if len(openAIResp.Choices) == 0 {
+		return nil, fmt.Errorf("openai API returned no choices")
+	}
+
+	text, thinking := StripThinking(openAIResp.Choices[0].Message.Content)
+	cmdResp, err := c.parser.parseOllamaResponse(text, genReq.Mode)
+	if err != nil {
+		return nil, err
+	}
+	cmdResp.Thinking = thinking
+	cmdResp.Trace = &Trace{
+		Provider:    "openai",
+		Model:       genReq.Model,
+		System:      system,
+		Prompt:      genReq.Prompt,
+		RawResponse: openAIResp.Choices[0].Message.Content,
+	}
+	return cmdResp, nil
+}
@@ -0,0 +1,141 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kodelint/shell-agent/internal/shellwords"
+)
+
+// RuleMissingPath flags relative paths that don't exist in the working directory
+const RuleMissingPath = "missing-path"
+
+// pathOperandCommands take existing files or directories as operands
+var pathOperandCommands = map[string]bool{
+	"cd": true, "rm": true, "rmdir": true, "ls": true, "cat": true, "less": true, "more": true,
+	"head": true, "tail": true, "du": true, "wc": true, "stat": true, "file": true, "source": true,
+	"chmod": true, "chown": true, "chgrp": true, "cp": true, "mv": true, "ln": true, "find": true,
+}
+
+// commandSeparators start a new simple command
+var commandSeparators = map[string]bool{"|": true, "||": true, "&&": true, ";": true, "&": true}
+
+// notAPath matches operands that are patterns, variables, URLs or numbers rather than literal paths
+var notAPath = regexp.MustCompile(`[*?\[\]{}$~<>=]|://|^\d+$`)
+
+// MissingPaths returns the relative paths a command operates on that don't exist.
+// Paths are resolved against workDir, following any cd in the command. It only
+// looks at operands of common file commands, so it is a heuristic, not a parser.
+func MissingPaths(command, workDir string) []string {
+	words, err := shellwords.SplitCommand(command)
+	if err != nil {
+		return nil
+	}
+
+	var missing []string
+	for _, simple := range splitSimpleCommands(words) {
+		if len(simple) == 0 {
+			continue
+		}
+
+		name := filepath.Base(simple[0])
+		if !pathOperandCommands[name] {
+			continue
+		}
+
+		operands := pathOperands(name, simple[1:])
+
+		if name == "cd" {
+			// Follow the directory change; stop when the new directory is unknown
+			if len(operands) != 1 {
+				return missing
+			}
+			target := operands[0]
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(workDir, target)
+			}
+			if _, err := os.Stat(target); err != nil {
+				return append(missing, operands[0])
+			}
+			workDir = target
+			continue
+		}
+
+		for _, operand := range operands {
+			if filepath.IsAbs(operand) {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(workDir, operand)); os.IsNotExist(err) {
+				missing = append(missing, operand)
+			}
+		}
+	}
+
+	return missing
+}
+
+// splitSimpleCommands splits words at command separators, including a
+// separator attached to the end of a word such as "build;"
+func splitSimpleCommands(words []string) [][]string {
+	var commands [][]string
+	var current []string
+
+	for _, word := range words {
+		if commandSeparators[word] {
+			commands = append(commands, current)
+			current = nil
+			continue
+		}
+		if trimmed := strings.TrimSuffix(word, ";"); trimmed != word && trimmed != "" {
+			commands = append(commands, append(current, trimmed))
+			current = nil
+			continue
+		}
+		current = append(current, word)
+	}
+
+	return append(commands, current)
+}
+
+// pathOperands picks the arguments of a file command that must already exist
+func pathOperands(name string, args []string) []string {
+	var operands []string
+	afterDashDash := false
+	skipNext := false
+
+	for _, arg := range args {
+		switch {
+		case skipNext:
+			skipNext = false
+		case arg == "--" && !afterDashDash:
+			afterDashDash = true
+		case strings.HasPrefix(arg, "-") && !afterDashDash:
+			// find's expression starts at the first option; nothing after it is a start path
+			if name == "find" {
+				return operands
+			}
+		case arg == ">" || arg == ">>" || arg == "<" || arg == "2>":
+			skipNext = true
+		case notAPath.MatchString(arg):
+		default:
+			operands = append(operands, arg)
+		}
+	}
+
+	switch name {
+	case "chmod", "chown", "chgrp":
+		// The first operand is the mode or owner
+		if len(operands) > 0 {
+			operands = operands[1:]
+		}
+	case "cp", "mv", "ln":
+		// The destination may not exist yet
+		if len(operands) > 1 {
+			operands = operands[:len(operands)-1]
+		}
+	}
+
+	return operands
+}
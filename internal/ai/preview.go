@@ -0,0 +1,229 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/shellwords"
+)
+
+// ErrNoPreview is returned by PreviewDestructive for commands it cannot simulate
+var ErrNoPreview = errors.New("no read-only preview for this command")
+
+// previewTimeout bounds the read-only find run by a preview
+const previewTimeout = 10 * time.Second
+
+// findActions are find primaries that act on files or write output files; a
+// find using any of them is never run for a preview
+var findActions = map[string]bool{
+	"-exec": true, "-execdir": true, "-ok": true, "-okdir": true,
+	"-fprint": true, "-fprint0": true, "-fprintf": true, "-fls": true,
+}
+
+// PreviewDestructive derives a read-only equivalent of an rm, mv or find -delete
+// command and returns it along with the paths the command would affect, relative
+// to the working directory. Nothing is modified: rm and mv targets are resolved
+// in-process, and find only runs once -delete is replaced with -print.
+// Pipelines, command lists and other commands return ErrNoPreview.
+func PreviewDestructive(command string) (string, []string, error) {
+	words, err := shellwords.SplitCommand(command)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrNoPreview, err)
+	}
+	for _, word := range words {
+		if commandSeparators[word] || strings.HasSuffix(word, ";") || strings.ContainsAny(word, "`<>") || strings.Contains(word, "$(") {
+			return "", nil, ErrNoPreview
+		}
+	}
+
+	// The preview needs no privileges, and must not ask for them
+	if len(words) > 0 && words[0] == "sudo" {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return "", nil, ErrNoPreview
+	}
+
+	switch filepath.Base(words[0]) {
+	case "rm":
+		return previewRemove(words[1:])
+	case "mv":
+		return previewMove(words[1:])
+	case "find":
+		return previewFind(words[1:])
+	}
+	return "", nil, ErrNoPreview
+}
+
+// previewRemove lists what rm would remove, including directory contents when recursive
+func previewRemove(args []string) (string, []string, error) {
+	flags, operands := splitOperands(args)
+	recursive := false
+	for _, flag := range flags {
+		if flag == "--recursive" || (!strings.HasPrefix(flag, "--") && strings.ContainsAny(flag, "rR")) {
+			recursive = true
+		}
+	}
+	if len(operands) == 0 {
+		return "", nil, ErrNoPreview
+	}
+
+	targets := expandOperands(operands)
+	if !recursive {
+		return quoteCommand(append([]string{"ls", "-d", "--"}, operands...), true), targets, nil
+	}
+
+	var matched []string
+	for _, target := range targets {
+		filepath.WalkDir(target, func(path string, d fs.DirEntry, err error) error {
+			if err == nil {
+				matched = append(matched, path)
+			}
+			return nil
+		})
+	}
+	return quoteCommand(append([]string{"find"}, operands...), true), matched, nil
+}
+
+// previewMove lists the sources mv would move
+func previewMove(args []string) (string, []string, error) {
+	flags, operands := splitOperands(args)
+
+	sources := operands
+	targetDir, targetOperand := false, false
+	for _, flag := range flags {
+		switch {
+		case flag == "--target-directory":
+			targetDir, targetOperand = true, true
+		case strings.HasPrefix(flag, "--target-directory="):
+			targetDir = true
+		case !strings.HasPrefix(flag, "--") && strings.Contains(flag, "t"):
+			// -t DIR takes the next word, -tDIR and -vtDIR carry it along
+			targetDir = true
+			targetOperand = strings.HasSuffix(flag, "t")
+		}
+	}
+	switch {
+	case !targetDir:
+		// Without -t the last operand is the destination
+		if len(operands) < 2 {
+			return "", nil, ErrNoPreview
+		}
+		sources = operands[:len(operands)-1]
+	case targetOperand && len(operands) > 0:
+		// -t DIR: splitOperands took DIR as the first operand
+		sources = operands[1:]
+	}
+	if len(sources) == 0 {
+		return "", nil, ErrNoPreview
+	}
+
+	return quoteCommand(append([]string{"ls", "-d", "--"}, sources...), true), expandOperands(sources), nil
+}
+
+// previewFind runs find with -delete replaced by -print
+func previewFind(args []string) (string, []string, error) {
+	previewArgs := make([]string, 0, len(args))
+	deletes := false
+	for _, arg := range args {
+		if findActions[arg] {
+			return "", nil, ErrNoPreview
+		}
+		if arg == "-delete" {
+			arg = "-print"
+			deletes = true
+		}
+		previewArgs = append(previewArgs, arg)
+	}
+	if !deletes {
+		return "", nil, ErrNoPreview
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), previewTimeout)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, "find", previewArgs...)
+	cmd.Stdout = &stdout
+	// find reports unreadable directories but still lists what it can
+	runErr := cmd.Run()
+	if _, ok := runErr.(*exec.ExitError); runErr != nil && !ok {
+		return "", nil, fmt.Errorf("failed to run preview: %w", runErr)
+	}
+
+	var matched []string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if line != "" {
+			matched = append(matched, line)
+		}
+	}
+	return quoteCommand(append([]string{"find"}, previewArgs...), false), matched, nil
+}
+
+// splitOperands separates leading-dash flags from operands; everything after
+// -- is an operand. The argument of -t is returned as an operand.
+func splitOperands(args []string) (flags, operands []string) {
+	afterDashDash := false
+	for _, arg := range args {
+		switch {
+		case afterDashDash:
+			operands = append(operands, arg)
+		case arg == "--":
+			afterDashDash = true
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			flags = append(flags, arg)
+		default:
+			operands = append(operands, arg)
+		}
+	}
+	return flags, operands
+}
+
+// expandOperands resolves ~ and glob patterns to the existing paths they name
+func expandOperands(operands []string) []string {
+	var paths []string
+	for _, operand := range operands {
+		if operand == "~" || strings.HasPrefix(operand, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				operand = filepath.Join(home, strings.TrimPrefix(operand, "~"))
+			}
+		}
+
+		if strings.ContainsAny(operand, "*?[") {
+			matches, _ := filepath.Glob(operand)
+			paths = append(paths, matches...)
+			continue
+		}
+		if _, err := os.Lstat(operand); err == nil {
+			paths = append(paths, operand)
+		}
+	}
+	return paths
+}
+
+// globChars strips glob metacharacters, leaving the rest of a pattern to check
+var globChars = strings.NewReplacer("*", "", "?", "", "[", "", "]", "")
+
+// quoteCommand joins words into a command line that splits back into the same
+// words. With keepGlobs, glob patterns stay unquoted so the shell expands them
+// as it would have for the original command.
+func quoteCommand(words []string, keepGlobs bool) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = shellwords.Quote(word)
+		if keepGlobs && strings.ContainsAny(word, "*?[") {
+			if rest := globChars.Replace(word); rest == "" || shellwords.Quote(rest) == rest {
+				quoted[i] = word
+			}
+		}
+	}
+	return strings.Join(quoted, " ")
+}
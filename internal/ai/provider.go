@@ -0,0 +1,27 @@
+package ai
+
+import "context"
+
+// GenerateRequest describes a single generation call to a provider
+type GenerateRequest struct {
+	Model  string
+	Prompt string
+	System string
+	Mode   Mode
+	// OnToken, when set, asks the provider to stream and receives each piece of output
+	OnToken func(token string)
+	// JSON constrains the response to JSON (CapabilityStructuredOutput)
+	JSON bool
+	// Think asks reasoning models for their thinking separately (CapabilityThinking)
+	Think bool
+	// KeepAlive is how long the model stays loaded, e.g. "10m"; "" leaves the provider default (CapabilityKeepAlive)
+	KeepAlive string
+}
+
+// Provider generates a command response for a request
+type Provider interface {
+	Generate(ctx context.Context, req GenerateRequest) (*CommandResponse, error)
+	// Capabilities reports which optional features the provider supports.
+	// Requests are stripped of options outside this set before Generate.
+	Capabilities() Capabilities
+}
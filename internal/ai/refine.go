@@ -0,0 +1,56 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxRefinementWords is the longest follow-up still treated as a refinement
+const maxRefinementWords = 8
+
+// refinementPrefixes start follow-ups that modify the previous command rather
+// than ask for a new one. Words that also start many new requests, such as
+// "now", "with" or "only", are left out.
+var refinementPrefixes = []string{
+	"same but", "but", "except", "excluding", "exclude", "without",
+	"instead", "make it",
+}
+
+// IsRefinement reports whether input reads as a short modifier of the previous
+// command, e.g. "same but exclude node_modules"
+func IsRefinement(input string) bool {
+	words := strings.Fields(strings.ToLower(input))
+	if len(words) == 0 || len(words) > maxRefinementWords {
+		return false
+	}
+
+	normalized := strings.Join(words, " ")
+	for _, prefix := range refinementPrefixes {
+		if normalized == prefix || strings.HasPrefix(normalized, prefix+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// AppendRefinement adds the previous command and the constraints given so far
+// to the original request, so the model revises that command instead of
+// starting over. Chained refinements add constraints rather than nesting.
+func AppendRefinement(request, command string, constraints []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\nPrevious command: %s\nRefinements:\n", request, command)
+	for _, constraint := range constraints {
+		fmt.Fprintf(&b, "- %s\n", constraint)
+	}
+	b.WriteString("Revise the previous command so it also satisfies every refinement.")
+	return b.String()
+}
+
+// DescribeRefinement is the request with its refinements as the user would
+// read it, for history and feedback
+func DescribeRefinement(request string, constraints []string) string {
+	if len(constraints) == 0 {
+		return request
+	}
+	return fmt.Sprintf("%s (%s)", request, strings.Join(constraints, "; "))
+}
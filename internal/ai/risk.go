@@ -0,0 +1,133 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kodelint/shell-agent/internal/config"
+	"github.com/kodelint/shell-agent/internal/shellwords"
+)
+
+// maxRiskScore is the ceiling of the risk score
+const maxRiskScore = 100
+
+// Risk factor names and their contribution to the score
+const (
+	RiskSudo              = "sudo"
+	RiskRecursive         = "recursive"
+	RiskDestructive       = "destructive verb"
+	RiskDangerousPattern  = "dangerous pattern"
+	RiskSecurityWeakening = "security weakening"
+	RiskNetwork           = "network access"
+	RiskWildcard          = "wildcard target"
+	RiskDataDir           = "touches data dir"
+)
+
+var riskWeights = map[string]int{
+	RiskSudo:              20,
+	RiskRecursive:         20,
+	RiskDestructive:       25,
+	RiskDangerousPattern:  30,
+	RiskSecurityWeakening: 30,
+	RiskNetwork:           10,
+	RiskWildcard:          15,
+	RiskDataDir:           15,
+}
+
+// commandPrefixes run the word that follows them as a command
+var commandPrefixes = map[string]bool{"sudo": true, "xargs": true, "exec": true, "nohup": true}
+
+// RiskFactor is one signal that contributed to a command's risk score
+type RiskFactor struct {
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+}
+
+// ScoreRisk combines the safety findings and the shape of a command into a
+// score from 0 to 100, returning the factors that contributed to it
+func ScoreRisk(command string, findings []Finding) (int, []RiskFactor) {
+	seen := make(map[string]bool)
+	var factors []RiskFactor
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			factors = append(factors, RiskFactor{Name: name, Weight: riskWeights[name]})
+		}
+	}
+
+	for _, finding := range findings {
+		switch finding.Rule {
+		case RuleSudo:
+			add(RiskSudo)
+		case RuleRecursive:
+			add(RiskRecursive)
+		case RuleDangerousPattern:
+			add(RiskDangerousPattern)
+		case RuleSecurityWeakening:
+			add(RiskSecurityWeakening)
+		}
+	}
+
+	words, err := shellwords.SplitCommand(command)
+	if err != nil {
+		words = strings.Fields(command)
+	}
+
+	dataDir := filepath.Clean(config.GetDataDir())
+	destructive := false
+	for i, word := range words {
+		class := classifyProgram(word)
+		atStart := i == 0 || isCommandSeparator(words[i-1]) || commandPrefixes[words[i-1]]
+
+		switch {
+		case isCommandSeparator(word):
+			destructive = false
+		case atStart && class.destructive:
+			destructive = true
+			add(RiskDestructive)
+		case atStart && class.category == CategoryNetwork:
+			add(RiskNetwork)
+		case destructive && strings.ContainsAny(word, "*?"):
+			add(RiskWildcard)
+		}
+
+		if touchesDir(word, dataDir) {
+			add(RiskDataDir)
+		}
+	}
+
+	score := 0
+	for _, factor := range factors {
+		score += factor.Weight
+	}
+	if score > maxRiskScore {
+		score = maxRiskScore
+	}
+	return score, factors
+}
+
+// NeedsRiskConfirmation reports whether the risk score is above threshold; a
+// threshold of 0 disables the check
+func (r *CommandResponse) NeedsRiskConfirmation(threshold int) bool {
+	return threshold > 0 && r.RiskScore > threshold
+}
+
+// isCommandSeparator reports whether a word starts a new simple command
+func isCommandSeparator(word string) bool {
+	return commandSeparators[word]
+}
+
+// touchesDir reports whether word is dir or a path inside it
+func touchesDir(word, dir string) bool {
+	if strings.HasPrefix(word, "~") {
+		if home, err := os.UserHomeDir(); err == nil {
+			word = filepath.Join(home, word[1:])
+		}
+	}
+	if !filepath.IsAbs(word) {
+		return false
+	}
+	word = filepath.Clean(word)
+	return word == dir || strings.HasPrefix(word, dir+string(filepath.Separator))
+}
@@ -0,0 +1,219 @@
+package ai
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kodelint/shell-agent/internal/config"
+	"github.com/kodelint/shell-agent/internal/logger"
+	"github.com/kodelint/shell-agent/internal/shellwords"
+	"github.com/sirupsen/logrus"
+)
+
+// Severity ranks how serious a safety finding is
+type Severity string
+
+const (
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Finding is a single safety issue detected in a command
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Pattern  string   `json:"pattern,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// Rule names for findings
+const (
+	RuleDangerousPattern  = "dangerous-pattern"
+	RuleSecurityWeakening = "security-weakening"
+	RuleSudo              = "sudo"
+	RuleRecursive         = "recursive"
+)
+
+// defaultSecurityWeakeningRules flag commands that switch off safety mechanisms.
+// Rules from safety.security_weakening are added to these.
+var defaultSecurityWeakeningRules = []config.SecurityRule{
+	{Pattern: `\bset\s+\+e\b`, Message: "disables exit-on-error, so failures go unnoticed"},
+	{Pattern: `\bset\s+\+o\s+(errexit|pipefail|nounset)\b`, Message: "disables shell error checking"},
+	{Pattern: `\bchmod\s+(\S+\s+)*(a?-x|0?[0-6][0-7]{2})\s+(\S+\s+)*/(usr/)?(s?bin|lib)/`, Message: "removes execute permission from system binaries"},
+	{Pattern: `\biptables\s+(-\w+\s+)*(-F|--flush)\b`, Message: "flushes all firewall rules"},
+	{Pattern: `\bufw\s+disable\b`, Message: "disables the firewall"},
+	{Pattern: `\bsystemctl\s+(stop|disable|mask)\s+(firewalld|ufw|apparmor|auditd)\b`, Message: "stops a security service"},
+	{Pattern: `\bsetenforce\s+(0|permissive)\b`, Message: "puts SELinux into permissive mode"},
+	{Pattern: `SELINUX\s*=\s*disabled`, Message: "disables SELinux"},
+	{Pattern: `\bcsrutil\s+disable\b`, Message: "disables macOS System Integrity Protection"},
+	{Pattern: `\bspctl\s+--master-disable\b`, Message: "disables macOS Gatekeeper"},
+	{Pattern: `\bunset\s+HISTFILE\b|\bhistory\s+-c\b`, Message: "erases shell history, hiding what was run"},
+	{Pattern: `Set-ExecutionPolicy\s+(Unrestricted|Bypass)`, Message: "disables the PowerShell execution policy"},
+}
+
+// securityRule is a compiled security-weakening rule
+type securityRule struct {
+	pattern *regexp.Regexp
+	message string
+}
+
+// SafetyChecker validates commands for safety
+type SafetyChecker struct {
+	dangerousPatterns []string
+	securityRules     []securityRule
+	config            *config.Config
+	logger            *logrus.Entry
+}
+
+func NewSafetyChecker(cfg *config.Config) *SafetyChecker {
+	checker := &SafetyChecker{
+		dangerousPatterns: cfg.Safety.DangerousCommands,
+		config:            cfg,
+		logger:            logger.GetLogger().WithField("component", "safety-checker"),
+	}
+
+	rules := append(append([]config.SecurityRule{}, defaultSecurityWeakeningRules...), cfg.Safety.SecurityWeakening...)
+	for _, rule := range rules {
+		pattern, err := regexp.Compile("(?i)" + rule.Pattern)
+		if err != nil {
+			checker.logger.WithError(err).WithField("pattern", rule.Pattern).Warn("Ignoring invalid security-weakening pattern")
+			continue
+		}
+		checker.securityRules = append(checker.securityRules, securityRule{pattern: pattern, message: rule.Message})
+	}
+
+	return checker
+}
+
+// CheckCommand records safety findings on the response and adds them to its warning
+func (s *SafetyChecker) CheckCommand(response *CommandResponse) {
+	if response.Command == "" {
+		return
+	}
+
+	findings := s.Findings(response.Command)
+	for _, finding := range findings {
+		// Lower confidence for critical commands
+		if finding.Severity == SeverityCritical && response.Confidence > 0.5 {
+			response.Confidence = 0.5
+		}
+
+		// Don't repeat a privilege warning the model already gave
+		if finding.Rule == RuleSudo && strings.Contains(response.Warning, "sudo") {
+			continue
+		}
+
+		appendWarning(response, finding.Message)
+	}
+
+	response.Findings = append(response.Findings, findings...)
+	response.RiskScore, response.RiskFactors = ScoreRisk(response.Command, response.Findings)
+}
+
+// Findings runs all safety rules against a command
+func (s *SafetyChecker) Findings(command string) []Finding {
+	var findings []Finding
+	lower := strings.ToLower(command)
+
+	// Check for dangerous patterns
+	for _, pattern := range s.dangerousPatterns {
+		if strings.Contains(lower, strings.ToLower(pattern)) {
+			findings = append(findings, Finding{
+				Rule:     RuleDangerousPattern,
+				Severity: SeverityCritical,
+				Pattern:  pattern,
+				Message:  fmt.Sprintf("⚠️ DANGER: This command contains '%s' which can be destructive", pattern),
+			})
+
+			s.logger.WithFields(logrus.Fields{
+				"command": command,
+				"pattern": pattern,
+			}).Warn("Dangerous command pattern detected")
+			break
+		}
+	}
+
+	// Check for commands that switch off safety mechanisms
+	for _, rule := range s.securityRules {
+		if match := rule.pattern.FindString(command); match != "" {
+			findings = append(findings, Finding{
+				Rule:     RuleSecurityWeakening,
+				Severity: SeverityCritical,
+				Pattern:  match,
+				Message:  fmt.Sprintf("🛡️ SECURITY: '%s' %s", match, rule.message),
+			})
+
+			s.logger.WithFields(logrus.Fields{
+				"command": command,
+				"match":   match,
+			}).Warn("Security-weakening command detected")
+		}
+	}
+
+	// Additional safety checks
+	if strings.Contains(lower, "sudo") {
+		findings = append(findings, Finding{
+			Rule:     RuleSudo,
+			Severity: SeverityWarning,
+			Pattern:  "sudo",
+			Message:  "⚠️ This command requires administrative privileges",
+		})
+	}
+
+	// Check for recursive operations
+	if isRecursive(command) {
+		findings = append(findings, Finding{
+			Rule:     RuleRecursive,
+			Severity: SeverityWarning,
+			Message:  "⚠️ This command will operate recursively on directories",
+		})
+	}
+
+	return findings
+}
+
+// recursiveFlags maps the programs whose recursive flag is worth a warning to
+// the short flags that mean recursion for them. Only -R does for chmod, where
+// -r removes read permission.
+var recursiveFlags = map[string]string{"rm": "rR", "cp": "rR", "chmod": "R", "chown": "R", "chgrp": "R"}
+
+// isRecursive reports whether the command runs rm, cp, chmod, chown or chgrp
+// with a recursive flag. Commands that cannot be tokenized fall back to
+// substring matching.
+func isRecursive(command string) bool {
+	words, err := shellwords.SplitCommand(command)
+	if err != nil {
+		lower := strings.ToLower(command)
+		return strings.Contains(lower, "-r") && (strings.Contains(lower, "rm") || strings.Contains(lower, "chmod") || strings.Contains(lower, "chown"))
+	}
+
+	flags := ""
+	for _, word := range words {
+		if letters, ok := recursiveFlags[filepath.Base(word)]; ok {
+			flags = letters
+			continue
+		}
+		switch {
+		case isCommandSeparator(word):
+			flags = ""
+		case flags == "":
+		case word == "--recursive":
+			return true
+		case strings.HasPrefix(word, "-") && !strings.HasPrefix(word, "--") && strings.ContainsAny(word, flags):
+			return true
+		}
+	}
+	return false
+}
+
+// HasCritical reports whether any finding is critical
+func (r *CommandResponse) HasCritical() bool {
+	for _, finding := range r.Findings {
+		if finding.Severity == SeverityCritical {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package ai
+
+import "strings"
+
+// Step is one command of a multi-step answer
+type Step struct {
+	Command     string `json:"command"`
+	Explanation string `json:"explanation,omitempty"`
+}
+
+// ScriptSteps returns the steps of the response: the steps the model listed, or
+// else the command as a single step. A command is never split at && or ;,
+// since its parts may depend on each other, e.g. test -d x && echo yes.
+func (r *CommandResponse) ScriptSteps() []Step {
+	if len(r.Steps) > 0 {
+		return r.Steps
+	}
+	if r.Command == "" {
+		return nil
+	}
+	return []Step{{Command: r.Command, Explanation: r.Explanation}}
+}
+
+// parseSteps reads the optional "steps" field, a list of commands or of
+// {"command": ..., "explanation": ...} objects
+func parseSteps(value interface{}) []Step {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var steps []Step
+	for _, item := range items {
+		var step Step
+		switch item := item.(type) {
+		case string:
+			step.Command = strings.TrimSpace(item)
+		case map[string]interface{}:
+			command, _ := item["command"].(string)
+			explanation, _ := item["explanation"].(string)
+			step = Step{Command: strings.TrimSpace(command), Explanation: strings.TrimSpace(explanation)}
+		}
+		if step.Command != "" {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
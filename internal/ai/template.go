@@ -0,0 +1,100 @@
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// placeholderPattern matches a {{name}} placeholder in a templated command
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_-]*)\s*\}\}`)
+
+// templateInstruction asks the model for placeholders instead of guessed values
+const templateInstruction = "%d. Write each value the user is likely to vary (file names, patterns, hosts, ports) as a placeholder such as {{filename}} instead of guessing it\n"
+
+// SetTemplate asks for commands with {{name}} placeholders for the values the
+// user will vary; they are filled in before execution with FillTemplate
+func (c *Client) SetTemplate(enabled bool) {
+	c.template = enabled
+}
+
+// Placeholders returns the names of the {{name}} placeholders in command, in
+// order of first appearance and without duplicates
+func Placeholders(command string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, match := range placeholderPattern.FindAllStringSubmatch(command, -1) {
+		if name := match[1]; !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// CheckPlaceholderValue reports whether value can fill a placeholder. Commands
+// run without a shell and are split at whitespace, so quoting cannot keep a
+// value together: it must not contain spaces.
+func CheckPlaceholderValue(value string) error {
+	if strings.ContainsFunc(value, unicode.IsSpace) {
+		return fmt.Errorf("%q has spaces, which would split it into several arguments", value)
+	}
+	return nil
+}
+
+// FillPlaceholders substitutes values into the placeholders of command, as is.
+// An empty value leaves its placeholder out, so an optional argument can be
+// skipped. Every placeholder needs a value that passes CheckPlaceholderValue.
+func FillPlaceholders(command string, values map[string]string) (string, error) {
+	var missing []string
+	for _, name := range Placeholders(command) {
+		value, ok := values[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		if err := CheckPlaceholderValue(value); err != nil {
+			return "", fmt.Errorf("placeholder %s: %w", name, err)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("no value for placeholder(s): %s", strings.Join(missing, ", "))
+	}
+
+	return placeholderPattern.ReplaceAllStringFunc(command, func(placeholder string) string {
+		return values[placeholderPattern.FindStringSubmatch(placeholder)[1]]
+	}), nil
+}
+
+// FillTemplate returns a copy of a templated response with the placeholders
+// filled in. The filled command goes through all the checks again, since the
+// values can change what it does.
+func (c *Client) FillTemplate(response *CommandResponse, values map[string]string) (*CommandResponse, error) {
+	command, err := FillPlaceholders(response.Command, values)
+	if err != nil {
+		return nil, err
+	}
+
+	filled := c.recheck(response, command)
+	filled.Placeholders = nil
+	filled.Candidates = nil
+	return filled, nil
+}
+
+// withoutFindings removes the lines of warning that report findings, keeping
+// the model's own warnings
+func withoutFindings(warning string, findings []Finding) string {
+	messages := make(map[string]bool, len(findings))
+	for _, finding := range findings {
+		messages[finding.Message] = true
+	}
+
+	var kept []string
+	for _, line := range strings.Split(warning, "\n") {
+		if line != "" && !messages[line] {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
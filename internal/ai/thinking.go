@@ -0,0 +1,37 @@
+package ai
+
+import (
+	"strings"
+)
+
+const (
+	thinkOpen  = "<think>"
+	thinkClose = "</think>"
+)
+
+// StripThinking removes <think>...</think> blocks that reasoning models put before
+// their answer and returns the remaining text and the thinking. Some models omit
+// the opening tag, so text before a lone closing tag is treated as thinking too.
+// An unclosed block is left in place rather than risk discarding the answer.
+func StripThinking(response string) (string, string) {
+	var text, thinking []string
+
+	for {
+		start := strings.Index(response, thinkOpen)
+		end := strings.Index(response, thinkClose)
+
+		switch {
+		case end >= 0 && (start < 0 || end < start):
+			// Closing tag without an opening tag
+			thinking = append(thinking, response[:end])
+			response = response[end+len(thinkClose):]
+		case start >= 0 && end > start:
+			text = append(text, response[:start])
+			thinking = append(thinking, response[start+len(thinkOpen):end])
+			response = response[end+len(thinkClose):]
+		default:
+			text = append(text, response)
+			return strings.TrimSpace(strings.Join(text, "")), strings.TrimSpace(strings.Join(thinking, "\n"))
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package ai
+
+// Trace is the exchange with the model behind a response: the prompt exactly as
+// sent and the model output before parsing. It is kept for bug reports.
+type Trace struct {
+	Provider    string `json:"provider"`
+	Model       string `json:"model"`
+	System      string `json:"system,omitempty"`
+	Prompt      string `json:"prompt"`
+	RawResponse string `json:"raw_response"`
+}
@@ -0,0 +1,91 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/system"
+)
+
+const whySystemPrompt = `You are a shell command expert. Your job is to justify a shell command that was suggested for a request.
+
+IMPORTANT RULES:
+1. Explain why this command fits the request better than the alternatives
+2. Discuss the trade-offs of each alternative
+3. Do not suggest a new command
+
+Response format should be JSON with these fields:
+{
+  "explanation": "a detailed justification of the choice",
+  "warning": "any caveats the user should know (optional)",
+  "confidence": 0.95
+}
+`
+
+// Why asks the model to justify a previously generated command against its
+// alternatives. The response is an explanation of command, never a new command.
+func (c *Client) Why(parent context.Context, request, command string, alternatives []string) (*CommandResponse, error) {
+	if strings.TrimSpace(command) == "" {
+		return nil, fmt.Errorf("there is no command to explain yet")
+	}
+
+	modelName, err := c.prepareModel(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(parent, time.Duration(c.config.AI.Timeout)*time.Second)
+	defer cancel()
+
+	req := GenerateRequest{
+		Model:  modelName,
+		Prompt: whyPrompt(request, command, alternatives),
+		System: whySystemPrompt,
+		Mode:   ModeExplain,
+	}
+	if c.observer != nil {
+		req.OnToken = c.observer.OnToken
+	}
+
+	response, attempts, err := c.generateWithRecovery(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain command: %w", err)
+	}
+	response.Attempts = attempts
+	response.Command = command
+
+	if c.observer != nil {
+		c.observer.OnComplete(response)
+	}
+
+	return response, nil
+}
+
+// whyPrompt builds the reasoning prompt for Why
+func whyPrompt(request, command string, alternatives []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Operating System: %s\n\n", system.GOOS())
+	if request != "" {
+		fmt.Fprintf(&b, "User Request: %s\n\n", request)
+	}
+	fmt.Fprintf(&b, "Chosen Command: %s\n\n", command)
+
+	var others []string
+	for _, alt := range alternatives {
+		if alt != command {
+			others = append(others, alt)
+		}
+	}
+	if len(others) > 0 {
+		b.WriteString("Alternatives:\n")
+		for _, alt := range others {
+			fmt.Fprintf(&b, "- %s\n", alt)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("Explain step by step why the chosen command was picked over the alternatives and other reasonable approaches. Respond in JSON format as specified in the system prompt.")
+	return b.String()
+}
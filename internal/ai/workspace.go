@@ -0,0 +1,70 @@
+package ai
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kodelint/shell-agent/internal/config"
+)
+
+// maxListedFiles caps how many entries of the working directory are sent
+const maxListedFiles = 40
+
+// sendsContext reports whether local context such as the working directory may
+// be added to requests. It is always allowed for a local provider; remote
+// providers only receive it with ai.allow_context_to_remote. Every feature that
+// sends local context must check this.
+func (c *Client) sendsContext() bool {
+	return !config.IsRemoteProvider(c.config.AI.Provider) || c.config.AI.AllowContextToRemote
+}
+
+// workspaceContext describes the working directory and its files for the
+// prompt, or returns "" when ai.workspace_context is off or context may not be sent
+func (c *Client) workspaceContext() string {
+	if !c.config.AI.WorkspaceContext || !c.sendsContext() {
+		return ""
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return describeDir(dir)
+}
+
+// describeDir lists the visible entries of dir, directories marked with a slash
+func describeDir(dir string) string {
+	desc := fmt.Sprintf("Working Directory: %s\n", dir)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return desc
+	}
+
+	var names []string
+	more := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		if len(names) == maxListedFiles {
+			more++
+			continue
+		}
+		if entry.IsDir() {
+			name += "/"
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return desc
+	}
+
+	desc += "Files: " + strings.Join(names, ", ")
+	if more > 0 {
+		desc += fmt.Sprintf(" (and %d more)", more)
+	}
+	return desc + "\n"
+}
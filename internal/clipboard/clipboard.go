@@ -0,0 +1,71 @@
+// Package clipboard copies text to the system clipboard using the platform's
+// clipboard utility.
+package clipboard
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// ErrUnavailable is returned when no clipboard utility is installed
+var ErrUnavailable = errors.New("no clipboard utility found (install xclip, xsel or wl-clipboard)")
+
+var (
+	mu     sync.Mutex
+	writer func(text string) error
+)
+
+// SetWriter replaces how text is copied, e.g. to capture clipboard writes in
+// tests. A nil writer restores the system clipboard.
+func SetWriter(w func(text string) error) {
+	mu.Lock()
+	defer mu.Unlock()
+	writer = w
+}
+
+// Write copies text to the clipboard
+func Write(text string) error {
+	mu.Lock()
+	w := writer
+	mu.Unlock()
+
+	if w != nil {
+		return w(text)
+	}
+	return writeSystem(text)
+}
+
+// writeSystem pipes text into the first available clipboard utility
+func writeSystem(text string) error {
+	for _, candidate := range commands() {
+		if _, err := exec.LookPath(candidate[0]); err != nil {
+			continue
+		}
+
+		cmd := exec.Command(candidate[0], candidate[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	return ErrUnavailable
+}
+
+// commands lists the clipboard utilities to try, most specific first
+func commands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip"}}
+	default:
+		return [][]string{
+			{"wl-copy"},
+			{"xclip", "-selection", "clipboard"},
+			{"xsel", "--clipboard", "--input"},
+			// WSL can reach the Windows clipboard
+			{"clip.exe"},
+		}
+	}
+}
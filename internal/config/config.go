@@ -6,11 +6,11 @@ import (
 	"strings"
 
 	"github.com/kodelint/shell-agent/internal/logger"
+	"github.com/kodelint/shell-agent/internal/system"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
-var log = logger.GetLogger()
-
 type Config struct {
 	AI struct {
 		Provider     string  `mapstructure:"provider"`
@@ -20,12 +20,50 @@ type Config struct {
 		MaxTokens    int     `mapstructure:"max_tokens"`
 		Temperature  float64 `mapstructure:"temperature"`
 		SystemPrompt string  `mapstructure:"system_prompt"`
+		// Mode is one of shell, explain or review
+		Mode string `mapstructure:"mode"`
+		// AskWhenAmbiguous lets the model ask a clarifying question instead of guessing
+		AskWhenAmbiguous bool `mapstructure:"ask_when_ambiguous"`
+		// SanitizeInput replaces invalid UTF-8 in prompts instead of rejecting them
+		SanitizeInput bool `mapstructure:"sanitize_input"`
+		// MaxRetries is how many times a request is retried after a network error; 0 disables it
+		MaxRetries int `mapstructure:"max_retries"`
+		// ReparseAttempts is how many times the model is re-prompted for invalid JSON; 0 disables it
+		ReparseAttempts int `mapstructure:"reparse_attempts"`
+		// EscalationModel is retried when confidence falls below EscalationThreshold
+		EscalationModel     string  `mapstructure:"escalation_model"`
+		EscalationThreshold float64 `mapstructure:"escalation_threshold"`
+		// StrictParsing returns an error instead of a heuristic fallback for unparseable responses
+		StrictParsing bool `mapstructure:"strict_parsing"`
+		// MaxResponseBytes caps how much of an Ollama response is read; 0 disables the cap
+		MaxResponseBytes int64 `mapstructure:"max_response_bytes"`
+		// PinModelDigest checks the model against the digest recorded at download: off, warn or refuse
+		PinModelDigest string `mapstructure:"pin_model_digest"`
+		// Alternatives is how many alternative commands to request; -1 uses each model's catalog entry
+		Alternatives int `mapstructure:"alternatives"`
+		// WorkspaceContext adds the working directory and its file names to requests; off by default
+		WorkspaceContext bool `mapstructure:"workspace_context"`
+		// AllowContextToRemote sends local context to remote providers too; by default only local providers get it
+		AllowContextToRemote bool `mapstructure:"allow_context_to_remote"`
 
 		// Ollama specific settings
 		Ollama struct {
 			Host string `mapstructure:"host"`
 			Port int    `mapstructure:"port"`
+			// BaseURL is resolved from Host/Port or OLLAMA_HOST when loading
+			BaseURL string `mapstructure:"-"`
+			// Think asks reasoning models to return their thinking separately
+			Think bool `mapstructure:"think"`
+			// KeepAlive is how long Ollama keeps the model loaded, e.g. "10m"; "" uses Ollama's default
+			KeepAlive string `mapstructure:"keep_alive"`
 		} `mapstructure:"ollama"`
+
+		// OpenAI settings, used when provider is "openai"; any OpenAI-compatible endpoint works
+		OpenAI struct {
+			BaseURL string `mapstructure:"base_url"`
+			// APIKey falls back to $OPENAI_API_KEY
+			APIKey string `mapstructure:"api_key"`
+		} `mapstructure:"openai"`
 	} `mapstructure:"ai"`
 
 	Logging struct {
@@ -33,20 +71,82 @@ type Config struct {
 		File  string `mapstructure:"file"`
 	} `mapstructure:"logging"`
 
+	// Output routes each message category to "stdout" or "stderr"
+	Output struct {
+		Command     string `mapstructure:"command"`
+		Explanation string `mapstructure:"explanation"`
+		Warning     string `mapstructure:"warning"`
+		Status      string `mapstructure:"status"`
+	} `mapstructure:"output"`
+
+	// Script controls scripts written by --script and --script-out
+	Script struct {
+		Shebang string `mapstructure:"shebang"`
+		// StrictMode is the error-handling line after the shebang; empty omits it
+		StrictMode string `mapstructure:"strict_mode"`
+	} `mapstructure:"script"`
+
 	Interactive struct {
 		ConfirmCommands bool `mapstructure:"confirm_commands"`
 		ShowExplanation bool `mapstructure:"show_explanation"`
 		ShowConfidence  bool `mapstructure:"show_confidence"`
 		AutoExecute     bool `mapstructure:"auto_execute"`
+		// ExplainBeforeExecute restates a confirmed command and counts down before running it
+		ExplainBeforeExecute bool `mapstructure:"explain_before_execute"`
+		ExplainCountdown     int  `mapstructure:"explain_countdown"`
+		// OnDecline is what happens to a command the user declines to run: nothing, copy or save
+		OnDecline string `mapstructure:"on_decline"`
+		// PageOutput shows command output taller than the terminal through $PAGER, less or
+		// more; interactive commands and output that is not a terminal are not paged
+		PageOutput bool `mapstructure:"page_output"`
+		// IdleTimeout closes the REPL after this many minutes without input; 0 disables it
+		IdleTimeout int `mapstructure:"idle_timeout"`
+		// FeedbackMode is when to ask whether a command worked: always, on_failure, never or sampled
+		FeedbackMode string `mapstructure:"feedback_mode"`
+		// FeedbackSampleRate is the fraction of commands asked about in sampled mode
+		FeedbackSampleRate float64 `mapstructure:"feedback_sample_rate"`
 	} `mapstructure:"interactive"`
 
+	// History and Feedback merge a repeat of the last entry into it when Dedup is on
+	History struct {
+		Dedup bool `mapstructure:"dedup"`
+	} `mapstructure:"history"`
+
+	Feedback struct {
+		Dedup bool `mapstructure:"dedup"`
+	} `mapstructure:"feedback"`
+
 	Safety struct {
 		DangerousCommands []string `mapstructure:"dangerous_commands"`
-		RequireConfirm    bool     `mapstructure:"require_confirm"`
-		BlockDestructive  bool     `mapstructure:"block_destructive"`
+		// RequireConfirm confirms commands with a critical finding even when auto_execute is on
+		RequireConfirm bool `mapstructure:"require_confirm"`
+		// BlockDestructive refuses to run commands with a critical finding
+		BlockDestructive bool `mapstructure:"block_destructive"`
+		// SecurityWeakening adds rules to the built-in security-weakening checks
+		SecurityWeakening []SecurityRule `mapstructure:"security_weakening"`
+		// ConfirmAboveRisk asks for extra confirmation above this risk score; 0 disables it
+		ConfirmAboveRisk int `mapstructure:"confirm_above_risk"`
+		// ExecAllowlist, when non-empty, is the only programs shell-agent will execute
+		ExecAllowlist []string `mapstructure:"exec_allowlist"`
+		// ConfidenceAdjustments add to the model's confidence per command category, e.g. navigation: 0.1
+		ConfidenceAdjustments map[string]float64 `mapstructure:"confidence_adjustments"`
+		// WarnMissingBinary warns when a generated command runs a program that is not installed
+		WarnMissingBinary bool `mapstructure:"warn_missing_binary"`
+		// MaxPipes, MaxSubshells and MaxCommandLength are the complexity budget; 0 disables a limit
+		MaxPipes         int `mapstructure:"max_pipes"`
+		MaxSubshells     int `mapstructure:"max_subshells"`
+		MaxCommandLength int `mapstructure:"max_command_length"`
+		// ComplexityAction is what happens over the budget: off, warn or block
+		ComplexityAction string `mapstructure:"complexity_action"`
 	} `mapstructure:"safety"`
 }
 
+// SecurityRule flags commands matching a regular expression as weakening security
+type SecurityRule struct {
+	Pattern string `mapstructure:"pattern"`
+	Message string `mapstructure:"message"`
+}
+
 func Load() (*Config, error) {
 	var config Config
 
@@ -56,6 +156,12 @@ func Load() (*Config, error) {
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, err
 	}
+	resolveOllama(&config)
+
+	logger.GetLogger().WithFields(logrus.Fields{
+		"config_file": viper.ConfigFileUsed(),
+		"ollama":      config.AI.Ollama.BaseURL,
+	}).Debug("Configuration loaded")
 
 	return &config, nil
 }
@@ -63,6 +169,9 @@ func Load() (*Config, error) {
 func setDefaults() {
 	home, _ := os.UserHomeDir()
 
+	// Data directory for feedback, sessions and other local state
+	viper.SetDefault("data_dir", filepath.Join(home, ".shell-agent"))
+
 	// AI defaults
 	viper.SetDefault("ai.provider", "ollama")
 	viper.SetDefault("ai.default_model", "llama3.2:3b")
@@ -71,20 +180,60 @@ func setDefaults() {
 	viper.SetDefault("ai.max_tokens", 2048)
 	viper.SetDefault("ai.temperature", 0.1)
 	viper.SetDefault("ai.system_prompt", getDefaultSystemPrompt())
+	viper.SetDefault("ai.sanitize_input", true)
+	viper.SetDefault("ai.mode", "shell")
+	viper.SetDefault("ai.ask_when_ambiguous", false)
+	viper.SetDefault("ai.max_retries", 0)
+	viper.SetDefault("ai.reparse_attempts", 0)
+	viper.SetDefault("ai.escalation_model", "")
+	viper.SetDefault("ai.escalation_threshold", 0.5)
+	viper.SetDefault("ai.max_response_bytes", 10*1024*1024)
+	viper.SetDefault("ai.strict_parsing", false)
+	viper.SetDefault("ai.pin_model_digest", "off")
+	viper.SetDefault("ai.alternatives", -1)
+	viper.SetDefault("ai.workspace_context", false)
+	viper.SetDefault("ai.allow_context_to_remote", false)
 
 	// Ollama defaults
-	viper.SetDefault("ai.ollama.host", "localhost")
-	viper.SetDefault("ai.ollama.port", 11434)
+	// Ollama host and port have no viper defaults so that an unset address can
+	// fall back to OLLAMA_HOST; see resolveOllama
+	viper.SetDefault("ai.ollama.think", false)
+	viper.SetDefault("ai.ollama.keep_alive", "")
+
+	// OpenAI defaults
+	viper.SetDefault("ai.openai.base_url", "https://api.openai.com/v1")
+	viper.SetDefault("ai.openai.api_key", "")
 
 	// Logging defaults
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.file", "")
 
+	// Output defaults
+	viper.SetDefault("output.command", "stdout")
+	viper.SetDefault("output.explanation", "stdout")
+	viper.SetDefault("output.warning", "stdout")
+	viper.SetDefault("output.status", "stdout")
+
+	// Script defaults
+	viper.SetDefault("script.shebang", "#!/usr/bin/env bash")
+	viper.SetDefault("script.strict_mode", "set -euo pipefail")
+
 	// Interactive defaults
 	viper.SetDefault("interactive.confirm_commands", true)
 	viper.SetDefault("interactive.show_explanation", true)
 	viper.SetDefault("interactive.show_confidence", true)
 	viper.SetDefault("interactive.auto_execute", false)
+	viper.SetDefault("interactive.explain_before_execute", false)
+	viper.SetDefault("interactive.explain_countdown", 3)
+	viper.SetDefault("interactive.on_decline", "nothing")
+	viper.SetDefault("interactive.page_output", false)
+	viper.SetDefault("interactive.idle_timeout", 0)
+	viper.SetDefault("interactive.feedback_mode", "always")
+	viper.SetDefault("interactive.feedback_sample_rate", 0.2)
+
+	// Store defaults
+	viper.SetDefault("history.dedup", false)
+	viper.SetDefault("feedback.dedup", false)
 
 	// Safety defaults
 	viper.SetDefault("safety.dangerous_commands", []string{
@@ -93,6 +242,13 @@ func setDefaults() {
 	})
 	viper.SetDefault("safety.require_confirm", true)
 	viper.SetDefault("safety.block_destructive", false)
+	viper.SetDefault("safety.confirm_above_risk", 0)
+	viper.SetDefault("safety.exec_allowlist", []string{})
+	viper.SetDefault("safety.warn_missing_binary", true)
+	viper.SetDefault("safety.max_pipes", 3)
+	viper.SetDefault("safety.max_subshells", 2)
+	viper.SetDefault("safety.max_command_length", 300)
+	viper.SetDefault("safety.complexity_action", "warn")
 }
 
 func getDefaultSystemPrompt() string {
@@ -113,10 +269,27 @@ Response format should be JSON with these fields:
   "explanation": "brief explanation of what the command does",
   "warning": "any safety warnings or considerations (optional)",
   "confidence": 0.95,
-  "alternatives": ["alternative commands if applicable"]
+  "alternatives": ["alternative commands if applicable"],
+  "steps": [{"command": "one command", "explanation": "what it does"}]
+}
+
+Only include "steps" when the request needs several commands run in order.
+
+` + promptExamples()
 }
 
-Examples:
+// promptExamples returns the system prompt examples for the current operating
+// system, cmd.exe on Windows, which runs the commands there, and POSIX shell
+// everywhere else
+func promptExamples() string {
+	if system.GOOS() == "windows" {
+		return `Examples:
+- "list files" → {"command": "dir /a", "explanation": "Lists all files, including hidden ones", "confidence": 0.95}
+- "delete everything" → {"command": "", "warning": "This request is too dangerous. Please specify exactly what you want to delete.", "confidence": 0.0}
+`
+	}
+
+	return `Examples:
 - "list files" → {"command": "ls -la", "explanation": "Lists all files with detailed information", "confidence": 0.95}
 - "delete everything" → {"command": "", "warning": "This request is too dangerous. Please specify exactly what you want to delete.", "confidence": 0.0}
 `
@@ -139,6 +312,16 @@ func GetModelPath() string {
 	return expandPath(path)
 }
 
+// GetDataDir returns the directory where shell-agent stores local state
+func GetDataDir() string {
+	path := viper.GetString("data_dir")
+	if path == "" {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, ".shell-agent")
+	}
+	return expandPath(path)
+}
+
 func GetDefaultModel() string {
 	return viper.GetString("ai.default_model")
 }
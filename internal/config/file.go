@@ -0,0 +1,34 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/spf13/viper"
+)
+
+// ErrInvalidConfigFile is returned when a config file exists but cannot be parsed
+var ErrInvalidConfigFile = errors.New("invalid config file")
+
+// ReadConfigFile reads the config file viper was pointed at and reports whether
+// one was found. A missing file is not an error, since the defaults apply; a
+// file that exists but cannot be read or parsed is, so its settings are never
+// silently ignored.
+func ReadConfigFile() (bool, error) {
+	err := viper.ReadInConfig()
+	if err == nil {
+		return true, nil
+	}
+
+	var notFound viper.ConfigFileNotFoundError
+	if errors.As(err, &notFound) || errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+
+	file := viper.ConfigFileUsed()
+	if file == "" {
+		return true, fmt.Errorf("%w: %v", ErrInvalidConfigFile, err)
+	}
+	return true, fmt.Errorf("%w %s: %v", ErrInvalidConfigFile, file, err)
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kodelint/shell-agent/internal/logger"
+	"github.com/spf13/viper"
+)
+
+// OllamaHostEnv is the environment variable the ollama CLI reads its server address from
+const OllamaHostEnv = "OLLAMA_HOST"
+
+const (
+	DefaultOllamaHost = "localhost"
+	DefaultOllamaPort = 11434
+)
+
+// ParseOllamaHost converts an OLLAMA_HOST value into a base URL. It accepts a
+// bare host, host:port, or a full URL, and fills in the scheme and port the
+// same way the ollama CLI does.
+func ParseOllamaHost(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("%s is empty", OllamaHostEnv)
+	}
+
+	if !strings.Contains(value, "://") {
+		value = "http://" + value
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s %q: %w", OllamaHostEnv, value, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid %s %q: unsupported scheme %q", OllamaHostEnv, value, u.Scheme)
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		host = DefaultOllamaHost
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = strconv.Itoa(DefaultOllamaPort)
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	return fmt.Sprintf("%s://%s%s", u.Scheme, net.JoinHostPort(host, port), strings.TrimRight(u.Path, "/")), nil
+}
+
+// resolveOllama fills in the Ollama address. Explicitly configured host and
+// port win; otherwise OLLAMA_HOST is honored, then localhost:11434.
+func resolveOllama(config *Config) {
+	ollama := &config.AI.Ollama
+
+	if !viper.IsSet("ai.ollama.host") && !viper.IsSet("ai.ollama.port") {
+		if env := os.Getenv(OllamaHostEnv); env != "" {
+			baseURL, err := ParseOllamaHost(env)
+			if err == nil {
+				ollama.BaseURL = baseURL
+				return
+			}
+			logger.GetLogger().WithError(err).Warn("Ignoring invalid OLLAMA_HOST")
+		}
+	}
+
+	if ollama.Host == "" {
+		ollama.Host = DefaultOllamaHost
+	}
+	if ollama.Port == 0 {
+		ollama.Port = DefaultOllamaPort
+	}
+	ollama.BaseURL = fmt.Sprintf("http://%s", net.JoinHostPort(ollama.Host, strconv.Itoa(ollama.Port)))
+}
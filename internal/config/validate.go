@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// remoteModelPrefixes are name prefixes used by hosted model APIs
+var remoteModelPrefixes = []string{"gpt-", "o1", "o3", "o4", "chatgpt-", "text-davinci", "claude-", "gemini-"}
+
+// localModelFamilies are model families typically served by Ollama
+var localModelFamilies = []string{"llama", "mistral", "mixtral", "phi", "gemma", "codegemma", "qwen", "deepseek", "codellama", "starcoder", "tinyllama"}
+
+// IsRemoteProvider reports whether a provider sends requests to a hosted API
+func IsRemoteProvider(provider string) bool {
+	return provider != "" && provider != "ollama"
+}
+
+// ModelProviderMismatch returns why a model name looks wrong for a provider, or ""
+// when it looks compatible. It is a heuristic: custom deployments may use any name.
+func ModelProviderMismatch(provider, model string) string {
+	name := strings.ToLower(strings.TrimSpace(model))
+	if name == "" {
+		return ""
+	}
+
+	looksRemote := false
+	for _, prefix := range remoteModelPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			looksRemote = true
+			break
+		}
+	}
+
+	// Ollama models are tagged "name:tag" or belong to a well-known local family
+	looksLocal := strings.Contains(name, ":")
+	for _, family := range localModelFamilies {
+		if strings.HasPrefix(name, family) {
+			looksLocal = true
+			break
+		}
+	}
+
+	switch {
+	case !IsRemoteProvider(provider) && looksRemote:
+		return fmt.Sprintf("model %q looks like a hosted API model, but provider is %q", model, "ollama")
+	case IsRemoteProvider(provider) && looksLocal && !looksRemote:
+		return fmt.Sprintf("model %q looks like a local Ollama model, but provider is %q", model, provider)
+	}
+	return ""
+}
+
+// Validate returns warnings about settings that are likely mistakes. Warnings do not
+// prevent shell-agent from running since custom deployments vary.
+func (c *Config) Validate() []string {
+	var warnings []string
+
+	if mismatch := ModelProviderMismatch(c.AI.Provider, c.AI.DefaultModel); mismatch != "" {
+		warnings = append(warnings, mismatch+" (check ai.provider and ai.default_model)")
+	}
+
+	return warnings
+}
@@ -0,0 +1,239 @@
+package cookbook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/config"
+	"github.com/kodelint/shell-agent/internal/feedback"
+	"github.com/kodelint/shell-agent/internal/shutdown"
+	"github.com/kodelint/shell-agent/internal/store"
+)
+
+// ErrNotFound is returned when no entry has the requested name
+var ErrNotFound = errors.New("cookbook entry not found")
+
+// ErrExists is returned by Add when an entry with the same name is already saved
+var ErrExists = errors.New("cookbook entry already exists")
+
+// statusWorked is the feedback status that verifies a command
+const statusWorked = "worked"
+
+// Entry is a named, curated command. Verified entries are backed by feedback
+// that the command worked.
+type Entry struct {
+	Name        string    `json:"name"`
+	Command     string    `json:"command"`
+	Description string    `json:"description,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Verified    bool      `json:"verified"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// HasTag reports whether the entry carries the tag, ignoring case
+func (e Entry) HasTag(tag string) bool {
+	for _, t := range e.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// FromFeedback builds a verified entry from feedback that a command worked.
+// The prompt that produced the command becomes the description.
+func FromFeedback(name string, f feedback.Feedback) (Entry, error) {
+	if f.Status != statusWorked {
+		return Entry{}, fmt.Errorf("only feedback with status %q can be promoted, got %q", statusWorked, f.Status)
+	}
+
+	return Entry{
+		Name:        name,
+		Command:     f.GeneratedCommand,
+		Description: f.UserPrompt,
+		Verified:    true,
+	}, nil
+}
+
+// LastWorked returns the most recent "worked" feedback, limited to the given
+// command unless it is empty
+func LastWorked(list []feedback.Feedback, command string) (feedback.Feedback, bool) {
+	command = strings.TrimSpace(command)
+	for i := len(list) - 1; i >= 0; i-- {
+		f := list[i]
+		if f.Status == statusWorked && strings.TrimSpace(f.GeneratedCommand) != "" &&
+			(command == "" || strings.TrimSpace(f.GeneratedCommand) == command) {
+			return f, true
+		}
+	}
+	return feedback.Feedback{}, false
+}
+
+// Store keeps the cookbook in a JSON file
+type Store struct {
+	mu       sync.Mutex
+	filePath string
+}
+
+// NewStore creates a Store backed by cookbook.json in the data dir
+func NewStore() (*Store, error) {
+	dir := config.GetDataDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	return &Store{filePath: filepath.Join(dir, "cookbook.json")}, nil
+}
+
+// Add saves a new entry. Names are unique, ignoring case.
+func (s *Store) Add(entry Entry) (*Entry, error) {
+	entry.Name = strings.TrimSpace(entry.Name)
+	entry.Command = strings.TrimSpace(entry.Command)
+	if entry.Name == "" || strings.ContainsAny(entry.Name, " \t\n") {
+		return nil, fmt.Errorf("invalid entry name %q: use a single word such as 'disk-usage'", entry.Name)
+	}
+	if entry.Command == "" {
+		return nil, fmt.Errorf("command is empty")
+	}
+	entry.Tags = normalizeTags(entry.Tags)
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	if index(list, entry.Name) >= 0 {
+		return nil, fmt.Errorf("%w: %s", ErrExists, entry.Name)
+	}
+
+	list = append(list, entry)
+	if err := s.save(list); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// List returns entries ordered by name; a non-empty tag filters by that tag
+func (s *Store) List(tag string) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Entry
+	for _, e := range list {
+		if tag == "" || e.HasTag(tag) {
+			result = append(result, e)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result, nil
+}
+
+// Get returns the entry with the given name, ignoring case
+func (s *Store) Get(name string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if i := index(list, name); i >= 0 {
+		return &list[i], nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// Remove deletes the entry with the given name
+func (s *Store) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	if i := index(list, name); i >= 0 {
+		return s.save(append(list[:i], list[i+1:]...))
+	}
+	return fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// index returns the position of the named entry, ignoring case, or -1
+func index(list []Entry, name string) int {
+	name = strings.TrimSpace(name)
+	for i, e := range list {
+		if strings.EqualFold(e.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// load reads the cookbook file; the caller must hold s.mu
+func (s *Store) load() ([]Entry, error) {
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read cookbook file: %w", err)
+	}
+
+	var list []Entry
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal cookbook: %w", err)
+		}
+	}
+	return list, nil
+}
+
+// save writes the cookbook file atomically; the caller must hold s.mu
+func (s *Store) save(list []Entry) error {
+	defer shutdown.Begin()()
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cookbook: %w", err)
+	}
+
+	if err := store.WriteFileAtomic(s.filePath, data); err != nil {
+		return fmt.Errorf("failed to write cookbook file: %w", err)
+	}
+	return nil
+}
+
+// normalizeTags trims, lowercases and de-duplicates tags
+func normalizeTags(tags []string) []string {
+	var result []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
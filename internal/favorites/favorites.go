@@ -0,0 +1,194 @@
+package favorites
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/config"
+	"github.com/kodelint/shell-agent/internal/shutdown"
+	"github.com/kodelint/shell-agent/internal/store"
+)
+
+// Favorite is a generated command saved for reuse, with freeform tags
+type Favorite struct {
+	ID        int       `json:"id"`
+	Command   string    `json:"command"`
+	Prompt    string    `json:"prompt,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HasTag reports whether the favorite carries the tag, ignoring case
+func (f Favorite) HasTag(tag string) bool {
+	for _, t := range f.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Store keeps favorites in a JSON file
+type Store struct {
+	mu       sync.Mutex
+	filePath string
+}
+
+// NewStore creates a Store backed by favorites.json in the data dir
+func NewStore() (*Store, error) {
+	dir := config.GetDataDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	return &Store{filePath: filepath.Join(dir, "favorites.json")}, nil
+}
+
+// Add saves a command with the given tags and returns the new favorite
+func (s *Store) Add(command, prompt string, tags []string) (*Favorite, error) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return nil, fmt.Errorf("command is empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	nextID := 1
+	for _, f := range list {
+		if f.ID >= nextID {
+			nextID = f.ID + 1
+		}
+	}
+
+	favorite := Favorite{
+		ID:        nextID,
+		Command:   command,
+		Prompt:    prompt,
+		Tags:      normalizeTags(tags),
+		CreatedAt: time.Now(),
+	}
+	list = append(list, favorite)
+
+	if err := s.save(list); err != nil {
+		return nil, err
+	}
+	return &favorite, nil
+}
+
+// List returns favorites ordered by ID; a non-empty tag filters by that tag
+func (s *Store) List(tag string) ([]Favorite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Favorite
+	for _, f := range list {
+		if tag == "" || f.HasTag(tag) {
+			result = append(result, f)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+
+	return result, nil
+}
+
+// Get returns the favorite with the given ID
+func (s *Store) Get(id int) (*Favorite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range list {
+		if f.ID == id {
+			return &f, nil
+		}
+	}
+	return nil, fmt.Errorf("favorite %d not found", id)
+}
+
+// Remove deletes the favorite with the given ID
+func (s *Store) Remove(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	for i, f := range list {
+		if f.ID == id {
+			return s.save(append(list[:i], list[i+1:]...))
+		}
+	}
+	return fmt.Errorf("favorite %d not found", id)
+}
+
+// load reads the favorites file; the caller must hold s.mu
+func (s *Store) load() ([]Favorite, error) {
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read favorites file: %w", err)
+	}
+
+	var list []Favorite
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal favorites: %w", err)
+		}
+	}
+	return list, nil
+}
+
+// save writes the favorites file atomically; the caller must hold s.mu
+func (s *Store) save(list []Favorite) error {
+	defer shutdown.Begin()()
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal favorites: %w", err)
+	}
+
+	if err := store.WriteFileAtomic(s.filePath, data); err != nil {
+		return fmt.Errorf("failed to write favorites file: %w", err)
+	}
+	return nil
+}
+
+// normalizeTags trims, lowercases and de-duplicates tags
+func normalizeTags(tags []string) []string {
+	var result []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
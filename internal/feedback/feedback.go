@@ -8,8 +8,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/kodelint/shell-agent/internal/config"
 	"github.com/kodelint/shell-agent/internal/logger"
+	"github.com/kodelint/shell-agent/internal/shutdown"
+	"github.com/kodelint/shell-agent/internal/store"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 )
 
 // Feedback represents a single feedback entry from the user.
@@ -21,45 +25,54 @@ type Feedback struct {
 	Status           string    `json:"status"` // e.g., "worked", "failed", "incorrect"
 	CorrectCommand   string    `json:"correct_command,omitempty"`
 	Reason           string    `json:"reason,omitempty"`
+	// Count is how many times the same feedback was repeated when feedback.dedup is on; 0 means once
+	Count int `json:"count,omitempty"`
 }
 
 // Manager handles the saving and loading of feedback data.
 type Manager struct {
 	mu       sync.Mutex
 	filePath string
+	dedup    bool
 	logger   *logrus.Entry
 }
 
 // NewManager creates a new FeedbackManager instance.
 func NewManager() (*Manager, error) {
-	configDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user home directory: %w", err)
-	}
-
-	feedbackDir := filepath.Join(configDir, ".shell-agent")
+	feedbackDir := config.GetDataDir()
 	if _, err := os.Stat(feedbackDir); os.IsNotExist(err) {
 		os.MkdirAll(feedbackDir, 0755)
 	}
 
 	return &Manager{
 		filePath: filepath.Join(feedbackDir, "feedback.json"),
+		dedup:    viper.GetBool("feedback.dedup"),
 		logger:   logger.GetLogger().WithField("component", "feedback-manager"),
 	}, nil
 }
 
 // SaveFeedback appends a new feedback entry to the local file.
 func (m *Manager) SaveFeedback(f Feedback) error {
+	// Keep a shutdown signal from interrupting the write
+	defer shutdown.Begin()()
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	// Load existing feedback
-	feedbackList, err := m.LoadFeedback()
+	feedbackList, err := m.loadFeedback()
 	if err != nil {
 		feedbackList = []Feedback{} // Start with a new list if the file doesn't exist or is empty
 	}
 
-	feedbackList = append(feedbackList, f)
+	// With feedback.dedup on, repeating the last feedback bumps its count instead
+	if n := len(feedbackList); m.dedup && n > 0 && isRepeat(feedbackList[n-1], f) {
+		last := &feedbackList[n-1]
+		last.Timestamp = f.Timestamp
+		last.Count = max(last.Count, 1) + 1
+	} else {
+		feedbackList = append(feedbackList, f)
+	}
 
 	// Save the updated list
 	data, err := json.MarshalIndent(feedbackList, "", "  ")
@@ -67,7 +80,18 @@ func (m *Manager) SaveFeedback(f Feedback) error {
 		return fmt.Errorf("failed to marshal feedback data: %w", err)
 	}
 
-	return os.WriteFile(m.filePath, data, 0644)
+	// Write to a temporary file first so an interrupted write never corrupts the existing file
+	if err := store.WriteFileAtomic(m.filePath, data); err != nil {
+		return fmt.Errorf("failed to write feedback file: %w", err)
+	}
+
+	return nil
+}
+
+// isRepeat reports whether f repeats last: the same prompt, command and status shortly after it
+func isRepeat(last, f Feedback) bool {
+	return last.Status == f.Status &&
+		store.IsRepeat(last.UserPrompt, last.GeneratedCommand, last.Timestamp, f.UserPrompt, f.GeneratedCommand, f.Timestamp)
 }
 
 // LoadFeedback reads all feedback entries from the local file.
@@ -75,6 +99,11 @@ func (m *Manager) LoadFeedback() ([]Feedback, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	return m.loadFeedback()
+}
+
+// loadFeedback reads the feedback file; the caller must hold m.mu
+func (m *Manager) loadFeedback() ([]Feedback, error) {
 	data, err := os.ReadFile(m.filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read feedback file: %w", err)
@@ -0,0 +1,135 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/config"
+	"github.com/kodelint/shell-agent/internal/logger"
+	"github.com/kodelint/shell-agent/internal/shutdown"
+	"github.com/kodelint/shell-agent/internal/store"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// ErrEmpty is returned by Last when no command has been generated yet
+var ErrEmpty = errors.New("no commands in history yet")
+
+// Entry is a single generated command
+type Entry struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Prompt    string    `json:"prompt"`
+	Command   string    `json:"command"`
+	Model     string    `json:"model,omitempty"`
+	// Alternatives are the other commands the model offered
+	Alternatives []string `json:"alternatives,omitempty"`
+	// Count is how many times the entry was repeated when history.dedup is on; 0 means once
+	Count int `json:"count,omitempty"`
+}
+
+// Store appends generated commands to a JSON Lines file
+type Store struct {
+	mu       sync.Mutex
+	filePath string
+	dedup    bool
+	logger   *logrus.Entry
+}
+
+// NewStore creates a Store backed by history.jsonl in the data dir
+func NewStore() (*Store, error) {
+	dir := config.GetDataDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	return &Store{
+		filePath: filepath.Join(dir, "history.jsonl"),
+		dedup:    viper.GetBool("history.dedup"),
+		logger:   logger.GetLogger().WithField("component", "history"),
+	}, nil
+}
+
+// Record appends an entry to the history file. With history.dedup on, a repeat
+// of the last entry updates its timestamp and count instead.
+func (s *Store) Record(entry Entry) error {
+	defer shutdown.Begin()()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.dedup {
+		entries, err := s.load()
+		if err != nil {
+			return err
+		}
+		if n := len(entries); n > 0 {
+			last := &entries[n-1]
+			if store.IsRepeat(last.Prompt, last.Command, last.Timestamp, entry.Prompt, entry.Command, entry.Timestamp) {
+				last.Timestamp = entry.Timestamp
+				last.Count = max(last.Count, 1) + 1
+				if err := store.WriteJSONLines(s.filePath, entries); err != nil {
+					return fmt.Errorf("failed to write history file: %w", err)
+				}
+				return nil
+			}
+		}
+	}
+
+	if err := store.AppendJSONLine(s.filePath, entry); err != nil {
+		return fmt.Errorf("failed to write history entry: %w", err)
+	}
+	return nil
+}
+
+// Load reads all entries, oldest first. Malformed lines are skipped.
+func (s *Store) Load() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.load()
+}
+
+// load reads the history file; the caller must hold s.mu
+func (s *Store) load() ([]Entry, error) {
+	file, err := os.Open(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	err = store.ReadLines(file, func(line []byte) {
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			s.logger.WithError(err).Debug("Skipping malformed history line")
+			return
+		}
+		entries = append(entries, entry)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Last returns the most recent entry
+func (s *Store) Last() (*Entry, error) {
+	entries, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrEmpty
+	}
+	return &entries[len(entries)-1], nil
+}
@@ -0,0 +1,91 @@
+// Package idle ends interactive sessions that have gone without input for too
+// long, so an abandoned REPL doesn't stay open on a shared terminal.
+package idle
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrIdle is returned by Scan when no input arrives before the timeout
+var ErrIdle = errors.New("idle timeout")
+
+// Timer tracks the time since the last input, or since the caller last reset it
+// when it started waiting for input
+type Timer struct {
+	timeout time.Duration
+	now     func() time.Time
+	after   func(time.Duration) <-chan time.Time
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// NewTimer starts a timer that expires after timeout without input; 0 disables
+// it. A nil now uses time.Now and a nil after uses time.After.
+func NewTimer(timeout time.Duration, now func() time.Time, after func(time.Duration) <-chan time.Time) *Timer {
+	if now == nil {
+		now = time.Now
+	}
+	if after == nil {
+		after = time.After
+	}
+	return &Timer{timeout: timeout, now: now, after: after, last: now()}
+}
+
+// Reset restarts the timeout; call it when input is recorded or requested
+func (t *Timer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.last = t.now()
+}
+
+// Remaining returns how long until the timer expires
+func (t *Timer) Remaining() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.timeout - t.now().Sub(t.last)
+}
+
+// Expired reports whether the timeout has passed since the last input
+func (t *Timer) Expired() bool {
+	return t.timeout > 0 && t.Remaining() <= 0
+}
+
+// Scan waits for the next line from scanner, resetting the timer when it
+// arrives. It returns io.EOF at the end of input and ErrIdle when the timer
+// expires first; the scanner must not be used again after ErrIdle, since a
+// read is still pending on it.
+func (t *Timer) Scan(scanner *bufio.Scanner) (string, error) {
+	scanned := make(chan bool, 1)
+	go func() {
+		scanned <- scanner.Scan()
+	}()
+
+	for {
+		var expiry <-chan time.Time
+		if t.timeout > 0 {
+			expiry = t.after(t.Remaining())
+		}
+
+		select {
+		case ok := <-scanned:
+			if !ok {
+				if err := scanner.Err(); err != nil {
+					return "", err
+				}
+				return "", io.EOF
+			}
+			t.Reset()
+			return scanner.Text(), nil
+		case <-expiry:
+			// Check against the clock, in case the wait ended early
+			if t.Expired() {
+				return "", ErrIdle
+			}
+		}
+	}
+}
@@ -1,37 +1,63 @@
 package logger
 
 import (
+	"io"
 	"os"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 )
 
-var log *logrus.Logger
+var (
+	// mu guards log, which is initialized on first use from many goroutines
+	mu  sync.Mutex
+	log *logrus.Logger
+)
 
+// InitLogger configures the shared logger. An existing logger is reconfigured in
+// place, so references taken before flags were parsed see the new level.
 func InitLogger(debug, verbose bool) {
-	log = logrus.New()
+	mu.Lock()
+	defer mu.Unlock()
+
+	if log == nil {
+		log = logrus.New()
+	}
+	configure(log, debug, verbose)
+}
+
+func GetLogger() *logrus.Logger {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if log == nil {
+		log = logrus.New()
+		configure(log, false, false)
+	}
+	return log
+}
 
+// SetOutput sends log entries to w instead of stdout, e.g. to stderr while
+// stdout carries JSON
+func SetOutput(w io.Writer) {
+	GetLogger().SetOutput(w)
+}
+
+func configure(l *logrus.Logger, debug, verbose bool) {
 	// Set log level
 	if debug {
-		log.SetLevel(logrus.DebugLevel)
+		l.SetLevel(logrus.DebugLevel)
 	} else if verbose {
-		log.SetLevel(logrus.InfoLevel)
+		l.SetLevel(logrus.InfoLevel)
 	} else {
-		log.SetLevel(logrus.WarnLevel)
+		l.SetLevel(logrus.WarnLevel)
 	}
 
 	// Set formatter
-	log.SetFormatter(&logrus.TextFormatter{
+	l.SetFormatter(&logrus.TextFormatter{
 		FullTimestamp: true,
 		ForceColors:   true,
 	})
 
-	log.SetOutput(os.Stdout)
-}
-
-func GetLogger() *logrus.Logger {
-	if log == nil {
-		InitLogger(false, false)
-	}
-	return log
+	l.SetOutput(os.Stdout)
 }
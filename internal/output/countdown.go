@@ -0,0 +1,65 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+)
+
+// ErrAborted is returned when the user aborts a countdown
+var ErrAborted = errors.New("execution aborted")
+
+// PrintFinalCheck restates what a command will do right before it runs
+func PrintFinalCheck(response *ai.CommandResponse) {
+	w := writerFor(CategoryStatus)
+	fmt.Fprintln(w)
+	cyan.Fprintln(w, "🔎 About to run:")
+	white.Fprintf(w, "   %s\n", response.Command)
+	if summary := firstSentence(response.Explanation); summary != "" {
+		fmt.Fprintf(w, "   %s\n", summary)
+	}
+	if response.HasCritical() {
+		red.Fprintln(w, "   ⚠️  This command was flagged as dangerous")
+	}
+}
+
+// Countdown counts down the given number of seconds, one tick per second, and
+// returns ErrAborted if abort receives first. A nil after uses time.After.
+func Countdown(seconds int, abort <-chan os.Signal, after func(time.Duration) <-chan time.Time) error {
+	if after == nil {
+		after = time.After
+	}
+
+	w := writerFor(CategoryStatus)
+	for remaining := seconds; remaining > 0; remaining-- {
+		yellow.Fprintf(w, "\r⏳ Running in %d... (Ctrl+C to abort) ", remaining)
+
+		select {
+		case <-abort:
+			fmt.Fprintln(w)
+			return ErrAborted
+		case <-after(time.Second):
+		}
+	}
+
+	if seconds > 0 {
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+// firstSentence returns the first sentence of an explanation
+func firstSentence(text string) string {
+	text = strings.TrimSpace(text)
+	if idx := strings.Index(text, ". "); idx != -1 {
+		return text[:idx+1]
+	}
+	if idx := strings.Index(text, "\n"); idx != -1 {
+		return strings.TrimSpace(text[:idx])
+	}
+	return text
+}
@@ -0,0 +1,59 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kodelint/shell-agent/internal/clipboard"
+	"github.com/kodelint/shell-agent/internal/favorites"
+)
+
+// DeclineAction is what happens to a command the user chose not to execute
+type DeclineAction string
+
+const (
+	DeclineNothing DeclineAction = "nothing"
+	DeclineCopy    DeclineAction = "copy"
+	DeclineSave    DeclineAction = "save"
+)
+
+// DeclineActions lists the valid interactive.on_decline values
+var DeclineActions = []DeclineAction{DeclineNothing, DeclineCopy, DeclineSave}
+
+// ParseDeclineAction converts an interactive.on_decline value; "" means nothing
+func ParseDeclineAction(value string) (DeclineAction, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return DeclineNothing, nil
+	}
+
+	for _, action := range DeclineActions {
+		if string(action) == value {
+			return action, nil
+		}
+	}
+	return "", fmt.Errorf("invalid on_decline action %q: use 'nothing', 'copy' or 'save'", value)
+}
+
+// HandleDecline copies a declined command to the clipboard or saves it as a
+// favorite, depending on the action
+func HandleDecline(action DeclineAction, prompt, command string) error {
+	switch action {
+	case DeclineCopy:
+		if err := clipboard.Write(command); err != nil {
+			return fmt.Errorf("failed to copy command: %w", err)
+		}
+		PrintSuccess("📋 Command copied to clipboard")
+	case DeclineSave:
+		store, err := favorites.NewStore()
+		if err != nil {
+			return err
+		}
+		favorite, err := store.Add(command, prompt, nil)
+		if err != nil {
+			return fmt.Errorf("failed to save command: %w", err)
+		}
+		PrintSuccess(fmt.Sprintf("⭐ Saved as favorite #%d", favorite.ID))
+	}
+	return nil
+}
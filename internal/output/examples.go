@@ -0,0 +1,41 @@
+package output
+
+import (
+	"github.com/kodelint/shell-agent/internal/system"
+)
+
+// Example pairs a natural language request with a command it could produce
+type Example struct {
+	Request string
+	Command string
+}
+
+var unixExamples = []Example{
+	{"list all files in current directory", "ls -la"},
+	{"find all .py files modified in the last 7 days", "find . -name '*.py' -mtime -7"},
+	{"create a backup of my documents folder", "tar -czf documents-backup.tar.gz ~/Documents"},
+	{"show disk usage of current directory", "du -sh ."},
+	{"compress this folder into a tar.gz file", "tar -czf folder.tar.gz ."},
+	{"find files larger than 100MB", "find . -size +100M"},
+	{"show running processes using port 8080", "lsof -i :8080"},
+}
+
+// windowsExamples are cmd.exe commands, since that is what runs them on Windows
+var windowsExamples = []Example{
+	{"list all files in current directory", "dir /a"},
+	{"find all .py files modified in the last 7 days", "forfiles /s /m *.py /d -7"},
+	{"create a backup of my documents folder", "robocopy %USERPROFILE%\\Documents documents-backup /e"},
+	{"show disk usage of current directory", "dir /s"},
+	{"compress this folder into a zip file", "tar -a -cf folder.zip ."},
+	{"find all .log files in subdirectories", "dir /s /b *.log"},
+	{"show running processes using port 8080", "netstat -ano | findstr :8080"},
+}
+
+// HelpExamples returns example requests for the current operating system,
+// cmd.exe on Windows and POSIX shell everywhere else
+func HelpExamples() []Example {
+	if system.GOOS() == "windows" {
+		return windowsExamples
+	}
+	return unixExamples
+}
@@ -0,0 +1,100 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+)
+
+var (
+	// ErrDeclined is returned by RunResponse when the user chooses not to run the command
+	ErrDeclined = errors.New("command was not confirmed")
+	// ErrCancelled is returned by RunResponse when a confirmed command is stopped before it runs
+	ErrCancelled = errors.New("execution cancelled")
+	// ErrBlocked is returned by RunResponse when safety.block_destructive refuses the command
+	ErrBlocked = errors.New("destructive command blocked by safety.block_destructive")
+	// ErrTooComplex is returned by RunResponse when safety.complexity_action is block and the command is over budget
+	ErrTooComplex = errors.New("command exceeds the complexity budget (safety.complexity_action is block)")
+	// ErrNotAllowed is returned by RunResponse when the command runs a program outside safety.exec_allowlist
+	ErrNotAllowed = errors.New("not in safety.exec_allowlist")
+)
+
+// ExecOptions are the confirmation rules RunResponse applies
+type ExecOptions struct {
+	// AutoExecute runs commands without asking, unless ConfirmCommands is set
+	// or RequireConfirm is set and the command has a critical safety finding
+	AutoExecute     bool
+	ConfirmCommands bool
+	RequireConfirm  bool
+	// BlockDestructive refuses commands with a critical safety finding
+	BlockDestructive bool
+	// BlockComplex refuses commands over the complexity budget
+	BlockComplex bool
+	// ConfirmAboveRisk asks for extra confirmation above this risk score; 0 disables it
+	ConfirmAboveRisk int
+	// Allowlist, when non-empty, is the only programs that may be executed
+	Allowlist []string
+	// Pause, when set, runs after confirmation and returns false to cancel
+	Pause func(response *ai.CommandResponse) bool
+	// PageOutput streams output through a pager, see ExecuteCommandPaged
+	PageOutput bool
+}
+
+var (
+	executorMu sync.Mutex
+	executor   func(command string) error
+)
+
+// SetExecutor replaces how confirmed commands are run, e.g. to capture them in
+// tests. A nil executor restores ExecuteCommand.
+func SetExecutor(fn func(command string) error) {
+	executorMu.Lock()
+	defer executorMu.Unlock()
+	executor = fn
+}
+
+// RunResponse confirms a generated command according to opts and runs it,
+// returning the command's error if it fails. This is the single execution path
+// for interactive and single-command mode.
+func RunResponse(response *ai.CommandResponse, opts ExecOptions) error {
+	if len(opts.Allowlist) > 0 {
+		if disallowed := ai.DisallowedPrograms(response.Command, opts.Allowlist); len(disallowed) > 0 {
+			return fmt.Errorf("refusing to run %s: %w", strings.Join(disallowed, ", "), ErrNotAllowed)
+		}
+	}
+	if opts.BlockDestructive && response.HasCritical() {
+		return ErrBlocked
+	}
+	if opts.BlockComplex && response.TooComplex() {
+		return ErrTooComplex
+	}
+
+	ask := opts.ConfirmCommands || !opts.AutoExecute || (opts.RequireConfirm && response.HasCritical())
+	if ask && !PromptExecuteCommand() {
+		return ErrDeclined
+	}
+
+	if response.NeedsRiskConfirmation(opts.ConfirmAboveRisk) && !PromptRiskConfirmation(response.RiskScore, opts.ConfirmAboveRisk) {
+		return ErrCancelled
+	}
+	if opts.Pause != nil && !opts.Pause(response) {
+		return ErrCancelled
+	}
+
+	PrintInfo("🚀 Executing command...")
+
+	executorMu.Lock()
+	run := executor
+	executorMu.Unlock()
+	if run == nil {
+		run = ExecuteCommand
+		if opts.PageOutput {
+			run = ExecuteCommandPaged
+		}
+	}
+
+	return run(response.Command)
+}
@@ -0,0 +1,51 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FeedbackMode decides when to ask whether an executed command worked
+type FeedbackMode string
+
+const (
+	FeedbackAlways    FeedbackMode = "always"
+	FeedbackOnFailure FeedbackMode = "on_failure"
+	FeedbackNever     FeedbackMode = "never"
+	FeedbackSampled   FeedbackMode = "sampled"
+)
+
+// FeedbackModes lists the valid interactive.feedback_mode values
+var FeedbackModes = []FeedbackMode{FeedbackAlways, FeedbackOnFailure, FeedbackNever, FeedbackSampled}
+
+// ParseFeedbackMode converts an interactive.feedback_mode value; "" means always
+func ParseFeedbackMode(value string) (FeedbackMode, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return FeedbackAlways, nil
+	}
+
+	for _, mode := range FeedbackModes {
+		if string(mode) == value {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("invalid feedback_mode %q: use 'always', 'on_failure', 'never' or 'sampled'", value)
+}
+
+// ShouldAskFeedback reports whether to ask for feedback on a command that ran
+// and returned runErr, which is non-nil when it exited non-zero. In sampled
+// mode a fraction rate of commands is asked about, drawing from sample, which
+// returns a number in [0, 1).
+func ShouldAskFeedback(mode FeedbackMode, runErr error, rate float64, sample func() float64) bool {
+	switch mode {
+	case FeedbackNever:
+		return false
+	case FeedbackOnFailure:
+		return runErr != nil
+	case FeedbackSampled:
+		return sample() < rate
+	default:
+		return true
+	}
+}
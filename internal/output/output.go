@@ -2,6 +2,7 @@ package output
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"runtime"
@@ -10,7 +11,6 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/kodelint/shell-agent/internal/ai"
-	"github.com/kodelint/shell-agent/internal/system"
 	"github.com/manifoldco/promptui"
 )
 
@@ -25,55 +25,88 @@ var (
 	boldGreen = color.New(color.FgGreen, color.Bold)
 )
 
-type StatusInfo struct {
-	ModelManager *ai.ModelManager
-	SystemInfo   *system.SystemInfo
-	ModelOnly    bool
-}
-
 func PrintWelcome() {
-	fmt.Println()
-	cyan.Println("🤖 Shell Agent - AI-Powered Command Generator")
-	cyan.Println("==========================================")
-	green.Println("✨ Welcome to your intelligent shell assistant!")
-	green.Println("💬 Type your requests in natural language")
-	green.Println("📝 Available commands: help, status, clear, exit")
-	fmt.Println()
+	w := writerFor(CategoryStatus)
+	fmt.Fprintln(w)
+	cyan.Fprintln(w, "🤖 Shell Agent - AI-Powered Command Generator")
+	cyan.Fprintln(w, "==========================================")
+	green.Fprintln(w, "✨ Welcome to your intelligent shell assistant!")
+	green.Fprintln(w, "💬 Type your requests in natural language")
+	green.Fprintln(w, "📝 Available commands: help, status, clear, exit")
+	fmt.Fprintln(w)
 }
 
 func PrintPrompt() {
-	boldGreen.Print("🤖 shell-agent ➤ ")
+	w := writerFor(CategoryStatus)
+	boldGreen.Fprint(w, "🤖 shell-agent ➤ ")
+}
+
+// PrintAnswerPrompt prompts for the answer to a clarifying question
+func PrintAnswerPrompt() {
+	w := writerFor(CategoryStatus)
+	boldGreen.Fprint(w, "💬 Your answer ➤ ")
 }
 
 func PrintThinking() {
-	magenta.Print("🧠 Thinking... ")
-	fmt.Println()
+	w := writerFor(CategoryStatus)
+	magenta.Fprint(w, "🧠 Thinking... ")
+	fmt.Fprintln(w)
 }
 
 func PrintResponse(response *ai.CommandResponse) {
-	fmt.Println()
+	if response.Mode == ai.ModeExplain || response.Mode == ai.ModeReview {
+		printAnalysis(response)
+		return
+	}
+
+	status := writerFor(CategoryStatus)
+	explanation := writerFor(CategoryExplanation)
+
+	if response.NeedsClarification() {
+		fmt.Fprintln(status)
+		cyan.Fprintln(explanation, "❓ I need a bit more detail:")
+		streamString(explanation, "   "+response.Clarification+"\n", cyan, 20*time.Millisecond)
+		fmt.Fprintln(status)
+		return
+	}
+
+	fmt.Fprintln(status)
 
 	// Print explanation in green
 	if response.Explanation != "" {
-		green.Println("💡 Explanation:")
-		streamString("   "+response.Explanation+"\n", green, 20*time.Millisecond)
-		fmt.Println()
+		green.Fprintln(explanation, "💡 Explanation:")
+		streamString(explanation, "   "+response.Explanation+"\n", green, 20*time.Millisecond)
+		fmt.Fprintln(explanation)
 	}
 
 	// Print command in bold white
-	white.Println("🚀 Generated Command:")
-	streamString("   "+response.Command+"\n", white, 10*time.Millisecond)
+	printCommand(response.Command)
+
+	// List the command and its alternatives best-first
+	if len(response.Candidates) > 1 {
+		fmt.Fprintln(explanation)
+		cyan.Fprintln(explanation, "🔀 Candidates (best first):")
+		for i, candidate := range response.Candidates {
+			fmt.Fprintf(explanation, "   %d. %s\n", i+1, candidateLabel(candidate))
+		}
+	}
 
 	// Print warning if exists
 	if response.Warning != "" {
-		fmt.Println()
-		yellow.Println("⚠️  Warning:")
-		streamString("   "+response.Warning+"\n", yellow, 20*time.Millisecond)
+		warning := writerFor(CategoryWarning)
+		fmt.Fprintln(warning)
+		yellow.Fprintln(warning, "⚠️  Warning:")
+		streamString(warning, "   "+response.Warning+"\n", yellow, 20*time.Millisecond)
+	}
+
+	// Print the risk score and what contributed to it
+	if response.RiskScore > 0 {
+		printRisk(response)
 	}
 
 	// Print confidence if available
 	if response.Confidence > 0 {
-		fmt.Println()
+		fmt.Fprintln(status)
 		prefix := "✅ Confidence: "
 		var confidenceColor *color.Color
 		if response.Confidence >= 0.8 {
@@ -87,17 +120,108 @@ func PrintResponse(response *ai.CommandResponse) {
 		}
 
 		// We'll print the prefix first without streaming.
-		confidenceColor.Print(prefix)
+		confidenceColor.Fprint(status, prefix)
 		// Now we'll stream the rest of the message.
 		confidenceMessage := fmt.Sprintf("%.0f%%\n", response.Confidence*100)
-		streamString(confidenceMessage, confidenceColor, 20*time.Millisecond)
+		streamString(status, confidenceMessage, confidenceColor, 20*time.Millisecond)
 	}
 
-	fmt.Println()
+	fmt.Fprintln(status)
 }
 
-// PromptForFeedback asks the user to rate the last command.
+// printRisk prints the risk score followed by its contributing factors
+func printRisk(response *ai.CommandResponse) {
+	w := writerFor(CategoryWarning)
+
+	riskColor := green
+	if response.RiskScore >= 70 {
+		riskColor = red
+	} else if response.RiskScore >= 40 {
+		riskColor = yellow
+	}
+
+	factors := make([]string, 0, len(response.RiskFactors))
+	for _, factor := range response.RiskFactors {
+		factors = append(factors, fmt.Sprintf("%s +%d", factor.Name, factor.Weight))
+	}
+
+	fmt.Fprintln(w)
+	riskColor.Fprintf(w, "🎯 Risk score: %d/100 (%s)\n", response.RiskScore, strings.Join(factors, ", "))
+}
+
+// printCommand prints the generated command. When the command is routed to its own
+// stream it is printed bare, so that $(shell-agent ...) captures only the command.
+func printCommand(command string) {
+	w := writerFor(CategoryCommand)
+	if commandRoutedSeparately() {
+		fmt.Fprintln(w, command)
+		return
+	}
+
+	white.Fprintln(w, "🚀 Generated Command:")
+	streamString(w, "   "+command+"\n", white, 10*time.Millisecond)
+}
+
+// printAnalysis renders explain and review mode responses
+func printAnalysis(response *ai.CommandResponse) {
+	status := writerFor(CategoryStatus)
+	explanation := writerFor(CategoryExplanation)
+	warning := writerFor(CategoryWarning)
+
+	fmt.Fprintln(status)
+
+	white.Fprintln(status, "📘 Command:")
+	fmt.Fprintf(status, "   %s\n", response.Command)
+	fmt.Fprintln(status)
+
+	if response.Mode == ai.ModeReview {
+		green.Fprintln(explanation, "🔍 Review:")
+	} else {
+		green.Fprintln(explanation, "💡 Explanation:")
+	}
+	if response.Explanation != "" {
+		streamString(explanation, "   "+response.Explanation+"\n", green, 20*time.Millisecond)
+	}
+
+	if len(response.Issues) > 0 {
+		fmt.Fprintln(warning)
+		yellow.Fprintln(warning, "🚩 Issues:")
+		for _, issue := range response.Issues {
+			yellow.Fprintf(warning, "   • %s\n", issue)
+		}
+	} else if response.Mode == ai.ModeReview {
+		fmt.Fprintln(explanation)
+		green.Fprintln(explanation, "✅ No issues found")
+	}
+
+	if response.Risk != "" {
+		fmt.Fprintln(warning)
+		riskColor := green
+		switch response.Risk {
+		case "high":
+			riskColor = red
+		case "medium":
+			riskColor = yellow
+		}
+		riskColor.Fprintf(warning, "📊 Risk: %s\n", response.Risk)
+	}
+
+	if response.Warning != "" {
+		fmt.Fprintln(warning)
+		yellow.Fprintln(warning, "⚠️  Warning:")
+		streamString(warning, "   "+response.Warning+"\n", yellow, 20*time.Millisecond)
+	}
+
+	fmt.Fprintln(status)
+}
+
+// PromptForFeedback asks the user to rate the last command. Without a terminal
+// feedback is skipped.
 func PromptForFeedback() (string, error) {
+	if !isTerminal() {
+		return "", nil
+	}
+
 	// Options for the user to choose from
 	options := []string{"👍 Worked", "👎 Didn't Work", "❌ Incorrect"}
 
@@ -137,105 +261,126 @@ func PromptForFeedback() (string, error) {
 }
 
 func PrintError(message string) {
-	red.Printf("❌ Error: %s\n", message)
+	red.Fprintf(writerFor(CategoryWarning), "❌ Error: %s\n", message)
 }
 
 func PrintSuccess(message string) {
-	green.Printf("✅ %s\n", message)
+	w := writerFor(CategoryStatus)
+	green.Fprintf(w, "✅ %s\n", message)
 }
 
 func PrintWarning(message string) {
-	yellow.Printf("⚠️  %s\n", message)
+	yellow.Fprintf(writerFor(CategoryWarning), "⚠️  %s\n", message)
+}
+
+// PrintDiagnostic writes a warning to stderr whatever the routes, for messages
+// printed before any command runs, so they never mix with JSON on stdout
+func PrintDiagnostic(message string) {
+	yellow.Fprintf(stderr, "⚠️  %s\n", message)
 }
 
 func PrintInfo(message string) {
-	blue.Printf("ℹ️  %s\n", message)
+	w := writerFor(CategoryStatus)
+	blue.Fprintf(w, "ℹ️  %s\n", message)
 }
 
 func PrintGoodbye() {
-	fmt.Println()
-	cyan.Println("👋 Thank you for using Shell Agent!")
-	cyan.Println("🚀 May your commands be swift and your deployments bug-free!")
-	fmt.Println()
+	w := writerFor(CategoryStatus)
+	fmt.Fprintln(w)
+	cyan.Fprintln(w, "👋 Thank you for using Shell Agent!")
+	cyan.Fprintln(w, "🚀 May your commands be swift and your deployments bug-free!")
+	fmt.Fprintln(w)
 }
 
-func PrintStatus(status *StatusInfo) {
-	fmt.Println()
-	cyan.Println("📊 Shell Agent Status")
-	cyan.Println("=====================")
-	fmt.Println()
+// PrintStatus renders a status built by BuildStatus. modelOnly limits it to the model.
+func PrintStatus(status *Status, modelOnly bool) {
+	w := writerFor(CategoryStatus)
+	fmt.Fprintln(w)
+	cyan.Fprintln(w, "📊 Shell Agent Status")
+	cyan.Fprintln(w, "=====================")
+	fmt.Fprintln(w)
 
 	// Model information
-	currentModel := status.ModelManager.GetCurrentModel()
-	if currentModel != nil {
-		boldGreen.Printf("🤖 Current Model: %s\n", currentModel.Name)
-		green.Printf("📁 Model Path: %s\n", status.ModelManager.GetModelPath())
+	if status.Model != nil {
+		boldGreen.Fprintf(w, "🤖 Current Model: %s\n", status.Model.Name)
+		green.Fprintf(w, "📁 Model Path: %s\n", status.Model.Path)
 
-		if currentModel.Downloaded {
-			green.Println("✅ Model Status: Ready")
+		if status.Model.Downloaded {
+			green.Fprintln(w, "✅ Model Status: Ready")
 		} else {
-			yellow.Println("⚠️  Model Status: Not Downloaded")
+			yellow.Fprintln(w, "⚠️  Model Status: Not Downloaded")
 			PrintInfo("💡 Run 'shell-agent download' to install this model")
 		}
 	} else {
-		red.Println("❌ No model configured")
+		red.Fprintln(w, "❌ No model configured")
 		PrintInfo("💡 Run 'shell-agent download' to install a model")
 	}
 
-	if !status.ModelOnly {
-		fmt.Println()
+	if status.Ollama.Available {
+		green.Fprintf(w, "🦙 Ollama: Running at %s\n", status.Ollama.URL)
+	} else {
+		yellow.Fprintf(w, "⚠️  Ollama: Not available at %s\n", status.Ollama.URL)
+	}
+
+	if !modelOnly {
+		fmt.Fprintln(w)
 
 		// System information
-		sysInfo := status.SystemInfo.GetInfo()
-		boldGreen.Println("💻 System Information:")
-		fmt.Printf("   🖥️  OS: %s\n", sysInfo.OS)
-		fmt.Printf("   🏗️  Architecture: %s\n", sysInfo.Arch)
-		fmt.Printf("   🐹 Go Version: %s\n", sysInfo.GoVersion)
+		sysInfo := status.System
+		boldGreen.Fprintln(w, "💻 System Information:")
+		fmt.Fprintf(w, "   🖥️  OS: %s\n", sysInfo.OS)
+		fmt.Fprintf(w, "   🏗️  Architecture: %s\n", sysInfo.Arch)
+		fmt.Fprintf(w, "   🐹 Go Version: %s\n", sysInfo.GoVersion)
 
-		fmt.Println()
+		fmt.Fprintln(w)
 
 		// Configuration
-		boldGreen.Println("⚙️  Configuration:")
+		boldGreen.Fprintln(w, "⚙️  Configuration:")
 		if sysInfo.ConfigFile != "" {
-			fmt.Printf("   📋 Config File: %s\n", sysInfo.ConfigFile)
+			fmt.Fprintf(w, "   📋 Config File: %s\n", sysInfo.ConfigFile)
 		} else {
-			fmt.Printf("   📋 Config File: Not found (using defaults)\n")
+			fmt.Fprintf(w, "   📋 Config File: Not found (using defaults)\n")
 		}
-		fmt.Printf("   🐛 Debug Mode: %v\n", sysInfo.Debug)
-		fmt.Printf("   📝 Verbose Mode: %v\n", sysInfo.Verbose)
+		fmt.Fprintf(w, "   🐛 Debug Mode: %v\n", sysInfo.Debug)
+		fmt.Fprintf(w, "   📝 Verbose Mode: %v\n", sysInfo.Verbose)
+		fmt.Fprintf(w, "   💬 Feedback Entries: %d\n", status.FeedbackCount)
 	}
 
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 func PrintHelp() {
-	fmt.Println()
-	cyan.Println("🆘 Shell Agent Help & Commands")
-	cyan.Println("===============================")
-	fmt.Println()
-
-	boldGreen.Println("📝 Built-in Commands:")
-	green.Println("  help, h     - Show this help message")
-	green.Println("  status      - Show current model status")
-	green.Println("  clear, cls  - Clear the screen")
-	green.Println("  exit, quit, q - Exit shell agent")
-	fmt.Println()
-
-	boldGreen.Println("💬 Example Natural Language Requests:")
-	green.Println("  • 'list all files in current directory'")
-	green.Println("  • 'find all .py files modified in the last 7 days'")
-	green.Println("  • 'create a backup of my documents folder'")
-	green.Println("  • 'show disk usage of current directory'")
-	green.Println("  • 'compress this folder into a tar.gz file'")
-	green.Println("  • 'find files larger than 100MB'")
-	green.Println("  • 'show running processes using port 8080'")
-	fmt.Println()
-
-	boldGreen.Println("🎯 Tips for Better Results:")
-	green.Println("  • Be specific about what you want to accomplish")
-	green.Println("  • Mention file types, directories, or specific criteria")
-	green.Println("  • Ask for explanations if you're unsure about a command")
-	fmt.Println()
+	w := writerFor(CategoryStatus)
+	fmt.Fprintln(w)
+	cyan.Fprintln(w, "🆘 Shell Agent Help & Commands")
+	cyan.Fprintln(w, "===============================")
+	fmt.Fprintln(w)
+
+	boldGreen.Fprintln(w, "📝 Built-in Commands:")
+	green.Fprintln(w, "  help, h     - Show this help message")
+	green.Fprintln(w, "  status      - Show current model status")
+	green.Fprintln(w, "  mode [name] - Show or switch mode (shell, explain, review, function)")
+	green.Fprintln(w, "  session save|load <name> - Save or restore the conversation")
+	green.Fprintln(w, "  session list - List saved sessions")
+	green.Fprintln(w, "  reset       - Forget the conversation context")
+	green.Fprintln(w, "  why         - Ask why the last command was chosen")
+	green.Fprintln(w, "  preview     - Show what the last command would affect, without running it")
+	green.Fprintln(w, "  clear, cls  - Clear the screen")
+	green.Fprintln(w, "  exit, quit, q - Exit shell agent")
+	fmt.Fprintln(w)
+
+	boldGreen.Fprintln(w, "💬 Example Natural Language Requests:")
+	for _, example := range HelpExamples() {
+		green.Fprintf(w, "  • '%s' → %s\n", example.Request, example.Command)
+	}
+	fmt.Fprintln(w)
+
+	boldGreen.Fprintln(w, "🎯 Tips for Better Results:")
+	green.Fprintln(w, "  • Be specific about what you want to accomplish")
+	green.Fprintln(w, "  • Mention file types, directories, or specific criteria")
+	green.Fprintln(w, "  • Ask for explanations if you're unsure about a command")
+	green.Fprintln(w, "  • Refine the last command with a short follow-up, e.g. 'same but exclude node_modules'")
+	fmt.Fprintln(w)
 }
 
 func ClearScreen() {
@@ -250,7 +395,14 @@ func ClearScreen() {
 	cmd.Run()
 }
 
+// PromptExecuteCommand asks whether to run the command. Without a terminal to
+// answer on, the command is declined.
 func PromptExecuteCommand() bool {
+	if !isTerminal() {
+		PrintWarning("Not executing: confirmation needs a terminal")
+		return false
+	}
+
 	prompt := promptui.Prompt{
 		Label:     "Execute this command",
 		IsConfirm: true,
@@ -265,14 +417,102 @@ func PromptExecuteCommand() bool {
 	return strings.ToLower(result) == "y"
 }
 
+// PlaceholderFill asks for a value for each placeholder of a templated command.
+// An empty answer is allowed, since a placeholder may be optional; answers with
+// spaces are asked again. Without a terminal there is no one to ask, so it
+// returns ErrNoTerminal.
+func PlaceholderFill(names []string) (map[string]string, error) {
+	if !isTerminal() {
+		return nil, ErrNoTerminal
+	}
+
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		prompt := promptui.Prompt{
+			Label:    fmt.Sprintf("Value for {{%s}}", name),
+			Validate: ai.CheckPlaceholderValue,
+		}
+
+		value, err := prompt.Run()
+		if err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// PromptRegenerateSimpler asks whether to regenerate a command that is over
+// the complexity budget. Without a terminal the command is kept.
+func PromptRegenerateSimpler() bool {
+	if !isTerminal() {
+		return false
+	}
+
+	prompt := promptui.Prompt{
+		Label:     "Regenerate with a simpler, multi-step approach",
+		IsConfirm: true,
+		Default:   "n",
+	}
+
+	result, err := prompt.Run()
+	if err != nil {
+		return false
+	}
+
+	return strings.ToLower(result) == "y"
+}
+
+// PromptRiskConfirmation asks the user to type "yes" to run a command whose
+// risk score is above threshold. Without a terminal the command is declined.
+func PromptRiskConfirmation(score, threshold int) bool {
+	if !isTerminal() {
+		PrintWarning(fmt.Sprintf("Not executing: risk score %d is above %d and confirmation needs a terminal", score, threshold))
+		return false
+	}
+
+	prompt := promptui.Prompt{
+		Label: fmt.Sprintf("Risk score %d is above %d. Type 'yes' to run it anyway", score, threshold),
+	}
+
+	result, err := prompt.Run()
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(strings.TrimSpace(result), "yes")
+}
+
 func ExecuteCommand(command string) error {
+	cmd, err := buildCommand(command)
+	if err != nil {
+		return err
+	}
+
+	// Connect the command's standard input, output, and error streams
+	// to the current process's streams so you can see the output in real-time.
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+
+	// Run the command and return any error that occurs.
+	// This includes cases where the command exits with a non-zero status.
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to execute command '%s': %w", command, err)
+	}
+
+	return nil
+}
+
+// buildCommand prepares command to run without connecting its streams
+func buildCommand(command string) (*exec.Cmd, error) {
 	var cmd *exec.Cmd
 
 	// Split the command string into the command name and its arguments.
 	// This helps prevent command injection vulnerabilities.
 	parts := strings.Fields(command)
 	if len(parts) == 0 {
-		return fmt.Errorf("command string is empty")
+		return nil, fmt.Errorf("command string is empty")
 	}
 	name := parts[0]
 	args := parts[1:]
@@ -287,39 +527,165 @@ func ExecuteCommand(command string) error {
 		cmd = exec.Command(name, args...)
 	}
 
-	// Connect the command's standard input, output, and error streams
-	// to the current process's streams so you can see the output in real-time.
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-
-	// Run the command and return any error that occurs.
-	// This includes cases where the command exits with a non-zero status.
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to execute command '%s': %w", command, err)
-	}
-
-	return nil
+	return cmd, nil
 }
 
 func PrintAvailableModels(models []ai.ModelInfo) {
-	fmt.Println()
-	cyan.Println("📋 Available AI Models")
-	cyan.Println("======================")
-	fmt.Println()
+	w := writerFor(CategoryStatus)
+	fmt.Fprintln(w)
+	cyan.Fprintln(w, "📋 Available AI Models")
+	cyan.Fprintln(w, "======================")
+	fmt.Fprintln(w)
 
 	for _, model := range models {
 		if model.Downloaded {
-			green.Printf("✅ %s - %s (Downloaded)\n", model.Name, model.Description)
+			green.Fprintf(w, "✅ %s - %s (Downloaded)\n", model.Name, model.Description)
 		} else {
-			fmt.Printf("⬜ %s - %s (Available for download)\n", model.Name, model.Description)
+			fmt.Fprintf(w, "⬜ %s - %s (Available for download)\n", model.Name, model.Description)
+		}
+		fmt.Fprintf(w, "   📦 Size: %s | 🏷️  Type: %s\n", model.Size, model.Type)
+		fmt.Fprintln(w)
+	}
+}
+
+// PrintParseEvaluation renders the JSON-parse success rate per model
+func PrintParseEvaluation(results []ai.ParseEvaluation) {
+	w := writerFor(CategoryStatus)
+	fmt.Fprintln(w)
+	cyan.Fprintln(w, "🧪 JSON Parse Evaluation")
+	cyan.Fprintln(w, "========================")
+	fmt.Fprintln(w)
+
+	for _, result := range results {
+		rate := result.SuccessRate()
+		rateColor := green
+		if rate < 0.6 {
+			rateColor = red
+		} else if rate < 0.9 {
+			rateColor = yellow
 		}
-		fmt.Printf("   📦 Size: %s | 🏷️  Type: %s\n", model.Size, model.Type)
-		fmt.Println()
+
+		boldGreen.Fprintf(w, "🤖 %s\n", result.Model)
+		rateColor.Fprintf(w, "   ✅ Parsed: %d/%d (%.0f%%)\n", result.Parsed, result.Total, rate*100)
+		fmt.Fprintf(w, "   🔁 Fallbacks: %d | ❌ Errors: %d\n", result.Fallbacks, result.Errors)
+		fmt.Fprintf(w, "   📈 Avg Confidence: %.0f%%\n", result.AvgConfidence*100)
+		fmt.Fprintln(w)
 	}
 }
 
+// PrintPromptPreview shows the system prompt and, when given, the prompt that
+// would be sent for a request. The prompts themselves go to stdout unstyled.
+func PrintPromptPreview(systemPrompt, enhancedPrompt string) {
+	w := writerFor(CategoryStatus)
+	cyan.Fprintln(w, "🧾 System Prompt")
+	cyan.Fprintln(w, "================")
+	fmt.Fprintln(stdout, strings.TrimRight(systemPrompt, "\n"))
+
+	if enhancedPrompt == "" {
+		return
+	}
+	fmt.Fprintln(w)
+	cyan.Fprintln(w, "📨 Request Prompt")
+	cyan.Fprintln(w, "=================")
+	fmt.Fprintln(stdout, strings.TrimRight(enhancedPrompt, "\n"))
+}
+
+// PrintFlagExplanations renders the meaning of each flag as a two-column table
+func PrintFlagExplanations(flags []ai.FlagExplanation) {
+	w := writerFor(CategoryExplanation)
+
+	width := len("Flag")
+	for _, flag := range flags {
+		if len(flag.Flag) > width {
+			width = len(flag.Flag)
+		}
+	}
+
+	fmt.Fprintln(w)
+	boldGreen.Fprintln(w, "🏳️  Flags:")
+	white.Fprintf(w, "   %-*s  %s\n", width, "Flag", "Meaning")
+	for _, flag := range flags {
+		fmt.Fprintf(w, "   ")
+		cyan.Fprintf(w, "%-*s", width, flag.Flag)
+		fmt.Fprintf(w, "  %s\n", flag.Meaning)
+	}
+}
+
+// maxPreviewItems caps how many affected paths a preview lists
+const maxPreviewItems = 20
+
+// PrintPreview shows the read-only equivalent of a destructive command and the paths it would affect
+func PrintPreview(preview string, matched []string) {
+	w := writerFor(CategoryExplanation)
+	fmt.Fprintln(w)
+	boldGreen.Fprintln(w, "🔎 Preview (read-only):")
+	cyan.Fprintf(w, "   %s\n", preview)
+
+	if len(matched) == 0 {
+		green.Fprintln(w, "   Nothing would be affected")
+		return
+	}
+
+	yellow.Fprintf(w, "   %d path(s) would be affected:\n", len(matched))
+	for i, path := range matched {
+		if i == maxPreviewItems {
+			fmt.Fprintf(w, "   ... and %d more\n", len(matched)-maxPreviewItems)
+			break
+		}
+		fmt.Fprintf(w, "   • %s\n", path)
+	}
+}
+
+// candidateLabel describes a candidate for the candidate list and selection menu
+func candidateLabel(candidate ai.Candidate) string {
+	label := fmt.Sprintf("%s (%.0f%%)", candidate.Command, candidate.Confidence*100)
+	if candidate.Dangerous {
+		label += " ⚠️ dangerous"
+	}
+	return label
+}
+
+// PromptCandidateSelection lets the user pick one of the ranked candidates.
+// The top-ranked candidate is preselected.
+func PromptCandidateSelection(candidates []ai.Candidate) (string, error) {
+	// Without a terminal the best candidate stands
+	if !isTerminal() {
+		return candidates[0].Command, nil
+	}
+
+	items := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		items[i] = candidateLabel(candidate)
+	}
+
+	prompt := promptui.Select{
+		Label:     "Select a command",
+		Items:     items,
+		Size:      len(items),
+		CursorPos: 0,
+		Templates: &promptui.SelectTemplates{
+			Label:    "{{ . }}:",
+			Active:   "▶ {{ . | cyan }}",
+			Inactive: "  {{ . }}",
+			Selected: "✅ Selected: {{ . | green }}",
+		},
+	}
+
+	index, _, err := prompt.Run()
+	if err != nil {
+		return "", err
+	}
+
+	return candidates[index].Command, nil
+}
+
+// PromptModelSelection lets the user pick a model to download. It returns
+// ErrNoTerminal when there is no terminal to choose on.
 func PromptModelSelection(models []ai.ModelInfo) (string, error) {
+	if !isTerminal() {
+		return "", ErrNoTerminal
+	}
+
 	items := make([]string, len(models))
 	for i, model := range models {
 		status := "Available"
@@ -349,39 +715,62 @@ func PromptModelSelection(models []ai.ModelInfo) (string, error) {
 	return models[index].Name, nil
 }
 
+// PrintWaitingDot extends a "waiting" line by one dot, printing the message on the first attempt
+func PrintWaitingDot(message string, attempt int) {
+	w := writerFor(CategoryStatus)
+	if attempt == 1 {
+		cyan.Fprint(w, message)
+	}
+	cyan.Fprint(w, ".")
+}
+
+// PrintWaitingDone ends a line started by PrintWaitingDot
+func PrintWaitingDone() {
+	fmt.Fprintln(writerFor(CategoryStatus))
+}
+
 func PrintSetupWelcome() {
-	fmt.Println()
-	cyan.Println("🚀 Shell Agent Setup")
-	cyan.Println("====================")
-	fmt.Println()
-	green.Println("This setup will:")
-	green.Println("  ✅ Install Ollama (if needed)")
-	green.Println("  ✅ Start Ollama service")
-	green.Println("  ✅ Download a recommended AI model")
-	green.Println("  ✅ Create default configuration")
-	fmt.Println()
+	w := writerFor(CategoryStatus)
+	fmt.Fprintln(w)
+	cyan.Fprintln(w, "🚀 Shell Agent Setup")
+	cyan.Fprintln(w, "====================")
+	fmt.Fprintln(w)
+	green.Fprintln(w, "This setup will:")
+	green.Fprintln(w, "  ✅ Install Ollama (if needed)")
+	green.Fprintln(w, "  ✅ Start Ollama service")
+	green.Fprintln(w, "  ✅ Download a recommended AI model")
+	green.Fprintln(w, "  ✅ Create default configuration")
+	fmt.Fprintln(w)
 }
 
 func PrintSetupComplete() {
-	fmt.Println()
-	boldGreen.Println("🎉 Setup Complete!")
-	boldGreen.Println("==================")
-	fmt.Println()
-	green.Println("Shell Agent is now ready to use!")
-	fmt.Println()
-	boldGreen.Println("Quick Start:")
-	green.Println("  shell-agent                    # Start interactive mode")
-	green.Println("  shell-agent status            # Check system status")
-	green.Println("  shell-agent \"list files\"      # Generate a command")
-	fmt.Println()
-	green.Println("💡 Tips:")
-	green.Println("  • Be specific in your requests")
-	green.Println("  • Always review commands before executing")
-	green.Println("  • Use 'help' for assistance in interactive mode")
-	fmt.Println()
+	w := writerFor(CategoryStatus)
+	fmt.Fprintln(w)
+	boldGreen.Fprintln(w, "🎉 Setup Complete!")
+	boldGreen.Fprintln(w, "==================")
+	fmt.Fprintln(w)
+	green.Fprintln(w, "Shell Agent is now ready to use!")
+	fmt.Fprintln(w)
+	boldGreen.Fprintln(w, "Quick Start:")
+	green.Fprintln(w, "  shell-agent                    # Start interactive mode")
+	green.Fprintln(w, "  shell-agent status            # Check system status")
+	green.Fprintln(w, "  shell-agent \"list files\"      # Generate a command")
+	fmt.Fprintln(w)
+	green.Fprintln(w, "💡 Tips:")
+	green.Fprintln(w, "  • Be specific in your requests")
+	green.Fprintln(w, "  • Always review commands before executing")
+	green.Fprintln(w, "  • Use 'help' for assistance in interactive mode")
+	fmt.Fprintln(w)
 }
 
+// PromptSetupConfirm asks whether to continue with setup. Without a terminal
+// setup does not continue.
 func PromptSetupConfirm() bool {
+	if !isTerminal() {
+		PrintWarning("Setup needs a terminal to confirm")
+		return false
+	}
+
 	prompt := promptui.Prompt{
 		Label:     "Continue with setup",
 		IsConfirm: true,
@@ -397,9 +786,9 @@ func PromptSetupConfirm() bool {
 }
 
 // streamString prints a string character by character with a delay.
-func streamString(text string, c *color.Color, delay time.Duration) {
+func streamString(w io.Writer, text string, c *color.Color, delay time.Duration) {
 	for _, char := range text {
-		c.Fprint(os.Stdout, string(char))
+		c.Fprint(w, string(char))
 		time.Sleep(delay)
 	}
 }
@@ -0,0 +1,102 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// interactivePrograms take over the terminal or read answers from the user, so
+// their output must reach the terminal rather than a pager
+var interactivePrograms = map[string]bool{
+	"top": true, "htop": true, "btop": true, "watch": true, "less": true, "more": true, "most": true, "man": true,
+	"vi": true, "vim": true, "nvim": true, "nano": true, "emacs": true, "tmux": true, "screen": true, "fzf": true,
+	"ssh": true, "telnet": true, "ftp": true, "sftp": true, "mysql": true, "psql": true, "sqlite3": true,
+	"sh": true, "bash": true, "zsh": true, "fish": true, "python": true, "python3": true, "node": true, "irb": true,
+}
+
+// IsInteractive reports whether command runs a program that needs the terminal,
+// such as an editor, a pager, a monitor like top, or a REPL. sudo is skipped.
+func IsInteractive(command string) bool {
+	fields := strings.Fields(command)
+	for len(fields) > 1 && fields[0] == "sudo" {
+		fields = fields[1:]
+	}
+	return len(fields) > 0 && interactivePrograms[filepath.Base(fields[0])]
+}
+
+// terminalHeight returns the number of rows of the terminal on stdout, or 0
+// when stdout is not a terminal
+func terminalHeight() int {
+	_, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0
+	}
+	return height
+}
+
+// pagerCommand returns $PAGER, or less or more when installed. It returns nil
+// when there is no pager to use.
+func pagerCommand() []string {
+	if pager := strings.Fields(os.Getenv("PAGER")); len(pager) > 0 {
+		return pager
+	}
+	if _, err := exec.LookPath("less"); err == nil {
+		// -R keeps colors, -F exits at once if the output fits after all
+		return []string{"less", "-RF"}
+	}
+	if _, err := exec.LookPath("more"); err == nil {
+		return []string{"more"}
+	}
+	return nil
+}
+
+// ExecuteCommandPaged runs command like ExecuteCommand, but streams its standard
+// output into a pager, which shows output that fits the terminal as is. The
+// command runs unpaged when stdout is not a terminal, no pager is available, or
+// the command is interactive. Standard error is not paged, so errors still
+// appear as they happen.
+func ExecuteCommandPaged(command string) error {
+	pager := pagerCommand()
+	if pager == nil || terminalHeight() == 0 || IsInteractive(command) {
+		return ExecuteCommand(command)
+	}
+
+	cmd, err := buildCommand(command)
+	if err != nil {
+		return err
+	}
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return ExecuteCommand(command)
+	}
+
+	pagerCmd := exec.Command(pager[0], pager[1:]...)
+	pagerCmd.Stdin = reader
+	pagerCmd.Stdout = os.Stdout
+	pagerCmd.Stderr = os.Stderr
+	if err := pagerCmd.Start(); err != nil {
+		// A pager that can't start must not swallow the output
+		reader.Close()
+		writer.Close()
+		return ExecuteCommand(command)
+	}
+	reader.Close()
+
+	// The pager reads keys from the terminal, so the command gets no stdin
+	cmd.Stdout = writer
+	cmd.Stderr = os.Stderr
+	runErr := cmd.Run()
+	writer.Close()
+	pagerCmd.Wait()
+
+	if runErr != nil {
+		return fmt.Errorf("failed to execute command '%s': %w", command, runErr)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+)
+
+// PlanSchemaVersion is the version of the Plan JSON contract. It changes
+// whenever a field is removed or changes meaning; new fields may be added
+// without a change.
+const PlanSchemaVersion = 1
+
+// Plan is the machine-readable result of a generation, printed by --emit plan
+// for editors and other tools to build on. Every top-level field is always
+// present, and lists are empty rather than null:
+//
+//	schema_version  int       PlanSchemaVersion
+//	request         string    the request as given
+//	model           string    the model that produced the answer
+//	mode            string    shell, explain, review or function
+//	command         string    the full command; empty when clarification is set
+//	explanation     string
+//	steps           []Step    {command, explanation (optional)} for each step to run
+//	alternatives    []string
+//	findings        []Finding {rule, severity, message, pattern (optional)}; severity is warning or critical
+//	risk_score      int       0 to 100
+//	confidence      float     0 to 1
+//	clarification   string    a question the model asked instead of answering
+//	timing          Timing    {started_at (RFC 3339), duration_ms}
+type Plan struct {
+	SchemaVersion int          `json:"schema_version"`
+	Request       string       `json:"request"`
+	Model         string       `json:"model"`
+	Mode          ai.Mode      `json:"mode"`
+	Command       string       `json:"command"`
+	Explanation   string       `json:"explanation"`
+	Steps         []ai.Step    `json:"steps"`
+	Alternatives  []string     `json:"alternatives"`
+	Findings      []ai.Finding `json:"findings"`
+	RiskScore     int          `json:"risk_score"`
+	Confidence    float64      `json:"confidence"`
+	Clarification string       `json:"clarification"`
+	Timing        PlanTiming   `json:"timing"`
+}
+
+// PlanTiming records when a generation started and how long it took
+type PlanTiming struct {
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int64     `json:"duration_ms"`
+}
+
+// BuildPlan describes a generated response as a Plan
+func BuildPlan(request string, response *ai.CommandResponse, started time.Time, elapsed time.Duration) *Plan {
+	plan := &Plan{
+		SchemaVersion: PlanSchemaVersion,
+		Request:       request,
+		Model:         response.Model,
+		Mode:          response.Mode,
+		Command:       response.Command,
+		Explanation:   response.Explanation,
+		Steps:         response.ScriptSteps(),
+		Alternatives:  response.Alternatives,
+		Findings:      response.Findings,
+		RiskScore:     response.RiskScore,
+		Confidence:    response.Confidence,
+		Clarification: response.Clarification,
+		Timing: PlanTiming{
+			StartedAt:  started.UTC(),
+			DurationMS: elapsed.Milliseconds(),
+		},
+	}
+
+	if plan.Steps == nil {
+		plan.Steps = []ai.Step{}
+	}
+	if plan.Alternatives == nil {
+		plan.Alternatives = []string{}
+	}
+	if plan.Findings == nil {
+		plan.Findings = []ai.Finding{}
+	}
+	return plan
+}
+
+// PrintPlanJSON writes the plan as indented JSON to stdout
+func PrintPlanJSON(plan *Plan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+	_, err = fmt.Fprintln(stdout, string(data))
+	return err
+}
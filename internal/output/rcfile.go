@@ -0,0 +1,51 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kodelint/shell-agent/internal/shutdown"
+)
+
+// expandHome expands a path of ~ or ~/... to the home directory. Other paths,
+// including ~user/..., are returned unchanged.
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// AppendFunction appends a generated shell function to an rc file, preceded by
+// a comment naming the request it was generated for. A leading ~/ is expanded.
+func AppendFunction(path, request, body string) error {
+	defer shutdown.Begin()()
+
+	path, err := expandHome(path)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	header := "# Added by shell-agent"
+	if request = strings.Join(strings.Fields(request), " "); request != "" {
+		header += ": " + request
+	}
+
+	if _, err := fmt.Fprintf(file, "\n%s\n%s\n", header, strings.TrimSpace(body)); err != nil {
+		return fmt.Errorf("failed to write to %s: %w", path, err)
+	}
+	return nil
+}
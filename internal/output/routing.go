@@ -0,0 +1,95 @@
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/fatih/color"
+)
+
+// Category groups messages so they can be routed to stdout or stderr independently
+type Category string
+
+const (
+	// CategoryCommand is the generated command itself
+	CategoryCommand Category = "command"
+	// CategoryExplanation covers explanations, reviews and clarifying questions
+	CategoryExplanation Category = "explanation"
+	// CategoryWarning covers warnings and errors
+	CategoryWarning Category = "warning"
+	// CategoryStatus covers everything else: banners, progress, info and confidence
+	CategoryStatus Category = "status"
+)
+
+// Categories lists all routable message categories
+var Categories = []Category{CategoryCommand, CategoryExplanation, CategoryWarning, CategoryStatus}
+
+const (
+	streamStdout = "stdout"
+	streamStderr = "stderr"
+)
+
+var (
+	// stdout and stderr default to color-aware writers that handle ANSI colors on Windows
+	stdout io.Writer = color.Output
+	stderr io.Writer = color.Error
+
+	// routes maps each category to a stream; everything goes to stdout by default
+	routes = defaultRoutes()
+)
+
+func defaultRoutes() map[Category]string {
+	r := make(map[Category]string, len(Categories))
+	for _, category := range Categories {
+		r[category] = streamStdout
+	}
+	return r
+}
+
+// SetOutput redirects messages routed to stdout to w; nil restores the default
+func SetOutput(w io.Writer) {
+	if w == nil {
+		w = color.Output
+	}
+	stdout = w
+}
+
+// SetErrorOutput redirects messages routed to stderr to w; nil restores the default
+func SetErrorOutput(w io.Writer) {
+	if w == nil {
+		w = color.Error
+	}
+	stderr = w
+}
+
+// SetRoute sends a message category to "stdout" or "stderr"
+func SetRoute(category Category, stream string) error {
+	if _, ok := routes[category]; !ok {
+		return fmt.Errorf("unknown output category %q: use command, explanation, warning or status", category)
+	}
+	if stream != streamStdout && stream != streamStderr {
+		return fmt.Errorf("invalid stream %q for %s: use stdout or stderr", stream, category)
+	}
+
+	routes[category] = stream
+	return nil
+}
+
+// ResetRoutes restores the default routing of everything to stdout
+func ResetRoutes() {
+	routes = defaultRoutes()
+}
+
+// writerFor returns the writer a category is routed to
+func writerFor(category Category) io.Writer {
+	if routes[category] == streamStderr {
+		return stderr
+	}
+	return stdout
+}
+
+// commandRoutedSeparately reports whether the command goes to a different stream than
+// status messages, in which case it is printed bare so it can be captured cleanly
+func commandRoutedSeparately() bool {
+	return routes[CategoryCommand] != routes[CategoryStatus]
+}
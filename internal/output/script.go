@@ -0,0 +1,87 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/shutdown"
+)
+
+// ScriptOptions control the header of a generated script
+type ScriptOptions struct {
+	Shebang string
+	// StrictMode is written after the header, e.g. "set -euo pipefail"; empty omits it
+	StrictMode string
+}
+
+// RenderScript turns a response into a runnable script: the shebang, comments
+// naming the request and any warnings, the strict-mode line, then each step
+// preceded by a comment
+func RenderScript(request string, response *ai.CommandResponse, opts ScriptOptions) string {
+	var b strings.Builder
+
+	if opts.Shebang != "" {
+		fmt.Fprintln(&b, opts.Shebang)
+	}
+	if request = strings.Join(strings.Fields(request), " "); request != "" {
+		fmt.Fprintf(&b, "# Generated by shell-agent: %s\n", request)
+	}
+	for _, line := range commentLines(response.Explanation) {
+		fmt.Fprintf(&b, "# %s\n", line)
+	}
+	for _, line := range commentLines(response.Warning) {
+		fmt.Fprintf(&b, "# WARNING: %s\n", line)
+	}
+	if opts.StrictMode != "" {
+		fmt.Fprintf(&b, "\n%s\n", opts.StrictMode)
+	}
+
+	steps := response.ScriptSteps()
+	for i, step := range steps {
+		comment := fmt.Sprintf("Step %d", i+1)
+		if explanation := strings.Join(commentLines(step.Explanation), " "); explanation != "" && len(steps) > 1 {
+			comment += ": " + explanation
+		}
+		fmt.Fprintf(&b, "\n# %s\n%s\n", comment, step.Command)
+	}
+
+	return b.String()
+}
+
+// commentLines splits text into trimmed, non-empty lines
+func commentLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// PrintScript writes a rendered script to the command stream
+func PrintScript(script string) {
+	fmt.Fprint(writerFor(CategoryCommand), script)
+}
+
+// WriteScript writes a rendered script to path and makes it executable. A
+// leading ~/ is expanded.
+func WriteScript(path, script string) error {
+	defer shutdown.Begin()()
+
+	path, err := expandHome(path)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		return fmt.Errorf("failed to write script to %s: %w", path, err)
+	}
+	// WriteFile keeps the mode of an existing file
+	if err := os.Chmod(path, 0755); err != nil {
+		return fmt.Errorf("failed to make %s executable: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/config"
+	"github.com/kodelint/shell-agent/internal/feedback"
+	"github.com/kodelint/shell-agent/internal/system"
+)
+
+// statusProbeTimeout bounds how long status waits for Ollama to answer
+const statusProbeTimeout = 3 * time.Second
+
+// ModelStatus describes the configured model
+type ModelStatus struct {
+	Name        string `json:"name"`
+	OllamaName  string `json:"ollama_name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Size        string `json:"size,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Downloaded  bool   `json:"downloaded"`
+	Path        string `json:"path"`
+}
+
+// OllamaStatus reports whether the Ollama service answered
+type OllamaStatus struct {
+	URL       string `json:"url"`
+	Available bool   `json:"available"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Status is a snapshot of shell-agent's state, rendered as text by PrintStatus
+// or as JSON by PrintStatusJSON
+type Status struct {
+	// Model is nil when no model is configured
+	Model         *ModelStatus `json:"model"`
+	Ollama        OllamaStatus `json:"ollama"`
+	System        *system.Info `json:"system"`
+	FeedbackCount int          `json:"feedback_count"`
+}
+
+// BuildStatus gathers the model, Ollama, system and feedback status
+func BuildStatus() *Status {
+	modelManager := ai.NewModelManager()
+	status := &Status{
+		System: system.NewSystemInfo().GetInfo(),
+	}
+
+	if currentModel := modelManager.GetCurrentModel(); currentModel != nil {
+		status.Model = &ModelStatus{
+			Name:        currentModel.Name,
+			OllamaName:  currentModel.OllamaName,
+			Description: currentModel.Description,
+			Size:        currentModel.Size,
+			Type:        currentModel.Type,
+			Downloaded:  currentModel.Downloaded,
+			Path:        modelManager.GetModelPath(),
+		}
+	}
+
+	if cfg, err := config.Load(); err != nil {
+		status.Ollama.Error = err.Error()
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), statusProbeTimeout)
+		defer cancel()
+
+		status.Ollama.URL = cfg.AI.Ollama.BaseURL
+		if err := ai.NewOllamaClient(cfg).IsAvailable(ctx); err != nil {
+			status.Ollama.Error = err.Error()
+		} else {
+			status.Ollama.Available = true
+		}
+	}
+
+	if manager, err := feedback.NewManager(); err == nil {
+		if entries, err := manager.LoadFeedback(); err == nil {
+			status.FeedbackCount = len(entries)
+		}
+	}
+
+	return status
+}
+
+// PrintStatusJSON writes the status as indented JSON to stdout
+func PrintStatusJSON(status *Status) error {
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode status: %w", err)
+	}
+	_, err = fmt.Fprintln(stdout, string(data))
+	return err
+}
@@ -0,0 +1,37 @@
+package output
+
+import (
+	"errors"
+	"os"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// ErrNoTerminal is returned by prompts that have no safe default when stdin is
+// not a terminal, e.g. in CI or when input is piped
+var ErrNoTerminal = errors.New("stdin is not a terminal")
+
+var (
+	terminalMu sync.Mutex
+	terminal   func() bool
+)
+
+// SetTerminalCheck replaces how prompts detect an interactive terminal, e.g. to
+// simulate piped input in tests. A nil check restores the stdin check.
+func SetTerminalCheck(fn func() bool) {
+	terminalMu.Lock()
+	defer terminalMu.Unlock()
+	terminal = fn
+}
+
+// isTerminal reports whether prompts can read answers from the user
+func isTerminal() bool {
+	terminalMu.Lock()
+	check := terminal
+	terminalMu.Unlock()
+	if check == nil {
+		return term.IsTerminal(int(os.Stdin.Fd()))
+	}
+	return check()
+}
@@ -0,0 +1,145 @@
+// Package report builds reproduction bundles for bug reports: the request, the
+// exact exchange with the model, the parsed result and the setup they ran in.
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/config"
+	"github.com/kodelint/shell-agent/internal/output"
+	"github.com/kodelint/shell-agent/internal/shutdown"
+	"github.com/kodelint/shell-agent/internal/store"
+)
+
+// ErrNoLastRequest is returned by LoadLast before any command has been generated
+var ErrNoLastRequest = errors.New("no request recorded yet; generate a command first")
+
+// Redacted replaces the value of secret settings in a bundle
+const Redacted = "[REDACTED]"
+
+// secretWords mark a config key as holding a secret, e.g. ai.openai.api_key
+var secretWords = []string{"api_key", "apikey", "token", "secret", "password"}
+
+// LastRequest is the most recent generation, saved so it can be reported later
+type LastRequest struct {
+	Timestamp time.Time `json:"timestamp"`
+	Request   string    `json:"request"`
+	// Trace is nil when the provider did not record the exchange
+	Trace  *ai.Trace           `json:"trace,omitempty"`
+	Result *ai.CommandResponse `json:"result"`
+}
+
+// Bundle is everything a maintainer needs to reproduce a bad command
+type Bundle struct {
+	CreatedAt   time.Time      `json:"created_at"`
+	Version     string         `json:"version"`
+	Config      map[string]any `json:"config"`
+	Status      *output.Status `json:"status"`
+	LastRequest *LastRequest   `json:"last_request"`
+}
+
+// lastRequestPath is where the last request is kept, in the data dir
+func lastRequestPath() string {
+	return filepath.Join(config.GetDataDir(), "last-request.json")
+}
+
+// SaveLast replaces the saved last request
+func SaveLast(last LastRequest) error {
+	defer shutdown.Begin()()
+
+	if err := os.MkdirAll(config.GetDataDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(last, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal last request: %w", err)
+	}
+
+	if err := store.WriteFileAtomic(lastRequestPath(), data); err != nil {
+		return fmt.Errorf("failed to write last request: %w", err)
+	}
+	return nil
+}
+
+// LoadLast returns the saved last request
+func LoadLast() (*LastRequest, error) {
+	data, err := os.ReadFile(lastRequestPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoLastRequest
+		}
+		return nil, fmt.Errorf("failed to read last request: %w", err)
+	}
+
+	var last LastRequest
+	if err := json.Unmarshal(data, &last); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal last request: %w", err)
+	}
+	return &last, nil
+}
+
+// Build assembles a bundle from the resolved settings, with secrets redacted
+func Build(version string, settings map[string]any, status *output.Status, last *LastRequest) *Bundle {
+	return &Bundle{
+		CreatedAt:   time.Now(),
+		Version:     version,
+		Config:      Redact(settings),
+		Status:      status,
+		LastRequest: last,
+	}
+}
+
+// Redact returns a copy of settings with every non-empty secret replaced by
+// Redacted. Nested sections are copied, so settings is left unchanged.
+func Redact(settings map[string]any) map[string]any {
+	redacted := make(map[string]any, len(settings))
+	for key, value := range settings {
+		switch v := value.(type) {
+		case map[string]any:
+			redacted[key] = Redact(v)
+		case string:
+			if v != "" && isSecret(key) {
+				value = Redacted
+			}
+			redacted[key] = value
+		default:
+			redacted[key] = value
+		}
+	}
+	return redacted
+}
+
+// isSecret reports whether a config key holds a secret
+func isSecret(key string) bool {
+	key = strings.ToLower(key)
+	for _, word := range secretWords {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// Write saves the bundle as JSON in dir and returns its path. The file is only
+// readable by the user, since prompts may mention private paths.
+func Write(dir string, bundle *Bundle) (string, error) {
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	name := fmt.Sprintf("shell-agent-report-%s.json", bundle.CreatedAt.Format("20060102-150405"))
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return "", fmt.Errorf("failed to write report: %w", err)
+	}
+	return path, nil
+}
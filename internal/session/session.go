@@ -0,0 +1,144 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/config"
+	"github.com/kodelint/shell-agent/internal/logger"
+	"github.com/kodelint/shell-agent/internal/shutdown"
+	"github.com/kodelint/shell-agent/internal/store"
+	"github.com/sirupsen/logrus"
+)
+
+// validName restricts session names to safe file names
+var validName = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// Session is a saved conversation that can be reloaded later
+type Session struct {
+	Name     string       `json:"name"`
+	SavedAt  time.Time    `json:"saved_at"`
+	Messages []ai.Message `json:"messages"`
+}
+
+// Store saves and loads sessions as JSON files
+type Store struct {
+	dir    string
+	logger *logrus.Entry
+}
+
+// NewStore creates a Store in the sessions directory under the data dir
+func NewStore() (*Store, error) {
+	dir := filepath.Join(config.GetDataDir(), "sessions")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+
+	return &Store{
+		dir:    dir,
+		logger: logger.GetLogger().WithField("component", "session-store"),
+	}, nil
+}
+
+// Save writes the messages under the given name, replacing any existing session
+func (s *Store) Save(name string, messages []ai.Message) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+
+	defer shutdown.Begin()()
+
+	data, err := json.MarshalIndent(Session{
+		Name:     name,
+		SavedAt:  time.Now(),
+		Messages: messages,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	if err := store.WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"session":  name,
+		"messages": len(messages),
+	}).Info("Session saved")
+
+	return nil
+}
+
+// Load reads the named session
+func (s *Store) Load(name string) (*Session, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("session %q not found", name)
+		}
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to parse session %q: %w", name, err)
+	}
+
+	return &session, nil
+}
+
+// List returns the names of all saved sessions, sorted
+func (s *Store) List() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// Delete removes the named session
+func (s *Store) Delete(name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("session %q not found", name)
+		}
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// path returns the file for a session, rejecting names that could escape the directory
+func (s *Store) path(name string) (string, error) {
+	if !validName.MatchString(name) {
+		return "", fmt.Errorf("invalid session name %q: use letters, digits, '.', '_' or '-'", name)
+	}
+	return filepath.Join(s.dir, name+".json"), nil
+}
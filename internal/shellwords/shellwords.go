@@ -0,0 +1,131 @@
+// Package shellwords splits command lines into arguments the way a POSIX
+// shell would, for inspecting commands without running them. Execution never
+// goes through this package.
+package shellwords
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrUnbalancedQuote is returned when a quote is opened but never closed
+	ErrUnbalancedQuote = errors.New("unbalanced quote")
+	// ErrTrailingEscape is returned when a command ends with a lone backslash
+	ErrTrailingEscape = errors.New("trailing backslash")
+)
+
+// SplitCommand splits a command into words, honoring single quotes, double
+// quotes and backslash escapes. Quotes are removed from the result and an
+// empty quoted string yields an empty word. Operators such as | or ; are not
+// special: they are only separate words when surrounded by whitespace.
+func SplitCommand(command string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+
+		case r == '\\':
+			if i+1 >= len(runes) {
+				return nil, ErrTrailingEscape
+			}
+			i++
+			// A backslash-newline is a line continuation
+			if runes[i] != '\n' {
+				word.WriteRune(runes[i])
+				inWord = true
+			}
+
+		case r == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end == -1 {
+				return nil, fmt.Errorf("%w: ' at position %d", ErrUnbalancedQuote, i)
+			}
+			word.WriteString(string(runes[i+1 : end]))
+			inWord = true
+			i = end
+
+		case r == '"':
+			end, err := readDoubleQuoted(runes, i+1, &word)
+			if err != nil {
+				return nil, fmt.Errorf("%w: \" at position %d", err, i)
+			}
+			inWord = true
+			i = end
+
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
+
+// readDoubleQuoted writes the contents of a double-quoted string starting at
+// start to word and returns the index of the closing quote. Inside double
+// quotes a backslash only escapes $, `, ", \ and newline.
+func readDoubleQuoted(runes []rune, start int, word *strings.Builder) (int, error) {
+	for i := start; i < len(runes); i++ {
+		switch runes[i] {
+		case '"':
+			return i, nil
+		case '\\':
+			if i+1 < len(runes) {
+				switch next := runes[i+1]; next {
+				case '$', '`', '"', '\\':
+					word.WriteRune(next)
+					i++
+					continue
+				case '\n':
+					i++
+					continue
+				}
+			}
+			word.WriteRune('\\')
+		default:
+			word.WriteRune(runes[i])
+		}
+	}
+	return -1, ErrUnbalancedQuote
+}
+
+// indexRune returns the index of the first r at or after start, or -1
+func indexRune(runes []rune, start int, r rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// Quote returns word in a form SplitCommand reads back as the same single
+// word, single-quoting it when it contains anything beyond plain characters
+func Quote(word string) string {
+	if word == "" {
+		return "''"
+	}
+	if strings.IndexFunc(word, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@%+,", r))
+	}) < 0 {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
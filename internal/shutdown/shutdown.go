@@ -0,0 +1,127 @@
+package shutdown
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/logger"
+)
+
+// Manager coordinates a graceful exit: it cancels in-flight work, waits for
+// pending writes to finish and runs registered flush/close hooks.
+type Manager struct {
+	mu      sync.Mutex
+	pending sync.WaitGroup
+	hooks   []func() error
+	closing bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+var defaultManager = New()
+
+// New creates a Manager with its own cancellable context
+func New() *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Default returns the process-wide Manager
+func Default() *Manager {
+	return defaultManager
+}
+
+// Context returns a context that is cancelled when shutdown begins
+func (m *Manager) Context() context.Context {
+	return m.ctx
+}
+
+// Begin marks the start of a write that must not be interrupted.
+// The returned function must be called once the write completes.
+func (m *Manager) Begin() func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Writes started after shutdown began are not waited on
+	if m.closing {
+		return func() {}
+	}
+
+	m.pending.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(m.pending.Done)
+	}
+}
+
+// OnShutdown registers a hook that flushes or closes a resource.
+// Hooks run in reverse registration order after pending writes finish.
+func (m *Manager) OnShutdown(hook func() error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook)
+}
+
+// Shutdown cancels in-flight work, waits up to grace for pending writes and
+// then runs the registered hooks. Only the first call has any effect.
+func (m *Manager) Shutdown(grace time.Duration) {
+	log := logger.GetLogger().WithField("component", "shutdown")
+
+	m.mu.Lock()
+	if m.closing {
+		m.mu.Unlock()
+		return
+	}
+	m.closing = true
+	hooks := m.hooks
+	m.mu.Unlock()
+
+	m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(grace):
+		log.Warn("Timed out waiting for pending writes to finish")
+	}
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](); err != nil {
+			log.WithError(err).Warn("Shutdown hook failed")
+		}
+	}
+}
+
+// HandleSignals blocks until a signal arrives, shuts down gracefully and then calls exit
+func (m *Manager) HandleSignals(signals <-chan os.Signal, grace time.Duration, exit func()) {
+	sig := <-signals
+	logger.GetLogger().WithField("signal", sig.String()).Debug("Shutdown signal received")
+
+	m.Shutdown(grace)
+	exit()
+}
+
+// Begin marks the start of a write on the default Manager
+func Begin() func() {
+	return defaultManager.Begin()
+}
+
+// OnShutdown registers a hook on the default Manager
+func OnShutdown(hook func() error) {
+	defaultManager.OnShutdown(hook)
+}
+
+// Context returns the default Manager's context
+func Context() context.Context {
+	return defaultManager.Context()
+}
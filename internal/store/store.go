@@ -0,0 +1,125 @@
+// Package store holds the file helpers shared by the on-disk stores. Files are
+// always written with \n line endings, and readers accept \n, \r\n and a lone
+// \r so files edited or synced on other platforms still parse.
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxLineSize bounds a single JSON Lines record
+const maxLineSize = 1024 * 1024
+
+// DedupWindow is how recent the last record must be for a repeat to be merged into it
+const DedupWindow = 10 * time.Minute
+
+// ScanLines is a bufio.SplitFunc like bufio.ScanLines that also ends lines at a lone \r
+func ScanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		// A \r may be the first half of \r\n split across reads
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		return 0, nil, nil
+	}
+
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// ReadLines calls fn with each non-blank line of r, without its line ending
+func ReadLines(r io.Reader, fn func(line []byte)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	scanner.Split(ScanLines)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		fn(line)
+	}
+	return scanner.Err()
+}
+
+// AppendJSONLine appends v to a JSON Lines file as a single \n-terminated line
+func AppendJSONLine(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal record: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// WriteFileAtomic writes data to a temporary file and renames it over path, so
+// an interrupted write never corrupts the existing file
+func WriteFileAtomic(path string, data []byte) error {
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// WriteJSONLines atomically replaces a JSON Lines file with records
+func WriteJSONLines[T any](path string, records []T) error {
+	var buf bytes.Buffer
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to marshal record: %w", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	return WriteFileAtomic(path, buf.Bytes())
+}
+
+// NormalizePrompt folds case and whitespace so repeats of a prompt compare equal
+func NormalizePrompt(prompt string) string {
+	return strings.Join(strings.Fields(strings.ToLower(prompt)), " ")
+}
+
+// NormalizeCommand folds whitespace, but not case, so repeats of a command compare equal
+func NormalizeCommand(command string) string {
+	return strings.Join(strings.Fields(command), " ")
+}
+
+// IsRepeat reports whether a record at now repeats one at last: the same
+// normalized prompt and command within DedupWindow
+func IsRepeat(lastPrompt, lastCommand string, last time.Time, prompt, command string, now time.Time) bool {
+	return NormalizePrompt(lastPrompt) == NormalizePrompt(prompt) &&
+		NormalizeCommand(lastCommand) == NormalizeCommand(command) &&
+		now.Sub(last) >= 0 && now.Sub(last) <= DedupWindow
+}
@@ -7,26 +7,24 @@ import (
 	"github.com/spf13/viper"
 )
 
-var log = logger.GetLogger()
-
 type SystemInfo struct{}
 
 type Info struct {
-	OS         string
-	Arch       string
-	GoVersion  string
-	ConfigFile string
-	Debug      bool
-	Verbose    bool
+	OS         string `json:"os"`
+	Arch       string `json:"arch"`
+	GoVersion  string `json:"go_version"`
+	ConfigFile string `json:"config_file"`
+	Debug      bool   `json:"debug"`
+	Verbose    bool   `json:"verbose"`
 }
 
 func NewSystemInfo() *SystemInfo {
-	log.Debugf("Creating new system info instance")
+	logger.GetLogger().Debugf("Creating new system info instance")
 	return &SystemInfo{}
 }
 
 func (s *SystemInfo) GetInfo() *Info {
-	log.Debugf("Getting system info for new instance")
+	logger.GetLogger().Debugf("Getting system info for new instance")
 	return &Info{
 		OS:         runtime.GOOS,
 		Arch:       runtime.GOARCH,
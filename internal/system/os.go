@@ -0,0 +1,32 @@
+package system
+
+import (
+	"runtime"
+	"sync"
+)
+
+var (
+	osMu sync.RWMutex
+	goos = runtime.GOOS
+)
+
+// GOOS returns the operating system that help text, examples and prompts are
+// written for. It is runtime.GOOS unless overridden with SetGOOS.
+func GOOS() string {
+	osMu.RLock()
+	defer osMu.RUnlock()
+	return goos
+}
+
+// SetGOOS overrides the operating system used for help text, examples and
+// prompts, e.g. to test the Windows variants. An empty name restores runtime.GOOS.
+// Command execution always follows the real platform.
+func SetGOOS(name string) {
+	osMu.Lock()
+	defer osMu.Unlock()
+
+	if name == "" {
+		name = runtime.GOOS
+	}
+	goos = name
+}
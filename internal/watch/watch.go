@@ -0,0 +1,104 @@
+// Package watch reports when a file is saved, for regenerating a command each
+// time its request file changes. Changes are detected by polling, and bursts
+// of saves are debounced into a single trigger.
+package watch
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+const (
+	// DefaultInterval is how often Poll checks the file
+	DefaultInterval = 250 * time.Millisecond
+	// DefaultQuiet is how long Debounce waits after the last change
+	DefaultQuiet = 300 * time.Millisecond
+)
+
+// fileState is what Poll compares between checks
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func stat(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, size: info.Size(), modTime: info.ModTime()}
+}
+
+// Poll sends on the returned channel whenever the modification time or size of
+// path changes, checking every interval until ctx is done; the channel is then
+// closed. Creating or removing the file also counts as a change.
+func Poll(ctx context.Context, path string, interval time.Duration) <-chan struct{} {
+	changes := make(chan struct{})
+
+	go func() {
+		defer close(changes)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		last := stat(path)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current := stat(path)
+			if current == last {
+				continue
+			}
+			last = current
+
+			select {
+			case changes <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return changes
+}
+
+// Debounce sends one trigger on the returned channel once changes have been
+// quiet for the quiet duration, so an editor writing a file several times per
+// save regenerates only once. When changes is closed, a pending trigger is
+// still sent before the returned channel is closed. A nil after uses time.After.
+func Debounce(changes <-chan struct{}, quiet time.Duration, after func(time.Duration) <-chan time.Time) <-chan struct{} {
+	if after == nil {
+		after = time.After
+	}
+	triggers := make(chan struct{})
+
+	go func() {
+		defer close(triggers)
+
+		// timer is only set while a trigger is pending
+		var timer <-chan time.Time
+		for {
+			select {
+			case _, ok := <-changes:
+				if !ok {
+					if timer != nil {
+						triggers <- struct{}{}
+					}
+					return
+				}
+				timer = after(quiet)
+			case <-timer:
+				timer = nil
+				triggers <- struct{}{}
+			}
+		}
+	}()
+
+	return triggers
+}
@@ -2,6 +2,10 @@ package main
 
 import "github.com/kodelint/shell-agent/cmd"
 
+// Version is set at build time with -ldflags "-X main.Version=..."
+var Version = "dev"
+
 func main() {
+	cmd.SetVersion(Version)
 	cmd.Execute()
 }
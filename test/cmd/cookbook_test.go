@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/cmd"
+	"github.com/kodelint/shell-agent/internal/cookbook"
+	"github.com/kodelint/shell-agent/internal/output"
+	"github.com/spf13/viper"
+)
+
+// useCookbook points the cookbook at a temporary data dir holding entry
+func useCookbook(t *testing.T, entry cookbook.Entry) *cookbook.Store {
+	t.Helper()
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Setenv("HOME", t.TempDir())
+	viper.Set("data_dir", t.TempDir())
+
+	store, err := cookbook.NewStore()
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	if _, err := store.Add(entry); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	return store
+}
+
+func TestCookbookRemove(t *testing.T) {
+	store := useCookbook(t, cookbook.Entry{Name: "ports", Command: "lsof -i -P"})
+
+	rootCmd := cmd.NewRootCommand()
+	rootCmd.SetArgs([]string{"cookbook", "remove", "PORTS"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if _, err := store.Get("ports"); !errors.Is(err, cookbook.ErrNotFound) {
+		t.Errorf("Expected the entry to be removed, got %v", err)
+	}
+}
+
+func TestCookbookRunChecksWithoutRequireConfirm(t *testing.T) {
+	useCookbook(t, cookbook.Entry{Name: "wipe", Command: "rm -rf /"})
+	viper.Set("safety.require_confirm", false)
+
+	var buf bytes.Buffer
+	output.SetOutput(&buf)
+	t.Cleanup(func() { output.SetOutput(nil) })
+	output.SetTerminalCheck(func() bool { return false })
+	t.Cleanup(func() { output.SetTerminalCheck(nil) })
+
+	rootCmd := cmd.NewRootCommand()
+	rootCmd.SetArgs([]string{"cookbook", "run", "wipe"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got := buf.String(); !strings.Contains(got, "DANGER") || !strings.Contains(got, "Risk score") {
+		t.Errorf("Expected safety findings and a risk score, got:\n%s", got)
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kodelint/shell-agent/cmd"
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/output"
+	"github.com/spf13/viper"
+)
+
+// runWithFakeOllama runs the root command against a fake Ollama that always
+// answers with command, and returns the commands that were executed
+func runWithFakeOllama(t *testing.T, command string, args ...string) []string {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/tags", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ai.OllamaListResponse{Models: []ai.OllamaModel{{Name: "llama3.2:3b"}}})
+	})
+	mux.HandleFunc("/api/generate", func(w http.ResponseWriter, r *http.Request) {
+		response := `{"command": "` + command + `", "explanation": "stub", "confidence": 0.9}`
+		json.NewEncoder(w).Encode(ai.OllamaResponse{Model: "llama3.2:3b", Response: response, Done: true})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("OLLAMA_HOST", server.URL)
+	viper.Set("data_dir", t.TempDir())
+	viper.Set("ai.model_path", t.TempDir())
+	// Run without prompting, as a script with auto_execute would
+	viper.Set("interactive.auto_execute", true)
+	viper.Set("interactive.confirm_commands", false)
+
+	var executed []string
+	output.SetExecutor(func(command string) error {
+		executed = append(executed, command)
+		return nil
+	})
+	t.Cleanup(func() { output.SetExecutor(nil) })
+
+	rootCmd := cmd.NewRootCommand()
+	rootCmd.SetArgs(args)
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	return executed
+}
+
+func TestExecFlagRunsGeneratedCommand(t *testing.T) {
+	executed := runWithFakeOllama(t, "ls -la", "--exec", "list files")
+	if len(executed) != 1 || executed[0] != "ls -la" {
+		t.Errorf("Expected 'ls -la' to be executed, got %q", executed)
+	}
+}
+
+func TestWithoutExecFlagCommandIsOnlyPrinted(t *testing.T) {
+	executed := runWithFakeOllama(t, "ls -la", "--exec=false", "list files")
+	if len(executed) != 0 {
+		t.Errorf("Expected nothing to be executed, got %q", executed)
+	}
+}
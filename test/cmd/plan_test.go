@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/cmd"
+	"github.com/kodelint/shell-agent/internal/logger"
+	"github.com/kodelint/shell-agent/internal/output"
+)
+
+// planFields are the documented top-level fields of output.Plan
+var planFields = []string{
+	"alternatives", "clarification", "command", "confidence", "explanation", "findings",
+	"mode", "model", "request", "risk_score", "schema_version", "steps", "timing",
+}
+
+func TestEmitPlanMatchesSchema(t *testing.T) {
+	var buf bytes.Buffer
+	output.SetOutput(&buf)
+	t.Cleanup(func() { output.SetOutput(nil) })
+	t.Cleanup(func() { cmd.NewRootCommand().Flags().Set("emit", "") })
+
+	executed := runWithFakeOllama(t, "sudo rm -rf /tmp/cache", "--exec=false", "--emit", "plan", "clear the cache")
+	if len(executed) != 0 {
+		t.Errorf("Expected nothing to be executed, got %q", executed)
+	}
+
+	var plan map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &plan); err != nil {
+		t.Fatalf("Expected only JSON on stdout: %v\n%s", err, buf.String())
+	}
+	if logger.GetLogger().Out != os.Stderr {
+		t.Error("Expected logs to go to stderr while emitting a plan")
+	}
+
+	var fields []string
+	for field := range plan {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	if strings.Join(fields, ",") != strings.Join(planFields, ",") {
+		t.Errorf("Expected fields %q, got %q", planFields, fields)
+	}
+
+	if plan["schema_version"] != float64(output.PlanSchemaVersion) {
+		t.Errorf("Expected schema_version %d, got %v", output.PlanSchemaVersion, plan["schema_version"])
+	}
+	if plan["request"] != "clear the cache" || plan["model"] != "llama3.2:3b" || plan["command"] != "sudo rm -rf /tmp/cache" {
+		t.Errorf("Unexpected request, model or command: %v", plan)
+	}
+
+	steps, ok := plan["steps"].([]any)
+	if !ok || len(steps) != 1 {
+		t.Errorf("Expected one step, got %v", plan["steps"])
+	}
+
+	critical := false
+	findings, _ := plan["findings"].([]any)
+	for _, f := range findings {
+		finding := f.(map[string]any)
+		if finding["rule"] == "" || finding["message"] == "" {
+			t.Errorf("Expected each finding to have a rule and message, got %v", finding)
+		}
+		if finding["severity"] == "critical" {
+			critical = true
+		}
+	}
+	if !critical {
+		t.Errorf("Expected a critical safety finding, got %v", plan["findings"])
+	}
+
+	timing, _ := plan["timing"].(map[string]any)
+	if _, ok := timing["started_at"].(string); !ok {
+		t.Errorf("Expected timing.started_at, got %v", plan["timing"])
+	}
+	if _, ok := timing["duration_ms"].(float64); !ok {
+		t.Errorf("Expected timing.duration_ms, got %v", plan["timing"])
+	}
+}
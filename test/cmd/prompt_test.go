@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/cmd"
+	"github.com/kodelint/shell-agent/internal/output"
+	"github.com/spf13/viper"
+)
+
+func TestPromptShowPrintsSystemAndRequestPrompts(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Setenv("HOME", t.TempDir())
+	viper.Set("ai.model_path", t.TempDir())
+	viper.Set("ai.system_prompt", "You are a test prompt.")
+
+	var buf bytes.Buffer
+	output.SetOutput(&buf)
+	t.Cleanup(func() { output.SetOutput(nil) })
+
+	rootCmd := cmd.NewRootCommand()
+	rootCmd.SetArgs([]string{"prompt", "show", "--input", "list files by size"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	got := buf.String()
+	if !strings.Contains(got, "You are a test prompt.") {
+		t.Errorf("Expected the configured system prompt, got:\n%s", got)
+	}
+	if !strings.Contains(got, "User Request: list files by size") {
+		t.Errorf("Expected the rendered user request, got:\n%s", got)
+	}
+}
+
+func TestPromptShowAppliesModeAndTool(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Setenv("HOME", t.TempDir())
+	viper.Set("ai.model_path", t.TempDir())
+
+	var buf bytes.Buffer
+	output.SetOutput(&buf)
+	t.Cleanup(func() { output.SetOutput(nil) })
+
+	rootCmd := cmd.NewRootCommand()
+	t.Cleanup(func() {
+		show, _, _ := rootCmd.Find([]string{"prompt", "show"})
+		show.Flags().Set("mode", "")
+		show.Flags().Set("tool", "")
+	})
+
+	rootCmd.SetArgs([]string{"prompt", "show", "--mode", "explain", "--input", "tar -xzf logs.tgz"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	got := buf.String()
+	if !strings.Contains(got, "explain existing shell commands") || !strings.Contains(got, "Command: tar -xzf logs.tgz") {
+		t.Errorf("Expected the explain prompts, got:\n%s", got)
+	}
+	if !strings.Contains(got, "mode: explain") {
+		t.Errorf("Expected the mode to be reported, got:\n%s", got)
+	}
+
+	buf.Reset()
+	rootCmd.SetArgs([]string{"prompt", "show", "--mode", "shell", "--tool", "kubectl", "--input", "show pods"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got := buf.String(); !strings.Contains(got, "MUST use the 'kubectl' command-line tool") {
+		t.Errorf("Expected the tool constraint in the request prompt, got:\n%s", got)
+	}
+}
@@ -0,0 +1,61 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/spf13/viper"
+)
+
+func TestAlternativesFollowModelCatalog(t *testing.T) {
+	useTestConfig(t)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	tests := map[string]int{
+		"llama3.2:1b":   0,
+		"codegemma:7b":  3,
+		"unknown:model": 2,
+	}
+	for model, want := range tests {
+		if got := client.AlternativesFor(model); got != want {
+			t.Errorf("AlternativesFor(%q) = %d, want %d", model, got, want)
+		}
+	}
+
+	viper.Set("ai.alternatives", 1)
+	client, err = ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+	if got := client.AlternativesFor("codegemma:7b"); got != 1 {
+		t.Errorf("Expected ai.alternatives to override the catalog, got %d", got)
+	}
+}
+
+func TestSmallModelIsAskedForNoAlternatives(t *testing.T) {
+	fake := newFakeOllama(t, `{"command": "ls", "confidence": 0.9, "alternatives": ["ls -la", "dir"]}`)
+	fake.models = []string{"llama3.2:1b"}
+	viper.Set("ai.default_model", "llama3.2:1b")
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	response, err := client.GenerateCommand("list files")
+	if err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+
+	if prompt := fake.lastRequest(t).Prompt; !strings.Contains(prompt, "Do not suggest alternatives") {
+		t.Errorf("Expected the prompt to ask for no alternatives, got:\n%s", prompt)
+	}
+	if len(response.Alternatives) != 0 {
+		t.Errorf("Expected alternatives to be dropped, got %q", response.Alternatives)
+	}
+}
@@ -0,0 +1,141 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/spf13/viper"
+)
+
+func TestAttemptLogRecordsRetryAndEscalation(t *testing.T) {
+	fake := newFakeOllama(t)
+	fake.models = append(fake.models, "codegemma:7b")
+	viper.Set("ai.escalation_model", "codegemma:7b")
+	viper.Set("ai.escalation_threshold", 0.5)
+	viper.Set("ai.max_retries", 1)
+
+	provider := &scriptedProvider{responses: map[string][]*ai.CommandResponse{
+		"llama3.2:3b": {
+			nil, // network error, retried
+			{Command: "ls", Confidence: 0.3},
+		},
+		"codegemma:7b": {
+			{Command: "ls -la", Confidence: 0.9},
+		},
+	}}
+
+	client, err := ai.NewClientWithProvider(provider)
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	resp, err := client.GenerateCommand("list files")
+	if err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+
+	if len(resp.Attempts) != 2 {
+		t.Fatalf("Expected 2 attempts, got %+v", resp.Attempts)
+	}
+	if resp.Attempts[0].Kind != ai.AttemptNetworkRetry || resp.Attempts[0].Model != "llama3.2:3b" {
+		t.Errorf("Expected a network retry on llama3.2:3b, got %+v", resp.Attempts[0])
+	}
+	if resp.Attempts[1].Kind != ai.AttemptEscalation || resp.Attempts[1].Model != "codegemma:7b" {
+		t.Errorf("Expected an escalation to codegemma:7b, got %+v", resp.Attempts[1])
+	}
+
+	if resp.Command != "ls -la" || resp.Model != "codegemma:7b" {
+		t.Errorf("Expected the escalated response, got %+v", resp)
+	}
+
+	summary := ai.SummarizeAttempts(resp.Attempts)
+	if summary != "1 network retry, escalated to codegemma:7b" {
+		t.Errorf("Unexpected summary %q", summary)
+	}
+}
+
+func TestAttemptLogRecordsReparse(t *testing.T) {
+	newFakeOllama(t)
+	viper.Set("ai.reparse_attempts", 1)
+
+	provider := &scriptedProvider{responses: map[string][]*ai.CommandResponse{
+		"llama3.2:3b": {
+			{Command: "echo", Confidence: 0.3, Fallback: true},
+			{Command: "ls -la", Confidence: 0.9},
+		},
+	}}
+
+	client, err := ai.NewClientWithProvider(provider)
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	resp, err := client.GenerateCommand("list files")
+	if err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+
+	if len(resp.Attempts) != 1 || resp.Attempts[0].Kind != ai.AttemptReparse {
+		t.Fatalf("Expected a single reparse attempt, got %+v", resp.Attempts)
+	}
+	if resp.Command != "ls -la" {
+		t.Errorf("Expected the re-prompted response, got %q", resp.Command)
+	}
+	if !strings.Contains(provider.requests[1].Prompt, "not valid JSON") {
+		t.Error("Expected the re-prompt to ask for valid JSON")
+	}
+	if ai.SummarizeAttempts(resp.Attempts) != "1 reparse" {
+		t.Errorf("Unexpected summary %q", ai.SummarizeAttempts(resp.Attempts))
+	}
+}
+
+func TestReparseIsOptIn(t *testing.T) {
+	newFakeOllama(t)
+
+	provider := &scriptedProvider{responses: map[string][]*ai.CommandResponse{
+		"llama3.2:3b": {
+			{Command: "echo", Confidence: 0.3, Fallback: true},
+			{Command: "ls -la", Confidence: 0.9},
+		},
+	}}
+
+	client, err := ai.NewClientWithProvider(provider)
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	resp, err := client.GenerateCommand("list files")
+	if err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+	if len(resp.Attempts) != 0 || !resp.Fallback {
+		t.Errorf("Expected no re-prompt by default, got %+v", resp.Attempts)
+	}
+}
+
+func TestNetworkRetryIsOptIn(t *testing.T) {
+	newFakeOllama(t)
+
+	provider := &scriptedProvider{responses: map[string][]*ai.CommandResponse{
+		"llama3.2:3b": {
+			nil, // network error
+			{Command: "ls -la", Confidence: 0.9},
+		},
+	}}
+
+	client, err := ai.NewClientWithProvider(provider)
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	if _, err := client.GenerateCommand("list files"); err == nil {
+		t.Error("Expected the network error without a retry by default")
+	}
+}
+
+func TestSummarizeAttemptsEmpty(t *testing.T) {
+	if summary := ai.SummarizeAttempts(nil); summary != "" {
+		t.Errorf("Expected empty summary, got %q", summary)
+	}
+}
@@ -0,0 +1,87 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/spf13/viper"
+)
+
+// usePath points PATH at a directory holding only the named executables
+func usePath(t *testing.T, programs ...string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, program := range programs {
+		if err := os.WriteFile(filepath.Join(dir, program), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", program, err)
+		}
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestMissingBinaries(t *testing.T) {
+	usePath(t, "ls", "grep")
+
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"ls -la | grep go", nil},
+		{"cd /tmp && export FOO=1 && ls", nil},
+		{"sudo apt install jq", []string{"apt"}},
+		{"brew install jq && brew cleanup", []string{"brew"}},
+		{"LANG=C pbcopy < notes.txt; ls", []string{"pbcopy"}},
+	}
+
+	for _, tt := range tests {
+		if got := ai.MissingBinaries(tt.command); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("MissingBinaries(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestWarnMissingBinary(t *testing.T) {
+	tests := []struct {
+		command string
+		warns   bool
+	}{
+		{"apt install jq", true},
+		{"ls -la", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			newFakeOllama(t, `{"command": "`+tt.command+`", "confidence": 0.9}`)
+			viper.Set("safety.warn_missing_binary", true)
+			usePath(t, "ls")
+
+			client, err := ai.NewClient()
+			if err != nil {
+				t.Fatalf("Failed to create AI client: %v", err)
+			}
+
+			response, err := client.GenerateCommand("install jq")
+			if err != nil {
+				t.Fatalf("GenerateCommand failed: %v", err)
+			}
+
+			warned := false
+			for _, finding := range response.Findings {
+				if finding.Rule == ai.RuleMissingBinary {
+					warned = true
+				}
+			}
+			if warned != tt.warns {
+				t.Errorf("Expected missing-binary warning %v, got findings %+v", tt.warns, response.Findings)
+			}
+			if tt.warns && !strings.Contains(response.Warning, "regenerating") {
+				t.Errorf("Expected a suggestion to regenerate, got %q", response.Warning)
+			}
+		})
+	}
+}
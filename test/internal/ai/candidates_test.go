@@ -0,0 +1,124 @@
+package ai
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/spf13/viper"
+)
+
+func commandsOf(candidates []ai.Candidate) []string {
+	var result []string
+	for _, c := range candidates {
+		result = append(result, c.Command)
+	}
+	return result
+}
+
+func TestRankCandidates(t *testing.T) {
+	candidates := []ai.Candidate{
+		{Command: "find . -name '*.tmp' -print", Confidence: 0.6},
+		{Command: "rm -rf ./tmp", Confidence: 0.95, Dangerous: true},
+		{Command: "find . -name '*.tmp' -delete", Confidence: 0.8},
+		{Command: "ls *.tmp", Confidence: 0.3},
+		{Command: "echo tie", Confidence: 0.6},
+	}
+
+	ranked := ai.RankCandidates(candidates)
+
+	want := []string{
+		"find . -name '*.tmp' -delete",
+		"find . -name '*.tmp' -print",
+		"echo tie",
+		"rm -rf ./tmp",
+		"ls *.tmp",
+	}
+	if got := commandsOf(ranked); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// The input is left untouched
+	if candidates[0].Command != "find . -name '*.tmp' -print" {
+		t.Error("RankCandidates must not reorder its input")
+	}
+}
+
+func TestGenerateRanksAlternatives(t *testing.T) {
+	newFakeOllama(t, `{"command": "rm -rf ./build", "confidence": 0.9, "alternatives": [
+		"make clean",
+		{"command": "git clean -fdx build", "confidence": 0.7}
+	]}`)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	resp, err := client.GenerateCommand("remove the build directory")
+	if err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+
+	// The primary command is capped at 0.5 and penalized as dangerous; the untagged
+	// alternative inherits a discounted share of the capped confidence
+	want := []string{"git clean -fdx build", "make clean", "rm -rf ./build"}
+	if got := commandsOf(resp.Candidates); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if !resp.Candidates[2].Dangerous {
+		t.Error("Expected rm -rf candidate to be flagged dangerous")
+	}
+	if resp.Command != "rm -rf ./build" {
+		t.Errorf("Primary command must be preserved, got %q", resp.Command)
+	}
+}
+
+func TestSelectCandidateChecksTheSelection(t *testing.T) {
+	newFakeOllama(t, `{"command": "make clean", "explanation": "runs the clean target", "confidence": 0.9, "alternatives": ["rm -rf ./build"], "steps": [{"command": "make clean", "explanation": "clean"}]}`)
+	viper.Set("safety.warn_missing_binary", false)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	resp, err := client.GenerateCommand("remove the build directory")
+	if err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+	if resp.HasCritical() {
+		t.Fatal("Expected the primary command to be safe")
+	}
+
+	selected := client.SelectCandidate(resp, "rm -rf ./build")
+	if selected.Command != "rm -rf ./build" {
+		t.Errorf("Expected the selected command, got %q", selected.Command)
+	}
+	if !selected.HasCritical() || selected.RiskScore == 0 {
+		t.Errorf("Expected the selection to be checked, got findings %+v and risk %d", selected.Findings, selected.RiskScore)
+	}
+	if !strings.Contains(selected.Warning, "DANGER") {
+		t.Errorf("Expected a warning for the selection, got %q", selected.Warning)
+	}
+	// The model's breakdown and explanation describe the primary command
+	if len(selected.Steps) != 0 {
+		t.Errorf("Expected the steps of the primary command to be dropped, got %+v", selected.Steps)
+	}
+	if !strings.Contains(selected.Explanation, "original command, make clean") {
+		t.Errorf("Expected the explanation to be marked as the original's, got %q", selected.Explanation)
+	}
+
+	// Going back to a safe command drops the findings of the dangerous one
+	safe := client.SelectCandidate(selected, "make clean")
+	if safe.HasCritical() || strings.Contains(safe.Warning, "DANGER") {
+		t.Errorf("Expected no critical findings, got %+v and %q", safe.Findings, safe.Warning)
+	}
+	if safe.Explanation != "runs the clean target" || len(safe.Steps) != 1 {
+		t.Errorf("Expected the primary command's explanation and steps back, got %q and %+v", safe.Explanation, safe.Steps)
+	}
+	if resp.Command != "make clean" || resp.HasCritical() {
+		t.Error("Expected the original response to be left unchanged")
+	}
+}
@@ -0,0 +1,196 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/config"
+	"github.com/spf13/viper"
+)
+
+// fakeOpenAI records chat completion requests as raw JSON and answers each with content
+type fakeOpenAI struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []map[string]interface{}
+}
+
+// newFakeOpenAI starts a fake OpenAI-compatible server and selects the openai provider
+func newFakeOpenAI(t *testing.T, content string) *fakeOpenAI {
+	t.Helper()
+
+	fake := &fakeOpenAI{}
+	fake.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+		req["path"] = r.URL.Path
+		fake.requests = append(fake.requests, req)
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": content}},
+			},
+		})
+	}))
+	t.Cleanup(fake.Close)
+
+	useTestConfig(t)
+	viper.Set("ai.provider", "openai")
+	viper.Set("ai.default_model", "gpt-4o-mini")
+	viper.Set("ai.openai.base_url", fake.URL)
+	viper.Set("safety.warn_missing_binary", false)
+
+	return fake
+}
+
+func TestProviderCapabilities(t *testing.T) {
+	useTestConfig(t)
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	openAI := ai.NewOpenAIClient(cfg).Capabilities()
+	if openAI.Has(ai.CapabilityEmbeddings) || openAI.Has(ai.CapabilityKeepAlive) || openAI.Has(ai.CapabilityLocalModels) {
+		t.Errorf("Expected OpenAI to lack embeddings, keep-alive and local models, got %v", openAI.List())
+	}
+	if !openAI.Has(ai.CapabilityStructuredOutput) {
+		t.Error("Expected OpenAI to support structured output")
+	}
+
+	ollama := ai.NewOllamaClient(cfg).Capabilities()
+	for _, capability := range []ai.Capability{ai.CapabilityEmbeddings, ai.CapabilityKeepAlive, ai.CapabilityStreaming, ai.CapabilityThinking} {
+		if !ollama.Has(capability) {
+			t.Errorf("Expected Ollama to support %s", capability)
+		}
+	}
+}
+
+func TestCapabilitiesList(t *testing.T) {
+	got := ai.NewCapabilities(ai.CapabilityThinking, ai.CapabilityEmbeddings).List()
+	if want := []ai.Capability{ai.CapabilityEmbeddings, ai.CapabilityThinking}; !reflect.DeepEqual(got, want) {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+}
+
+func TestEmbeddingsSkipOnOpenAI(t *testing.T) {
+	fake := newFakeOpenAI(t, `{"command": "ls"}`)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	vectors, err := client.Embed(context.Background(), []string{"list files"})
+	if !errors.Is(err, ai.ErrUnsupported) {
+		t.Fatalf("Expected ErrUnsupported, got %v", err)
+	}
+	if vectors != nil {
+		t.Errorf("Expected no vectors, got %v", vectors)
+	}
+	if len(fake.requests) != 0 {
+		t.Errorf("Expected no request to be sent, got %v", fake.requests)
+	}
+}
+
+func TestUnsupportedOptionsAreNotSent(t *testing.T) {
+	fake := newFakeOpenAI(t, `{"command": "ls -la", "explanation": "list files", "confidence": 0.9}`)
+	viper.Set("ai.ollama.keep_alive", "10m")
+	viper.Set("ai.ollama.think", true)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+	client.SetObserver(&recordingObserver{})
+
+	response, err := client.GenerateCommand("list files")
+	if err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+	if response.Command != "ls -la" || response.Model != "gpt-4o-mini" {
+		t.Errorf("Unexpected response %+v", response)
+	}
+
+	if len(fake.requests) != 1 {
+		t.Fatalf("Expected one request, got %d", len(fake.requests))
+	}
+	req := fake.requests[0]
+	if req["path"] != "/chat/completions" {
+		t.Errorf("Expected a chat completions request, got %v", req["path"])
+	}
+	for _, key := range []string{"keep_alive", "think", "stream"} {
+		if _, ok := req[key]; ok {
+			t.Errorf("Expected %q not to be sent to OpenAI, got %v", key, req[key])
+		}
+	}
+	if format, ok := req["response_format"].(map[string]interface{}); !ok || format["type"] != "json_object" {
+		t.Errorf("Expected a JSON response format, got %v", req["response_format"])
+	}
+}
+
+func TestKeepAliveSentToOllama(t *testing.T) {
+	fake := newFakeOllama(t, `{"command": "ls", "confidence": 0.9}`)
+	viper.Set("ai.ollama.keep_alive", "10m")
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+	if _, err := client.GenerateCommand("list files"); err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+
+	req := fake.lastRequest(t)
+	if req.KeepAlive != "10m" || req.Format != "json" {
+		t.Errorf("Expected keep_alive 10m and JSON format, got %+v", req)
+	}
+}
+
+func TestAvailabilityCheckSkipsHostedProviders(t *testing.T) {
+	fake := newFakeOpenAI(t, `{"command": "ls"}`)
+	// Nothing listens here, so checking Ollama would fail
+	t.Setenv("OLLAMA_HOST", "http://127.0.0.1:1")
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+	if err := client.IsAvailable(context.Background()); err != nil {
+		t.Errorf("Expected no Ollama check with the openai provider, got %v", err)
+	}
+	if len(fake.requests) != 0 {
+		t.Errorf("Expected no request to be sent, got %v", fake.requests)
+	}
+}
+
+func TestLocalModelOnHostedProviderExplainsError(t *testing.T) {
+	fake := newFakeOpenAI(t, "")
+	fake.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]any{"error": map[string]string{"message": "model not found"}})
+	})
+	viper.Set("ai.default_model", "llama3.2:3b")
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	_, err = client.GenerateCommand("list files")
+	if err == nil || !strings.Contains(err.Error(), `model "llama3.2:3b" looks like a local Ollama model`) {
+		t.Errorf("Expected the error to explain the model mismatch, got %v", err)
+	}
+}
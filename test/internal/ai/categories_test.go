@@ -0,0 +1,64 @@
+package ai
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/spf13/viper"
+)
+
+func TestCommandCategories(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"ls -la", []string{ai.CategoryNavigation}},
+		{"sudo LANG=C /usr/bin/find / -name '*.conf'", []string{ai.CategorySearch}},
+		{"ps aux | awk '{print $2}' | sort -n", []string{ai.CategoryProcess, ai.CategoryTextProcessing}},
+		{"frobnicate --all", []string{ai.CategoryOther}},
+	}
+
+	for _, tt := range tests {
+		if got := ai.CommandCategories(tt.command); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CommandCategories(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestConfidenceAdjustments(t *testing.T) {
+	tests := []struct {
+		name       string
+		output     string
+		confidence float64
+	}{
+		{"raised", `{"command": "pwd", "confidence": 0.6}`, 0.75},
+		{"lowered", `{"command": "awk -F: '{print $1}' /etc/passwd", "confidence": 0.9}`, 0.7},
+		{"clamped", `{"command": "ls", "confidence": 0.95}`, 1},
+		{"unchanged", `{"command": "git status", "confidence": 0.7}`, 0.7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newFakeOllama(t, tt.output)
+			viper.Set("safety.confidence_adjustments", map[string]float64{
+				ai.CategoryNavigation:     0.15,
+				ai.CategoryTextProcessing: -0.2,
+			})
+
+			client, err := ai.NewClient()
+			if err != nil {
+				t.Fatalf("Failed to create AI client: %v", err)
+			}
+
+			response, err := client.GenerateCommand("do something")
+			if err != nil {
+				t.Fatalf("GenerateCommand failed: %v", err)
+			}
+			if math.Abs(response.Confidence-tt.confidence) > 1e-9 {
+				t.Errorf("Expected confidence %v, got %v", tt.confidence, response.Confidence)
+			}
+		})
+	}
+}
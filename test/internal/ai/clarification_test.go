@@ -0,0 +1,57 @@
+package ai
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/output"
+	"github.com/spf13/viper"
+)
+
+func TestClarificationResponse(t *testing.T) {
+	fake := newFakeOllama(t, `{"command": "", "clarification": "Which directory should be cleaned?", "confidence": 0.1}`)
+	viper.Set("ai.ask_when_ambiguous", true)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	resp, err := client.GenerateCommand("clean it up")
+	if err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+
+	if !strings.Contains(fake.lastRequest(t).System, `"clarification"`) {
+		t.Error("Expected system prompt to permit clarifying questions")
+	}
+
+	if resp.Fallback {
+		t.Fatal("Clarification-only response should not use the fallback parser")
+	}
+	if !resp.NeedsClarification() {
+		t.Fatalf("Expected response to need clarification: %+v", resp)
+	}
+
+	var buf bytes.Buffer
+	output.SetOutput(&buf)
+	t.Cleanup(func() { output.SetOutput(nil) })
+	output.PrintResponse(resp)
+
+	rendered := buf.String()
+	if !strings.Contains(rendered, "Which directory should be cleaned?") {
+		t.Errorf("Expected question to be rendered, got %q", rendered)
+	}
+	if strings.Contains(rendered, "Generated Command") {
+		t.Errorf("Clarification must not be rendered as a command, got %q", rendered)
+	}
+}
+
+func TestAppendClarification(t *testing.T) {
+	got := ai.AppendClarification("clean it up", "Which directory?", "./build")
+	if !strings.Contains(got, "clean it up") || !strings.Contains(got, "Which directory?") || !strings.Contains(got, "./build") {
+		t.Errorf("Expected request, question and answer in %q", got)
+	}
+}
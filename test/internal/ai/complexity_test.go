@@ -0,0 +1,96 @@
+package ai
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/spf13/viper"
+)
+
+func TestMeasureComplexity(t *testing.T) {
+	tests := []struct {
+		command string
+		want    ai.Complexity
+	}{
+		{"ls -la", ai.Complexity{Length: 6}},
+		{"ps aux | grep go | sort | uniq -c", ai.Complexity{Pipes: 3, Length: 33}},
+		{"make || make clean", ai.Complexity{Length: 18}},
+		{"grep 'a|b|c' file", ai.Complexity{Length: 17}},
+		{`echo "$(date) | $(whoami)"`, ai.Complexity{Subshells: 2, Length: 26}},
+		{"echo `date` $((1 + 2))", ai.Complexity{Subshells: 1, Length: 22}},
+		{"(cd src && make) | tee log", ai.Complexity{Pipes: 1, Subshells: 1, Length: 26}},
+	}
+
+	for _, tt := range tests {
+		if got := ai.MeasureComplexity(tt.command); got != tt.want {
+			t.Errorf("MeasureComplexity(%q) = %+v, want %+v", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestComplexityExceeds(t *testing.T) {
+	limits := ai.ComplexityLimits{MaxPipes: 2, MaxSubshells: 1, MaxLength: 20}
+
+	if got := (ai.Complexity{Pipes: 2, Subshells: 1, Length: 20}).Exceeds(limits); got != nil {
+		t.Errorf("Expected a command at the limits to pass, got %q", got)
+	}
+
+	got := (ai.Complexity{Pipes: 3, Subshells: 2, Length: 21}).Exceeds(limits)
+	want := []string{"3 pipes (limit 2)", "2 subshells (limit 1)", "21 characters (limit 20)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Exceeds() = %q, want %q", got, want)
+	}
+
+	// Zero limits are not checked
+	if got := (ai.Complexity{Pipes: 10, Subshells: 10, Length: 1000}).Exceeds(ai.ComplexityLimits{}); got != nil {
+		t.Errorf("Expected no limits to pass everything, got %q", got)
+	}
+}
+
+func TestComplexityWarning(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		action  string
+		warns   bool
+	}{
+		{"too many pipes", "cat access.log | cut -d' ' -f1 | sort | uniq -c | sort -rn | head", "warn", true},
+		{"simple", "du -sh .", "warn", false},
+		{"off", "cat access.log | cut -d' ' -f1 | sort | uniq -c | sort -rn | head", "off", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newFakeOllama(t, `{"command": "`+tt.command+`", "confidence": 0.9}`)
+			viper.Set("safety.warn_missing_binary", false)
+			viper.Set("safety.max_pipes", 3)
+			viper.Set("safety.complexity_action", tt.action)
+
+			client, err := ai.NewClient()
+			if err != nil {
+				t.Fatalf("Failed to create AI client: %v", err)
+			}
+
+			response, err := client.GenerateCommand("top visitors in the access log")
+			if err != nil {
+				t.Fatalf("GenerateCommand failed: %v", err)
+			}
+
+			if response.TooComplex() != tt.warns {
+				t.Errorf("Expected complexity warning %v, got findings %+v", tt.warns, response.Findings)
+			}
+			if tt.warns && !strings.Contains(response.Warning, "5 pipes (limit 3)") {
+				t.Errorf("Expected the pipe count in the warning, got %q", response.Warning)
+			}
+		})
+	}
+}
+
+func TestAppendSimplification(t *testing.T) {
+	got := ai.AppendSimplification("top visitors")
+	if !strings.HasPrefix(got, "top visitors\n") || !strings.Contains(got, "simpler, multi-step approach") {
+		t.Errorf("AppendSimplification() = %q", got)
+	}
+}
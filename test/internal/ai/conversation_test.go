@@ -0,0 +1,37 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+)
+
+func TestConversationContextIsReplayed(t *testing.T) {
+	fake := newFakeOllama(t, `{"command": "find . -name '*.log' -delete", "confidence": 0.9}`)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	// Simulate a loaded session
+	conversation := &ai.Conversation{Messages: []ai.Message{
+		{Role: "user", Content: "find log files"},
+		{Role: "assistant", Content: "find . -name '*.log'"},
+	}}
+	client.SetConversation(conversation)
+
+	if _, err := client.GenerateCommand("now delete them"); err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+
+	prompt := fake.lastRequest(t).Prompt
+	if !strings.Contains(prompt, "find . -name '*.log'") || !strings.Contains(prompt, "now delete them") {
+		t.Errorf("Expected prior turns and the new request in prompt, got %q", prompt)
+	}
+
+	if len(conversation.Messages) != 4 {
+		t.Errorf("Expected the new turn to be recorded, got %d messages", len(conversation.Messages))
+	}
+}
@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/config"
+	"github.com/spf13/viper"
+)
+
+const (
+	pinnedDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	liveDigest   = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+// writeMetadata stores fixture download metadata for a model
+func writeMetadata(t *testing.T, model, digest string) {
+	t.Helper()
+
+	dir := filepath.Join(config.GetModelPath(), model)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create model dir: %v", err)
+	}
+	metadata := `{"name": "` + model + `", "ollama_name": "` + model + `", "source": "ollama", "digest": "` + digest + `"}`
+	if err := os.WriteFile(filepath.Join(dir, "metadata.json"), []byte(metadata), 0644); err != nil {
+		t.Fatalf("Failed to write metadata: %v", err)
+	}
+}
+
+func TestDigestMismatch(t *testing.T) {
+	models := []ai.OllamaModel{{Name: "llama3.2:3b", Digest: liveDigest}, {Name: "mistral:latest", Digest: pinnedDigest}}
+
+	tests := []struct {
+		pinned   string
+		model    string
+		mismatch bool
+	}{
+		{pinnedDigest, "llama3.2:3b", true},
+		{liveDigest, "llama3.2:3b", false},
+		{pinnedDigest, "mistral", false},
+		{"", "llama3.2:3b", false},
+		{pinnedDigest, "phi3:mini", false},
+	}
+
+	for _, tt := range tests {
+		if _, mismatch := ai.DigestMismatch(tt.pinned, tt.model, models); mismatch != tt.mismatch {
+			t.Errorf("DigestMismatch(%q, %q) = %v, want %v", tt.pinned, tt.model, mismatch, tt.mismatch)
+		}
+	}
+}
+
+func TestPinnedDigestMismatchRefusesGeneration(t *testing.T) {
+	fake := newFakeOllama(t, `{"command": "ls", "confidence": 0.9}`, `{"command": "ls", "confidence": 0.9}`)
+	fake.digests = map[string]string{"llama3.2:3b": liveDigest}
+	writeMetadata(t, "llama3.2:3b", pinnedDigest)
+
+	viper.Set("ai.pin_model_digest", ai.PinDigestRefuse)
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+	if _, err := client.GenerateCommand("list files"); !errors.Is(err, ai.ErrDigestMismatch) {
+		t.Fatalf("Expected ErrDigestMismatch, got %v", err)
+	}
+
+	// Warn mode generates anyway
+	viper.Set("ai.pin_model_digest", ai.PinDigestWarn)
+	client, err = ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+	if _, err := client.GenerateCommand("list files"); err != nil {
+		t.Errorf("Expected warn mode to generate, got %v", err)
+	}
+
+	// A matching digest passes in refuse mode
+	writeMetadata(t, "llama3.2:3b", liveDigest)
+	viper.Set("ai.pin_model_digest", ai.PinDigestRefuse)
+	client, err = ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+	if _, err := client.GenerateCommand("list files"); err != nil {
+		t.Errorf("Expected a matching digest to generate, got %v", err)
+	}
+}
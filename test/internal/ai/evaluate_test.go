@@ -0,0 +1,53 @@
+package ai
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+)
+
+func TestEvaluateParsing(t *testing.T) {
+	provider := &scriptedProvider{responses: map[string][]*ai.CommandResponse{
+		"good": {
+			{Command: "ls -la", Confidence: 0.9},
+			{Command: "du -sh .", Confidence: 0.7},
+			{Command: "pwd", Confidence: 0.8},
+		},
+		"flaky": {
+			{Command: "ls", Confidence: 0.6},
+			{Command: "echo", Confidence: 0.3, Fallback: true},
+			nil,
+		},
+	}}
+
+	client, err := ai.NewClientWithProvider(provider)
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	results := client.EvaluateParsing(context.Background(), []string{"good", "flaky"}, []string{"a", "b", "c"})
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	good := results[0]
+	if good.Model != "good" || good.Parsed != 3 || good.SuccessRate() != 1 {
+		t.Errorf("Unexpected result for good model: %+v", good)
+	}
+	if math.Abs(good.AvgConfidence-0.8) > 1e-9 {
+		t.Errorf("Expected average confidence 0.8, got %f", good.AvgConfidence)
+	}
+
+	flaky := results[1]
+	if flaky.Parsed != 1 || flaky.Fallbacks != 1 || flaky.Errors != 1 {
+		t.Errorf("Unexpected counts for flaky model: %+v", flaky)
+	}
+	if math.Abs(flaky.SuccessRate()-1.0/3.0) > 1e-9 {
+		t.Errorf("Expected success rate 1/3, got %f", flaky.SuccessRate())
+	}
+	if math.Abs(flaky.AvgConfidence-0.6) > 1e-9 {
+		t.Errorf("Expected average confidence 0.6 over parsed responses, got %f", flaky.AvgConfidence)
+	}
+}
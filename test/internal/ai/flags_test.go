@@ -0,0 +1,49 @@
+package ai
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+)
+
+func TestExplainFlags(t *testing.T) {
+	fake := newFakeOllama(t, `Here you go: {"flags": [{"flag": "-l", "meaning": "use the long listing format"}, "-a: include hidden files", {"meaning": "no flag"}], "confidence": 0.9}`)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	flags, err := client.ExplainFlags(context.Background(), "ls -la")
+	if err != nil {
+		t.Fatalf("ExplainFlags failed: %v", err)
+	}
+
+	want := []ai.FlagExplanation{
+		{Flag: "-l", Meaning: "use the long listing format"},
+		{Flag: "-a", Meaning: "include hidden files"},
+	}
+	if !reflect.DeepEqual(flags, want) {
+		t.Errorf("Expected %+v, got %+v", want, flags)
+	}
+
+	if req := fake.lastRequest(t); !strings.Contains(req.Prompt, "Command: ls -la") {
+		t.Errorf("Expected the command in the prompt, got %q", req.Prompt)
+	}
+}
+
+func TestExplainFlagsWithoutFlagsFails(t *testing.T) {
+	newFakeOllama(t, `{"explanation": "lists files", "confidence": 0.9}`)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	if _, err := client.ExplainFlags(context.Background(), "ls -la"); err == nil {
+		t.Error("Expected an error when the model returns no flag explanations")
+	}
+}
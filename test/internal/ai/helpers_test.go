@@ -0,0 +1,138 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/spf13/viper"
+)
+
+// scriptedProvider returns canned responses per model, in call order.
+// A nil response simulates a network error.
+type scriptedProvider struct {
+	responses map[string][]*ai.CommandResponse
+	calls     map[string]int
+	requests  []ai.GenerateRequest
+}
+
+func (p *scriptedProvider) Generate(ctx context.Context, req ai.GenerateRequest) (*ai.CommandResponse, error) {
+	if p.calls == nil {
+		p.calls = map[string]int{}
+	}
+	p.requests = append(p.requests, req)
+
+	idx := p.calls[req.Model]
+	p.calls[req.Model]++
+
+	resp := p.responses[req.Model][idx]
+	if resp == nil {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	}
+	return resp, nil
+}
+
+// Capabilities reports local models, since the scripted models stand in for Ollama's
+func (p *scriptedProvider) Capabilities() ai.Capabilities {
+	return ai.NewCapabilities(ai.CapabilityLocalModels)
+}
+
+// fakeOllama mimics the Ollama API endpoints used by the client.
+// Each call to /api/generate returns the next scripted model output.
+type fakeOllama struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	models   []string
+	outputs  []string
+	requests []ai.OllamaRequest
+	// raw, when set, is written verbatim as the next generate response body
+	raw []string
+	// digests are reported for models in the tags list
+	digests map[string]string
+}
+
+// newFakeOllama starts a fake Ollama server and points the config at it
+func newFakeOllama(t *testing.T, outputs ...string) *fakeOllama {
+	t.Helper()
+
+	fake := &fakeOllama{
+		models:  []string{"llama3.2:3b"},
+		outputs: outputs,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/tags", func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		var list ai.OllamaListResponse
+		for _, name := range fake.models {
+			list.Models = append(list.Models, ai.OllamaModel{Name: name, Digest: fake.digests[name]})
+		}
+		json.NewEncoder(w).Encode(list)
+	})
+	mux.HandleFunc("/api/generate", func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		var req ai.OllamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		fake.requests = append(fake.requests, req)
+
+		if len(fake.raw) > 0 {
+			io.WriteString(w, fake.raw[0])
+			fake.raw = fake.raw[1:]
+			return
+		}
+
+		output := ""
+		if len(fake.outputs) > 0 {
+			output = fake.outputs[0]
+			fake.outputs = fake.outputs[1:]
+		}
+		json.NewEncoder(w).Encode(ai.OllamaResponse{Model: req.Model, Response: output, Done: true})
+	})
+
+	fake.Server = httptest.NewServer(mux)
+	t.Cleanup(fake.Close)
+
+	host, port, _ := net.SplitHostPort(fake.Listener.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+
+	useTestConfig(t)
+	viper.Set("ai.ollama.host", host)
+	viper.Set("ai.ollama.port", portNum)
+
+	return fake
+}
+
+// lastRequest returns the most recent generate request received
+func (f *fakeOllama) lastRequest(t *testing.T) ai.OllamaRequest {
+	t.Helper()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.requests) == 0 {
+		t.Fatal("Expected a generate request")
+	}
+	return f.requests[len(f.requests)-1]
+}
+
+// useTestConfig isolates viper state and on-disk data for a single test
+func useTestConfig(t *testing.T) {
+	t.Helper()
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.Set("ai.model_path", t.TempDir())
+}
@@ -0,0 +1,48 @@
+package ai
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+)
+
+func TestSanitizeInput(t *testing.T) {
+	invalid := "list files in caf\xe9 folder"
+
+	tests := []struct {
+		name          string
+		input         string
+		sanitize      bool
+		wantSanitized bool
+		wantErr       error
+	}{
+		{"valid input is untouched", "list files", true, false, nil},
+		{"valid input in strict mode", "list files", false, false, nil},
+		{"invalid input is sanitized", invalid, true, true, nil},
+		{"invalid input is rejected", invalid, false, false, ai.ErrInvalidEncoding},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, sanitized, err := ai.SanitizeInput(test.input, test.sanitize)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Expected error %v, got %v", test.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if sanitized != test.wantSanitized {
+				t.Errorf("Expected sanitized=%v, got %v", test.wantSanitized, sanitized)
+			}
+			if !test.wantSanitized && got != test.input {
+				t.Errorf("Expected input to be unchanged, got %q", got)
+			}
+			if test.wantSanitized && !strings.Contains(got, "�") {
+				t.Errorf("Expected replacement character in %q", got)
+			}
+		})
+	}
+}
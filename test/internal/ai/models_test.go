@@ -0,0 +1,63 @@
+package ai
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/spf13/viper"
+)
+
+// countingLister reports a fixed set of installed models and counts the calls
+type countingLister struct {
+	models []ai.OllamaModel
+	calls  int
+}
+
+func (l *countingLister) ListModels(ctx context.Context) ([]ai.OllamaModel, error) {
+	l.calls++
+	return l.models, nil
+}
+
+func TestListAvailableModelsListsOllamaOnce(t *testing.T) {
+	useTestConfig(t)
+	modelPath := viper.GetString("ai.model_path")
+
+	lister := &countingLister{models: []ai.OllamaModel{{Name: "mistral:7b"}, {Name: "codegemma:7b"}}}
+	manager := ai.NewModelManagerWithLister(lister)
+
+	// Every catalog model has local metadata, so each needs checking against Ollama
+	for _, model := range manager.ListAvailableModels() {
+		dir := filepath.Join(modelPath, model.Name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "metadata.json"), []byte("{}"), 0644); err != nil {
+			t.Fatalf("Failed to write metadata: %v", err)
+		}
+	}
+
+	models := manager.ListAvailableModels()
+	if lister.calls != 1 {
+		t.Errorf("Expected a single ListModels call for %d models, got %d", len(models), lister.calls)
+	}
+
+	for _, model := range models {
+		want := model.Name == "mistral:7b" || model.Name == "codegemma:7b"
+		if model.Downloaded != want {
+			t.Errorf("%s: expected Downloaded=%v", model.Name, want)
+		}
+	}
+}
+
+func BenchmarkListAvailableModels(b *testing.B) {
+	viper.Set("ai.model_path", b.TempDir())
+	b.Cleanup(viper.Reset)
+
+	manager := ai.NewModelManagerWithLister(&countingLister{})
+	for i := 0; i < b.N; i++ {
+		manager.ListAvailableModels()
+	}
+}
@@ -0,0 +1,108 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+)
+
+func TestModes(t *testing.T) {
+	tests := []struct {
+		mode         ai.Mode
+		input        string
+		output       string
+		systemPrompt string
+		check        func(t *testing.T, resp *ai.CommandResponse)
+	}{
+		{
+			mode:         ai.ModeShell,
+			input:        "list files",
+			output:       `{"command": "ls -la", "explanation": "Lists files", "confidence": 0.9}`,
+			systemPrompt: "convert natural language requests into safe, accurate shell commands",
+			check: func(t *testing.T, resp *ai.CommandResponse) {
+				if resp.Command != "ls -la" || resp.Explanation != "Lists files" {
+					t.Errorf("Unexpected shell response: %+v", resp)
+				}
+			},
+		},
+		{
+			mode:         ai.ModeExplain,
+			input:        "tar -xzf a.tgz",
+			output:       `{"explanation": "Extracts a gzipped tarball", "confidence": 0.9}`,
+			systemPrompt: "explain existing shell commands",
+			check: func(t *testing.T, resp *ai.CommandResponse) {
+				if resp.Command != "tar -xzf a.tgz" || resp.Explanation != "Extracts a gzipped tarball" {
+					t.Errorf("Unexpected explain response: %+v", resp)
+				}
+			},
+		},
+		{
+			mode:         ai.ModeReview,
+			input:        "chmod -R 777 /var/www",
+			output:       `{"summary": "Overly permissive", "issues": ["world-writable files", "recursive"], "risk": "High", "confidence": 0.9}`,
+			systemPrompt: "critique existing shell commands for safety and portability",
+			check: func(t *testing.T, resp *ai.CommandResponse) {
+				if resp.Explanation != "Overly permissive" || resp.Risk != "high" || len(resp.Issues) != 2 {
+					t.Errorf("Unexpected review response: %+v", resp)
+				}
+			},
+		},
+		{
+			mode:         ai.ModeFunction,
+			input:        "back up a folder with a timestamp",
+			output:       `{"command": "backup_folder() {\n  tar -czf \"$1-$(date +%Y%m%d%H%M%S).tar.gz\" \"$1\"\n}", "explanation": "Call as backup_folder <dir>", "confidence": 0.9}`,
+			systemPrompt: "turn requests into reusable, named shell functions",
+			check: func(t *testing.T, resp *ai.CommandResponse) {
+				if !strings.HasPrefix(resp.Command, "backup_folder() {\n") || !strings.HasSuffix(resp.Command, "\n}") {
+					t.Errorf("Expected a multi-line function definition, got %q", resp.Command)
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.mode), func(t *testing.T) {
+			fake := newFakeOllama(t, test.output)
+
+			client, err := ai.NewClient()
+			if err != nil {
+				t.Fatalf("Failed to create AI client: %v", err)
+			}
+			client.SetMode(test.mode)
+
+			resp, err := client.GenerateCommand(test.input)
+			if err != nil {
+				t.Fatalf("GenerateCommand failed: %v", err)
+			}
+
+			req := fake.lastRequest(t)
+			if !strings.Contains(req.System, test.systemPrompt) {
+				t.Errorf("Expected %s system prompt, got %q", test.mode, req.System)
+			}
+			if !strings.Contains(req.Prompt, test.input) {
+				t.Errorf("Expected prompt to contain input %q", test.input)
+			}
+
+			if resp.Mode != test.mode {
+				t.Errorf("Expected mode %s, got %s", test.mode, resp.Mode)
+			}
+			test.check(t, resp)
+		})
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	if mode, err := ai.ParseMode(""); err != nil || mode != ai.ModeShell {
+		t.Errorf("Expected empty mode to default to shell, got %q, %v", mode, err)
+	}
+	if mode, err := ai.ParseMode("Review"); err != nil || mode != ai.ModeReview {
+		t.Errorf("Expected case-insensitive match, got %q, %v", mode, err)
+	}
+	if mode, err := ai.ParseMode("function"); err != nil || mode != ai.ModeFunction {
+		t.Errorf("Expected function mode, got %q, %v", mode, err)
+	}
+	if _, err := ai.ParseMode("poetry"); err == nil {
+		t.Error("Expected error for unknown mode")
+	}
+}
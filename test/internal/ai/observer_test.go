@@ -0,0 +1,115 @@
+package ai
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+)
+
+// recordingObserver logs events in the order they arrive
+type recordingObserver struct {
+	events   []string
+	tokens   []string
+	complete *ai.CommandResponse
+}
+
+func (o *recordingObserver) OnToken(token string) {
+	o.events = append(o.events, "token")
+	o.tokens = append(o.tokens, token)
+}
+
+func (o *recordingObserver) OnSafetyFinding(finding ai.Finding) {
+	o.events = append(o.events, "finding:"+finding.Rule)
+}
+
+func (o *recordingObserver) OnComplete(response *ai.CommandResponse) {
+	o.events = append(o.events, "complete")
+	o.complete = response
+}
+
+func TestObserverEventSequence(t *testing.T) {
+	fake := newFakeOllama(t)
+	parts := []string{`{"command": "sudo rm -rf`, ` ./build", "explanation": "Removes the build directory",`, ` "confidence": 0.9}`}
+	fake.raw = []string{chunked(t, parts, true)}
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	observer := &recordingObserver{}
+	client.SetObserver(observer)
+
+	resp, err := client.GenerateCommand("remove the build directory")
+	if err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+
+	if !fake.lastRequest(t).Stream {
+		t.Error("Expected a streamed request when an observer is set")
+	}
+
+	want := []string{
+		"token", "token", "token",
+		"finding:" + ai.RuleDangerousPattern,
+		"finding:" + ai.RuleSudo,
+		"finding:" + ai.RuleRecursive,
+		"complete",
+	}
+	if !reflect.DeepEqual(observer.events, want) {
+		t.Errorf("Expected events %v, got %v", want, observer.events)
+	}
+
+	if got := strings.Join(observer.tokens, ""); got != strings.Join(parts, "") {
+		t.Errorf("Expected tokens to reassemble the output, got %q", got)
+	}
+	if observer.complete != resp {
+		t.Error("Expected OnComplete to receive the returned response")
+	}
+}
+
+func TestNoStreamingWithoutObserver(t *testing.T) {
+	fake := newFakeOllama(t, `{"command": "ls", "confidence": 0.9}`)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	if _, err := client.GenerateCommand("list files"); err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+	if fake.lastRequest(t).Stream {
+		t.Error("Expected a non-streamed request without an observer")
+	}
+}
+
+// finalOnlyObserver wants the findings and result, but no tokens
+type finalOnlyObserver struct {
+	recordingObserver
+}
+
+func (o *finalOnlyObserver) ConsumesTokens() bool { return false }
+
+func TestNoStreamingForObserverWithoutTokens(t *testing.T) {
+	fake := newFakeOllama(t, `{"command": "ls", "confidence": 0.9}`)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+	observer := &finalOnlyObserver{}
+	client.SetObserver(observer)
+
+	if _, err := client.GenerateCommand("list files"); err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+	if fake.lastRequest(t).Stream {
+		t.Error("Expected no streaming for an observer that declines tokens")
+	}
+	if !reflect.DeepEqual(observer.events, []string{"complete"}) {
+		t.Errorf("Expected only the completion event, got %v", observer.events)
+	}
+}
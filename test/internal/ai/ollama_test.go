@@ -0,0 +1,188 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/config"
+	"github.com/spf13/viper"
+)
+
+// chunked splits text into newline-delimited generate chunks; only the last
+// carries done when markDone is set
+func chunked(t *testing.T, parts []string, markDone bool) string {
+	t.Helper()
+
+	var body strings.Builder
+	for i, part := range parts {
+		data, err := json.Marshal(ai.OllamaResponse{
+			Model:    "llama3.2:3b",
+			Response: part,
+			Done:     markDone && i == len(parts)-1,
+		})
+		if err != nil {
+			t.Fatalf("Failed to marshal chunk: %v", err)
+		}
+		body.Write(data)
+		body.WriteByte('\n')
+	}
+	return body.String()
+}
+
+func TestGenerateDecodesChunkedBody(t *testing.T) {
+	parts := []string{`{"command": "find . -name`, ` '*.log' -size +100M",`, ` "explanation": "Finds large logs", "confidence": 0.9}`}
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"single object", chunked(t, []string{strings.Join(parts, "")}, true)},
+		{"chunks until done", chunked(t, parts, true) + chunked(t, []string{"ignored after done"}, false)},
+		{"chunks without done", chunked(t, parts, false)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeOllama(t)
+			fake.raw = []string{tt.body}
+
+			cfg, err := config.Load()
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+
+			resp, err := ai.NewOllamaClient(cfg).Generate(context.Background(), ai.GenerateRequest{Model: "llama3.2:3b", Prompt: "find large logs"})
+			if err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+			if resp.Fallback {
+				t.Errorf("Expected the concatenated chunks to parse as JSON, got fallback: %+v", resp)
+			}
+			if resp.Command != "find . -name '*.log' -size +100M" {
+				t.Errorf("Unexpected command %q", resp.Command)
+			}
+		})
+	}
+}
+
+func TestGenerateReportsChunkError(t *testing.T) {
+	fake := newFakeOllama(t)
+	fake.raw = []string{chunked(t, []string{`{"command":`}, false) + `{"error": "model unloaded"}` + "\n"}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	_, err = ai.NewOllamaClient(cfg).Generate(context.Background(), ai.GenerateRequest{Model: "llama3.2:3b", Prompt: "anything"})
+	if err == nil || !strings.Contains(err.Error(), "model unloaded") {
+		t.Errorf("Expected the chunk error to be returned, got %v", err)
+	}
+}
+
+func TestPollAvailableSucceedsAfterRetries(t *testing.T) {
+	checks := 0
+	check := func(ctx context.Context) error {
+		checks++
+		if checks < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	var reported []int
+	err := ai.PollAvailable(context.Background(), time.Millisecond, check, func(attempt int) {
+		reported = append(reported, attempt)
+	})
+	if err != nil {
+		t.Fatalf("Expected polling to succeed, got %v", err)
+	}
+
+	if checks != 3 {
+		t.Errorf("Expected 3 checks, got %d", checks)
+	}
+	if want := []int{1, 2}; !reflect.DeepEqual(reported, want) {
+		t.Errorf("Expected progress for failed attempts %v, got %v", want, reported)
+	}
+}
+
+func TestPollAvailableGivesUp(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := ai.PollAvailable(ctx, 5*time.Millisecond, func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}, nil)
+	if err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("Expected the last check error, got %v", err)
+	}
+}
+
+func TestResponseSizeLimit(t *testing.T) {
+	fake := newFakeOllama(t)
+	viper.Set("ai.max_response_bytes", 1024)
+
+	huge := chunked(t, []string{strings.Repeat("x", 4096)}, true)
+	fake.raw = []string{huge}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	client := ai.NewOllamaClient(cfg)
+
+	_, err = client.Generate(context.Background(), ai.GenerateRequest{Model: "llama3.2:3b", Prompt: "anything"})
+	if !errors.Is(err, ai.ErrResponseTooLarge) {
+		t.Errorf("Expected ErrResponseTooLarge from Generate, got %v", err)
+	}
+
+	// Model lists are guarded too
+	fake.mu.Lock()
+	for i := 0; i < 100; i++ {
+		fake.models = append(fake.models, strings.Repeat("m", 32))
+	}
+	fake.mu.Unlock()
+
+	if _, err := client.ListModels(context.Background()); !errors.Is(err, ai.ErrResponseTooLarge) {
+		t.Errorf("Expected ErrResponseTooLarge from ListModels, got %v", err)
+	}
+
+	// A body within the limit still decodes
+	fake.raw = []string{chunked(t, []string{`{"command": "ls", "confidence": 0.9}`}, true)}
+	if resp, err := client.Generate(context.Background(), ai.GenerateRequest{Model: "llama3.2:3b", Prompt: "list"}); err != nil || resp.Command != "ls" {
+		t.Errorf("Expected small response to decode, got %+v, %v", resp, err)
+	}
+}
+
+func TestGenerateRecordsTrace(t *testing.T) {
+	raw := `{"command": "du -sh .", "confidence": 0.9}`
+	fake := newFakeOllama(t, raw)
+	viper.Set("safety.warn_missing_binary", false)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	response, err := client.GenerateCommand("how big is this directory")
+	if err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+
+	trace := response.Trace
+	if trace == nil {
+		t.Fatal("Expected the exchange to be traced")
+	}
+	if trace.Provider != "ollama" || trace.RawResponse != raw {
+		t.Errorf("Unexpected trace %+v", trace)
+	}
+	if trace.Prompt != fake.lastRequest(t).Prompt {
+		t.Error("Expected the trace to hold the prompt exactly as sent")
+	}
+}
@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+)
+
+// newWorkDir creates a directory containing build/ and notes.txt
+func newWorkDir(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "build", "cache"), 0755); err != nil {
+		t.Fatalf("Failed to create build dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0644); err != nil {
+		t.Fatalf("Failed to create notes.txt: %v", err)
+	}
+	return dir
+}
+
+func TestMissingPaths(t *testing.T) {
+	dir := newWorkDir(t)
+
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"rm -rf build", nil},
+		{"rm -rf dist", []string{"dist"}},
+		{"cat notes.txt missing.txt", []string{"missing.txt"}},
+		{"cd build && rm -rf cache", nil},
+		{"cd build && ls output", []string{"output"}},
+		{"cd nowhere; ls", []string{"nowhere"}},
+		{"cp notes.txt backup/notes.txt", nil},
+		{"chmod 644 notes.txt", nil},
+		{"find src -name '*.go'", []string{"src"}},
+		{"tail -n 20 notes.txt", nil},
+		{"ls *.log ~/missing $HOME/x /absolute/missing", nil},
+		{"echo dist > out.txt", nil},
+		{"rm 'unbalanced", nil},
+	}
+
+	for _, tt := range tests {
+		if got := ai.MissingPaths(tt.command, dir); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("MissingPaths(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestMissingPathWarning(t *testing.T) {
+	tests := []struct {
+		command     string
+		wantWarning bool
+	}{
+		{"rm -rf build", false},
+		{"rm -rf dist", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			t.Chdir(newWorkDir(t))
+			newFakeOllama(t, `{"command": "`+tt.command+`", "confidence": 0.9}`)
+
+			client, err := ai.NewClient()
+			if err != nil {
+				t.Fatalf("Failed to create AI client: %v", err)
+			}
+
+			resp, err := client.GenerateCommand("clean the build folder")
+			if err != nil {
+				t.Fatalf("GenerateCommand failed: %v", err)
+			}
+
+			gotWarning := strings.Contains(resp.Warning, "Not found in")
+			if gotWarning != tt.wantWarning {
+				t.Errorf("Expected missing-path warning=%v, got warning %q", tt.wantWarning, resp.Warning)
+			}
+			if gotFinding := findRule(resp.Findings, ai.RuleMissingPath) != nil; gotFinding != tt.wantWarning {
+				t.Errorf("Expected missing-path finding=%v, got %+v", tt.wantWarning, resp.Findings)
+			}
+		})
+	}
+}
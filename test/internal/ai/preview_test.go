@@ -0,0 +1,88 @@
+package ai
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+)
+
+// newPreviewDir creates a working directory with logs, a temp file and a build tree
+func newPreviewDir(t *testing.T) []string {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	files := []string{"a.log", "b.log", "c.tmp", filepath.Join("build", "cache", "obj.o")}
+	for _, file := range files {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", filepath.Dir(file), err)
+		}
+		if err := os.WriteFile(file, nil, 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", file, err)
+		}
+	}
+	return files
+}
+
+func TestPreviewDestructive(t *testing.T) {
+	files := newPreviewDir(t)
+
+	tests := []struct {
+		command string
+		preview string
+		matched []string
+	}{
+		{"rm *.log", "ls -d -- *.log", []string{"a.log", "b.log"}},
+		{"rm -f missing.txt", "ls -d -- missing.txt", nil},
+		{"sudo rm -rf build", "find build", []string{"build", "build/cache", "build/cache/obj.o"}},
+		{"find . -name '*.tmp' -delete", "find . -name '*.tmp' -print", []string{"./c.tmp"}},
+		{"mv a.log b.log archive/", "ls -d -- a.log b.log", []string{"a.log", "b.log"}},
+		{"mv -t archive 'c.tmp'", "ls -d -- c.tmp", []string{"c.tmp"}},
+		{"mv --target-directory=archive a.log b.log", "ls -d -- a.log b.log", []string{"a.log", "b.log"}},
+		{"mv --target-directory archive c.tmp", "ls -d -- c.tmp", []string{"c.tmp"}},
+		{"mv -vtarchive a.log", "ls -d -- a.log", []string{"a.log"}},
+	}
+
+	for _, tt := range tests {
+		preview, matched, err := ai.PreviewDestructive(tt.command)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.command, err)
+			continue
+		}
+		if preview != tt.preview {
+			t.Errorf("%q: expected preview %q, got %q", tt.command, tt.preview, preview)
+		}
+		if !reflect.DeepEqual(matched, tt.matched) {
+			t.Errorf("%q: expected %q to be affected, got %q", tt.command, tt.matched, matched)
+		}
+	}
+
+	// A preview must never modify anything
+	for _, file := range files {
+		if _, err := os.Stat(file); err != nil {
+			t.Errorf("Expected %s to survive the previews: %v", file, err)
+		}
+	}
+}
+
+func TestPreviewDestructiveUnsupported(t *testing.T) {
+	newPreviewDir(t)
+
+	for _, command := range []string{
+		"ls *.log | xargs rm",
+		"rm a.log && rm b.log",
+		"find . -name '*.log' -exec rm {} ;",
+		"find . -name '*.log'",
+		"dd if=/dev/zero of=/dev/sda",
+		"rm $(cat list.txt)",
+	} {
+		if _, _, err := ai.PreviewDestructive(command); !errors.Is(err, ai.ErrNoPreview) {
+			t.Errorf("%q: expected ErrNoPreview, got %v", command, err)
+		}
+	}
+}
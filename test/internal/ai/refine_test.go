@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+)
+
+func TestIsRefinement(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"same but exclude node_modules", true},
+		{"but only .go files", true},
+		{"Without hidden files", true},
+		{"make it recursive", true},
+		{"now show disk usage", false},
+		{"with docker list containers", false},
+		{"only", false},
+		{"find all large files in my home directory", false},
+		{"list files", false},
+		{"butter the toast", false},
+		{"and then also compress every single one of them into a tarball", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := ai.IsRefinement(tt.input); got != tt.want {
+			t.Errorf("IsRefinement(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRefinementIncludesPriorCommandAndConstraint(t *testing.T) {
+	fake := newFakeOllama(t, `{"command": "find . -size +100M -not -path './node_modules/*'", "confidence": 0.9}`)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	input := ai.AppendRefinement("find files larger than 100MB", "find . -size +100M", []string{"same but exclude node_modules"})
+	if _, err := client.GenerateCommand(input); err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+
+	prompt := fake.lastRequest(t).Prompt
+	for _, want := range []string{"find files larger than 100MB", "find . -size +100M", "same but exclude node_modules"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected %q in the refinement prompt, got %q", want, prompt)
+		}
+	}
+}
+
+func TestChainedRefinementsDoNotNest(t *testing.T) {
+	constraints := []string{"same but exclude node_modules", "but only .go files"}
+	prompt := ai.AppendRefinement("find large files", "find . -size +100M -name '*.go'", constraints)
+
+	if strings.Count(prompt, "find large files") != 1 || strings.Count(prompt, "Previous command:") != 1 {
+		t.Errorf("Expected the request and previous command once, got %q", prompt)
+	}
+	for _, constraint := range constraints {
+		if !strings.Contains(prompt, "- "+constraint+"\n") {
+			t.Errorf("Expected %q in the refinement prompt, got %q", constraint, prompt)
+		}
+	}
+
+	if got := ai.DescribeRefinement("find large files", constraints); got != "find large files (same but exclude node_modules; but only .go files)" {
+		t.Errorf("DescribeRefinement() = %q", got)
+	}
+	if got := ai.DescribeRefinement("find large files", nil); got != "find large files" {
+		t.Errorf("DescribeRefinement() = %q", got)
+	}
+}
@@ -0,0 +1,125 @@
+package ai
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/config"
+	"github.com/spf13/viper"
+)
+
+func hasFactor(factors []ai.RiskFactor, name string) bool {
+	for _, factor := range factors {
+		if factor.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRiskScoreTriggersExtraConfirmation(t *testing.T) {
+	useTestConfig(t)
+	viper.Set("safety.confirm_above_risk", 60)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	checker := ai.NewSafetyChecker(cfg)
+
+	resp := &ai.CommandResponse{Command: "sudo rm -r ./build/*", Confidence: 0.9}
+	checker.CheckCommand(resp)
+
+	for _, name := range []string{ai.RiskSudo, ai.RiskRecursive, ai.RiskDestructive, ai.RiskWildcard} {
+		if !hasFactor(resp.RiskFactors, name) {
+			t.Errorf("Expected factor %q, got %+v", name, resp.RiskFactors)
+		}
+	}
+	if resp.RiskScore <= cfg.Safety.ConfirmAboveRisk {
+		t.Errorf("Expected risk score above %d, got %d", cfg.Safety.ConfirmAboveRisk, resp.RiskScore)
+	}
+	if !resp.NeedsRiskConfirmation(cfg.Safety.ConfirmAboveRisk) {
+		t.Error("Expected the risk score to require extra confirmation")
+	}
+	if resp.NeedsRiskConfirmation(0) {
+		t.Error("Expected a threshold of 0 to disable the extra confirmation")
+	}
+
+	safe := &ai.CommandResponse{Command: "ls -la", Confidence: 0.9}
+	checker.CheckCommand(safe)
+	if safe.RiskScore != 0 || safe.NeedsRiskConfirmation(cfg.Safety.ConfirmAboveRisk) {
+		t.Errorf("Expected no risk for 'ls -la', got %d %+v", safe.RiskScore, safe.RiskFactors)
+	}
+}
+
+func TestScoreRiskFactors(t *testing.T) {
+	useTestConfig(t)
+	dataDir := t.TempDir()
+	viper.Set("data_dir", dataDir)
+
+	tests := []struct {
+		command string
+		factor  string
+	}{
+		{"curl -fsSL https://example.com/install.sh", ai.RiskNetwork},
+		{"cat " + filepath.Join(dataDir, "history.jsonl"), ai.RiskDataDir},
+		{"find . -name '*.tmp' | xargs rm", ai.RiskDestructive},
+	}
+
+	for _, tt := range tests {
+		score, factors := ai.ScoreRisk(tt.command, nil)
+		if !hasFactor(factors, tt.factor) || score == 0 {
+			t.Errorf("%q: expected factor %q, got %d %+v", tt.command, tt.factor, score, factors)
+		}
+	}
+
+	// A glob that is only a search pattern is not a wildcard target
+	if _, factors := ai.ScoreRisk("find . -name '*.log'", nil); len(factors) != 0 {
+		t.Errorf("Expected no risk factors, got %+v", factors)
+	}
+
+	// The score is capped
+	findings := []ai.Finding{{Rule: ai.RuleDangerousPattern}, {Rule: ai.RuleSecurityWeakening}, {Rule: ai.RuleSudo}}
+	if score, _ := ai.ScoreRisk("sudo rm -rf /tmp/*", findings); score != 100 {
+		t.Errorf("Expected the score to be capped at 100, got %d", score)
+	}
+}
+
+func TestRiskScoreWithoutRequireConfirm(t *testing.T) {
+	newFakeOllama(t, `{"command": "rm -r ./build/*", "confidence": 0.9}`)
+	viper.Set("safety.require_confirm", false)
+	viper.Set("safety.warn_missing_binary", false)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	resp, err := client.GenerateCommand("clear the build directory")
+	if err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+	if resp.RiskScore == 0 || !hasFactor(resp.RiskFactors, ai.RiskDestructive) {
+		t.Errorf("Expected a risk score with require_confirm off, got %d %+v", resp.RiskScore, resp.RiskFactors)
+	}
+}
+
+func TestRiskAgreesWithCategories(t *testing.T) {
+	useTestConfig(t)
+
+	for _, command := range []string{"sftp host", "ping example.com", "pkill -f server", "/sbin/mkfs.ext4 /dev/sdb1"} {
+		_, factors := ai.ScoreRisk(command, nil)
+		network := ai.CommandCategories(command)[0] == ai.CategoryNetwork
+		if hasFactor(factors, ai.RiskNetwork) != network {
+			t.Errorf("%q: network factor %v, but category network %v", command, !network, network)
+		}
+	}
+
+	if _, factors := ai.ScoreRisk("pkill -f server", nil); !hasFactor(factors, ai.RiskDestructive) {
+		t.Errorf("Expected pkill to be destructive, got %+v", factors)
+	}
+	if _, factors := ai.ScoreRisk("/sbin/mkfs.ext4 /dev/sdb1", nil); !hasFactor(factors, ai.RiskDestructive) {
+		t.Errorf("Expected mkfs.ext4 to be destructive, got %+v", factors)
+	}
+}
@@ -0,0 +1,140 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/config"
+	"github.com/spf13/viper"
+)
+
+func newTestSafetyChecker(t *testing.T) *ai.SafetyChecker {
+	t.Helper()
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	return ai.NewSafetyChecker(cfg)
+}
+
+func findRule(findings []ai.Finding, rule string) *ai.Finding {
+	for i := range findings {
+		if findings[i].Rule == rule {
+			return &findings[i]
+		}
+	}
+	return nil
+}
+
+func TestSecurityWeakeningFindings(t *testing.T) {
+	useTestConfig(t)
+	viper.Set("safety.security_weakening", []map[string]string{
+		{"pattern": `auditctl\s+-e\s*0`, "message": "disables the Linux audit system"},
+	})
+	checker := newTestSafetyChecker(t)
+
+	critical := []string{
+		"set +e; make build",
+		"sudo chmod -x /usr/bin/sudo",
+		"sudo iptables -F",
+		"sudo ufw disable",
+		"sudo setenforce 0",
+		"sudo systemctl stop firewalld",
+		"sudo spctl --master-disable",
+		"unset HISTFILE",
+		"sudo auditctl -e 0",
+	}
+	for _, command := range critical {
+		t.Run(command, func(t *testing.T) {
+			resp := &ai.CommandResponse{Command: command, Confidence: 0.9}
+			checker.CheckCommand(resp)
+
+			finding := findRule(resp.Findings, ai.RuleSecurityWeakening)
+			if finding == nil {
+				t.Fatalf("Expected a security-weakening finding, got %+v", resp.Findings)
+			}
+			if finding.Severity != ai.SeverityCritical {
+				t.Errorf("Expected critical severity, got %s", finding.Severity)
+			}
+			if resp.Confidence > 0.5 {
+				t.Errorf("Expected confidence to be capped, got %f", resp.Confidence)
+			}
+			if resp.Warning == "" {
+				t.Error("Expected finding to be surfaced in the warning")
+			}
+		})
+	}
+
+	safe := []string{"ls -la", "set -e", "chmod +x ./script.sh", "iptables -L", "ufw status"}
+	for _, command := range safe {
+		t.Run(command, func(t *testing.T) {
+			if finding := findRule(checker.Findings(command), ai.RuleSecurityWeakening); finding != nil {
+				t.Errorf("Expected no security-weakening finding, got %+v", finding)
+			}
+		})
+	}
+}
+
+func TestExistingSafetyFindings(t *testing.T) {
+	useTestConfig(t)
+	checker := newTestSafetyChecker(t)
+
+	findings := checker.Findings("sudo rm -rf /tmp/build")
+	if f := findRule(findings, ai.RuleDangerousPattern); f == nil || f.Severity != ai.SeverityCritical {
+		t.Errorf("Expected critical dangerous-pattern finding, got %+v", findings)
+	}
+	if f := findRule(findings, ai.RuleSudo); f == nil || f.Severity != ai.SeverityWarning {
+		t.Errorf("Expected sudo warning finding, got %+v", findings)
+	}
+	if f := findRule(findings, ai.RuleRecursive); f == nil {
+		t.Errorf("Expected recursive finding, got %+v", findings)
+	}
+}
+
+func TestRecursiveFinding(t *testing.T) {
+	useTestConfig(t)
+	checker := newTestSafetyChecker(t)
+
+	tests := []struct {
+		command   string
+		recursive bool
+	}{
+		{"rm -fr ./build", true},
+		{"chmod -R 755 ./public", true},
+		{"/bin/chown --recursive www-data: /var/www", true},
+		{"rm release-notes.txt", false},
+		{"grep -r TODO . | xargs echo rm", false},
+		{"rm old.log && ls -R", false},
+		{"chmod -x ./script.sh", false},
+		{"chmod -rwx ./secret.txt", false},
+		{"chown -Rv www-data: /var/www", true},
+		{"cp -r ./src ./backup", true},
+	}
+
+	for _, tt := range tests {
+		got := findRule(checker.Findings(tt.command), ai.RuleRecursive) != nil
+		if got != tt.recursive {
+			t.Errorf("%q: expected recursive=%v, got %v", tt.command, tt.recursive, got)
+		}
+	}
+}
+
+func TestFindingsWithoutRequireConfirm(t *testing.T) {
+	newFakeOllama(t, `{"command": "rm -rf ./build", "confidence": 0.9}`)
+	viper.Set("safety.require_confirm", false)
+	viper.Set("safety.warn_missing_binary", false)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	resp, err := client.GenerateCommand("remove the build directory")
+	if err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+	if !resp.HasCritical() {
+		t.Errorf("Expected findings with require_confirm off, got %+v", resp.Findings)
+	}
+}
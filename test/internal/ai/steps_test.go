@@ -0,0 +1,29 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+)
+
+func TestStepsOnlyResponse(t *testing.T) {
+	newFakeOllama(t, `{"steps": [{"command": "git stash", "explanation": "Set changes aside"}, "git pull --rebase"], "confidence": 0.9}`)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	response, err := client.GenerateCommand("update without losing my changes")
+	if err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+
+	steps := response.ScriptSteps()
+	if len(steps) != 2 || steps[0].Explanation != "Set changes aside" || steps[1].Command != "git pull --rebase" {
+		t.Errorf("Expected both steps, got %+v", steps)
+	}
+	if response.Command != "git stash && git pull --rebase" {
+		t.Errorf("Expected the steps joined into the command, got %q", response.Command)
+	}
+}
@@ -0,0 +1,49 @@
+package ai
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/spf13/viper"
+)
+
+func TestStrictParsing(t *testing.T) {
+	const garbage = "Sure! You could probably try listing things."
+
+	tests := []struct {
+		name   string
+		strict bool
+	}{
+		{"lenient", false},
+		{"strict", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The initial response and the re-prompt are both garbage
+			newFakeOllama(t, garbage, garbage)
+			viper.Set("ai.strict_parsing", tt.strict)
+
+			client, err := ai.NewClient()
+			if err != nil {
+				t.Fatalf("Failed to create AI client: %v", err)
+			}
+
+			resp, err := client.GenerateCommand("list files")
+			if tt.strict {
+				if !errors.Is(err, ai.ErrParseFailure) {
+					t.Fatalf("Expected ErrParseFailure, got response %+v, error %v", resp, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected a fallback response, got error %v", err)
+			}
+			if !resp.Fallback || resp.Confidence != 0.3 {
+				t.Errorf("Expected a low-confidence fallback response, got %+v", resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package ai
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/spf13/viper"
+)
+
+func TestPlaceholders(t *testing.T) {
+	got := ai.Placeholders("convert {{input}} -resize {{ width }}x {{output}} && open {{output}}")
+	want := []string{"input", "width", "output"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Placeholders() = %q, want %q", got, want)
+	}
+
+	if got := ai.Placeholders("ls -la"); got != nil {
+		t.Errorf("Expected no placeholders, got %q", got)
+	}
+}
+
+func TestFillPlaceholders(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		value   string
+		want    string
+	}{
+		{"bare", "cat {{file}}", "notes.txt", "cat notes.txt"},
+		{"inside a word", "cp {{file}} {{file}}.bak", "notes.txt", "cp notes.txt notes.txt.bak"},
+		{"metacharacters", "grep -e {{file}} log", "a;b$c", "grep -e a;b$c log"},
+		{"empty", "ls {{file}} -la", "", "ls  -la"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ai.FillPlaceholders(tt.command, map[string]string{"file": tt.value})
+			if err != nil {
+				t.Fatalf("FillPlaceholders failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FillPlaceholders() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFillPlaceholdersRejectsSplitValues(t *testing.T) {
+	// Commands are split at whitespace, so these could not stay one argument
+	for _, value := range []string{"my notes.txt", "a\tb", " "} {
+		_, err := ai.FillPlaceholders("cat {{file}}", map[string]string{"file": value})
+		if err == nil || !strings.Contains(err.Error(), "file") {
+			t.Errorf("Expected an error for value %q, got %v", value, err)
+		}
+	}
+}
+
+func TestFillPlaceholdersMissingValue(t *testing.T) {
+	_, err := ai.FillPlaceholders("cp {{src}} {{dst}}", map[string]string{"src": "a"})
+	if err == nil || !strings.Contains(err.Error(), "dst") {
+		t.Errorf("Expected an error naming the missing placeholder, got %v", err)
+	}
+}
+
+func TestTemplateResponse(t *testing.T) {
+	fake := newFakeOllama(t, `{"command": "rm {{target}}", "confidence": 0.9}`)
+	viper.Set("safety.warn_missing_binary", false)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+	client.SetTemplate(true)
+
+	response, err := client.GenerateCommand("delete a file")
+	if err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+	if !strings.Contains(fake.lastRequest(t).Prompt, "{{filename}}") {
+		t.Error("Expected the prompt to ask for placeholders")
+	}
+	if !reflect.DeepEqual(response.Placeholders, []string{"target"}) {
+		t.Errorf("Expected the target placeholder, got %q", response.Placeholders)
+	}
+
+	// The filled command is checked again: this value makes it recursive
+	filled, err := client.FillTemplate(response, map[string]string{"target": "-rf"})
+	if err != nil {
+		t.Fatalf("FillTemplate failed: %v", err)
+	}
+	if filled.Command != "rm -rf" {
+		t.Errorf("Expected the filled command, got %q", filled.Command)
+	}
+	if len(filled.Findings) == 0 {
+		t.Error("Expected the filled command to be checked for safety")
+	}
+	if response.Command != "rm {{target}}" {
+		t.Error("Expected the templated response to be left unchanged")
+	}
+}
+
+func TestFillTemplateRunsAllChecks(t *testing.T) {
+	newFakeOllama(t, `{"command": "cat {{file}}", "confidence": 0.9}`)
+	t.Chdir(t.TempDir())
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+	client.SetTemplate(true)
+
+	response, err := client.GenerateCommand("show a file")
+	if err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+
+	filled, err := client.FillTemplate(response, map[string]string{"file": "missing.txt"})
+	if err != nil {
+		t.Fatalf("FillTemplate failed: %v", err)
+	}
+	found := false
+	for _, finding := range filled.Findings {
+		if finding.Rule == ai.RuleMissingPath {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a missing-path finding for the filled command, got %+v", filled.Findings)
+	}
+}
@@ -0,0 +1,78 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/spf13/viper"
+)
+
+func TestStripThinking(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		text     string
+		thinking string
+	}{
+		{"no thinking", `{"command": "ls"}`, `{"command": "ls"}`, ""},
+		{"leading block", "<think>list them</think>\n{\"command\": \"ls\"}", `{"command": "ls"}`, "list them"},
+		{"missing opening tag", "user wants files</think>{\"command\": \"ls\"}", `{"command": "ls"}`, "user wants files"},
+		{"two blocks", "<think>a</think>{\"command\": <think>b</think>\"ls\"}", `{"command": "ls"}`, "a\nb"},
+		{"unclosed block", "<think>still going {\"command\": \"ls\"}", "<think>still going {\"command\": \"ls\"}", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, thinking := ai.StripThinking(tt.response)
+			if text != tt.text || thinking != tt.thinking {
+				t.Errorf("Expected (%q, %q), got (%q, %q)", tt.text, tt.thinking, text, thinking)
+			}
+		})
+	}
+}
+
+func TestThinkingIsStrippedBeforeParsing(t *testing.T) {
+	// The thinking mentions a different command in JSON, which must not be parsed
+	fake := newFakeOllama(t, `<think>Maybe {"command": "rm -rf ./logs"}? No, just list them.</think>{"command": "ls -la logs", "confidence": 0.9}`)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	response, err := client.GenerateCommand("show the logs")
+	if err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+	if response.Command != "ls -la logs" || response.Fallback {
+		t.Errorf("Expected the answer after the thinking block, got %+v", response)
+	}
+	if response.Thinking == "" {
+		t.Error("Expected the thinking to be kept on the response")
+	}
+	if fake.lastRequest(t).Think {
+		t.Error("Expected think to be off by default")
+	}
+}
+
+func TestThinkOptionUsesThinkingField(t *testing.T) {
+	fake := newFakeOllama(t)
+	fake.raw = []string{`{"model": "llama3.2:3b", "thinking": "the user wants a listing", "response": "{\"command\": \"ls\", \"confidence\": 0.9}", "done": true}`}
+	viper.Set("ai.ollama.think", true)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	response, err := client.GenerateCommand("list files")
+	if err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+	if !fake.lastRequest(t).Think {
+		t.Error("Expected the request to ask for thinking")
+	}
+	if response.Command != "ls" || response.Thinking != "the user wants a listing" {
+		t.Errorf("Expected command 'ls' with separate thinking, got %+v", response)
+	}
+}
@@ -0,0 +1,61 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+)
+
+func TestToolConstraint(t *testing.T) {
+	tests := []struct {
+		name        string
+		tool        string
+		wantWarning bool
+	}{
+		{"installed tool", "go", false},
+		{"missing tool", "shell-agent-no-such-tool", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeOllama(t, `{"command": "`+tt.tool+` version", "confidence": 0.9}`)
+
+			client, err := ai.NewClient()
+			if err != nil {
+				t.Fatalf("Failed to create AI client: %v", err)
+			}
+			client.SetTool(tt.tool)
+
+			resp, err := client.GenerateCommand("show the version")
+			if err != nil {
+				t.Fatalf("GenerateCommand failed: %v", err)
+			}
+
+			if prompt := fake.lastRequest(t).Prompt; !strings.Contains(prompt, "MUST use the '"+tt.tool+"' command-line tool") {
+				t.Errorf("Expected tool constraint in prompt, got %q", prompt)
+			}
+
+			gotWarning := strings.Contains(resp.Warning, "'"+tt.tool+"' is not installed")
+			if gotWarning != tt.wantWarning {
+				t.Errorf("Expected missing-tool warning=%v, got warning %q", tt.wantWarning, resp.Warning)
+			}
+		})
+	}
+}
+
+func TestNoToolConstraintByDefault(t *testing.T) {
+	fake := newFakeOllama(t, `{"command": "ls", "confidence": 0.9}`)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	if _, err := client.GenerateCommand("list files"); err != nil {
+		t.Fatalf("GenerateCommand failed: %v", err)
+	}
+	if strings.Contains(fake.lastRequest(t).Prompt, "command-line tool") {
+		t.Error("Expected no tool constraint without --tool")
+	}
+}
@@ -0,0 +1,64 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/output"
+)
+
+func TestWhy(t *testing.T) {
+	fake := newFakeOllama(t, `{"explanation": "find with -size filters by size without listing every file, unlike du.", "confidence": 0.9}`)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	resp, err := client.Why(context.Background(), "find large log files", "find . -name '*.log' -size +100M", []string{"du -ah . | sort -h"})
+	if err != nil {
+		t.Fatalf("Why failed: %v", err)
+	}
+
+	req := fake.lastRequest(t)
+	for _, want := range []string{"find . -name '*.log' -size +100M", "du -ah . | sort -h", "find large log files", "why the chosen command"} {
+		if !strings.Contains(req.Prompt, want) {
+			t.Errorf("Expected prompt to contain %q, got %q", want, req.Prompt)
+		}
+	}
+	if !strings.Contains(req.System, "justify") {
+		t.Errorf("Expected a reasoning system prompt, got %q", req.System)
+	}
+
+	if resp.Mode != ai.ModeExplain || resp.Command != "find . -name '*.log' -size +100M" {
+		t.Errorf("Expected an explanation of the prior command, got %+v", resp)
+	}
+
+	var buf bytes.Buffer
+	output.SetOutput(&buf)
+	t.Cleanup(func() { output.SetOutput(nil) })
+	output.PrintResponse(resp)
+
+	rendered := buf.String()
+	if !strings.Contains(rendered, "filters by size") {
+		t.Errorf("Expected the explanation to be rendered, got %q", rendered)
+	}
+	if strings.Contains(rendered, "Generated Command") || strings.Contains(rendered, "Confidence") {
+		t.Errorf("Expected the explanation only, got %q", rendered)
+	}
+}
+
+func TestWhyWithoutCommand(t *testing.T) {
+	useTestConfig(t)
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+	if _, err := client.Why(context.Background(), "", "  ", nil); err == nil {
+		t.Error("Expected an error when there is no command")
+	}
+}
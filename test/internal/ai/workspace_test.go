@@ -0,0 +1,106 @@
+package ai
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/spf13/viper"
+)
+
+// useWorkspace changes into a temporary directory holding the named files
+func useWorkspace(t *testing.T, names ...string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	t.Chdir(dir)
+	return dir
+}
+
+func TestWorkspaceContextWithRemoteProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		override bool
+		enabled  bool
+		sent     bool
+	}{
+		{"local provider", "ollama", false, true, true},
+		{"remote provider", "openai", false, true, false},
+		{"remote provider with override", "openai", true, true, true},
+		{"workspace context off", "ollama", false, false, false},
+		{"remote provider with override but context off", "openai", true, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t)
+			dir := useWorkspace(t, "report.csv", "secret-plans.txt")
+			viper.Set("ai.provider", tt.provider)
+			viper.Set("ai.allow_context_to_remote", tt.override)
+			viper.Set("ai.workspace_context", tt.enabled)
+
+			client, err := ai.NewClient()
+			if err != nil {
+				t.Fatalf("Failed to create AI client: %v", err)
+			}
+
+			prompt := client.EnhancedPrompt("count the lines in the csv")
+			for _, want := range []string{"Working Directory: ", filepath.Base(dir), "secret-plans.txt"} {
+				if strings.Contains(prompt, want) != tt.sent {
+					t.Errorf("Expected %q in prompt: %v, got:\n%s", want, tt.sent, prompt)
+				}
+			}
+		})
+	}
+}
+
+func TestWorkspaceContextListsVisibleFiles(t *testing.T) {
+	useTestConfig(t)
+	dir := useWorkspace(t, ".env", "main.go")
+	if err := os.Mkdir(filepath.Join(dir, "docs"), 0755); err != nil {
+		t.Fatalf("Failed to create docs: %v", err)
+	}
+	viper.Set("ai.workspace_context", true)
+	for i := 0; i < 45; i++ {
+		os.WriteFile(filepath.Join(dir, fmt.Sprintf("z%02d.log", i)), nil, 0644)
+	}
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	prompt := client.EnhancedPrompt("list files")
+	if !strings.Contains(prompt, "Files: docs/, main.go, z00.log") {
+		t.Errorf("Expected directories marked and files in order, got:\n%s", prompt)
+	}
+	if strings.Contains(prompt, ".env") {
+		t.Errorf("Expected dotfiles to be left out, got:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "(and 7 more)") {
+		t.Errorf("Expected the listing to be capped, got:\n%s", prompt)
+	}
+}
+
+func TestWorkspaceContextIsOptIn(t *testing.T) {
+	useTestConfig(t)
+	useWorkspace(t, "report.csv")
+
+	client, err := ai.NewClient()
+	if err != nil {
+		t.Fatalf("Failed to create AI client: %v", err)
+	}
+
+	if prompt := client.EnhancedPrompt("count the lines in the csv"); strings.Contains(prompt, "Working Directory: ") {
+		t.Errorf("Expected no workspace context by default, got:\n%s", prompt)
+	}
+}
@@ -1,8 +1,17 @@
 package config
 
 import (
-	"github.com/kodelint/shell-agent/internal/config"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/kodelint/shell-agent/internal/config"
+	"github.com/kodelint/shell-agent/internal/logger"
+	"github.com/kodelint/shell-agent/internal/system"
+	"github.com/spf13/viper"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -24,3 +33,198 @@ func TestLoadConfig(t *testing.T) {
 		t.Error("Timeout should be positive")
 	}
 }
+
+func TestModelProviderMismatch(t *testing.T) {
+	tests := []struct {
+		provider string
+		model    string
+		mismatch bool
+	}{
+		{"ollama", "llama3.2:3b", false},
+		{"ollama", "codegemma:7b", false},
+		{"ollama", "my-custom-model", false},
+		{"ollama", "gpt-4o", true},
+		{"ollama", "claude-3-haiku", true},
+		{"", "gpt-4o-mini", true},
+		{"openai", "gpt-4o", false},
+		{"openai", "o3-mini", false},
+		{"openai", "llama3.2:3b", true},
+		{"openai", "mistral", true},
+		{"openai", "my-deployment", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.provider+"/"+test.model, func(t *testing.T) {
+			got := config.ModelProviderMismatch(test.provider, test.model)
+			if (got != "") != test.mismatch {
+				t.Errorf("Expected mismatch=%v, got %q", test.mismatch, got)
+			}
+		})
+	}
+}
+
+func TestValidateWarnsOnMismatch(t *testing.T) {
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if warnings := cfg.Validate(); len(warnings) != 0 {
+		t.Errorf("Expected default config to be valid, got %v", warnings)
+	}
+
+	cfg.AI.Provider = "openai"
+	cfg.AI.DefaultModel = "llama3.2:3b"
+	warnings := cfg.Validate()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "llama3.2:3b") {
+		t.Errorf("Expected a mismatch warning naming the model, got %v", warnings)
+	}
+}
+
+func TestParseOllamaHost(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"127.0.0.1:11434", "http://127.0.0.1:11434"},
+		{"http://remote:11434", "http://remote:11434"},
+		{"remote", "http://remote:11434"},
+		{"https://ollama.example.com", "https://ollama.example.com:443"},
+		{"http://proxy:8080/ollama/", "http://proxy:8080/ollama"},
+		{":9999", "http://localhost:9999"},
+		{"[::1]:11434", "http://[::1]:11434"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := config.ParseOllamaHost(tt.value)
+			if err != nil {
+				t.Fatalf("ParseOllamaHost(%q) failed: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseOllamaHost(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"", "ftp://remote:21"} {
+		if _, err := config.ParseOllamaHost(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
+
+func TestOllamaHostEnvironment(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Setenv(config.OllamaHostEnv, "remote:8080")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.AI.Ollama.BaseURL != "http://remote:8080" {
+		t.Errorf("Expected OLLAMA_HOST to be used, got %q", cfg.AI.Ollama.BaseURL)
+	}
+
+	// Explicit configuration wins over the environment
+	viper.Set("ai.ollama.host", "configured")
+	cfg, err = config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.AI.Ollama.BaseURL != "http://configured:11434" {
+		t.Errorf("Expected configured host to win, got %q", cfg.AI.Ollama.BaseURL)
+	}
+}
+
+func TestOllamaDefaultAddress(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Setenv(config.OllamaHostEnv, "")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.AI.Ollama.BaseURL != "http://localhost:11434" {
+		t.Errorf("Expected default address, got %q", cfg.AI.Ollama.BaseURL)
+	}
+}
+
+func TestSystemPromptExamplesFollowOS(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Cleanup(func() { system.SetGOOS("") })
+
+	system.SetGOOS("windows")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if !strings.Contains(cfg.AI.SystemPrompt, `"dir /a"`) || strings.Contains(cfg.AI.SystemPrompt, "Get-ChildItem") {
+		t.Errorf("Expected cmd.exe examples in the Windows system prompt, got %q", cfg.AI.SystemPrompt)
+	}
+}
+
+func TestDebugFlagReachesConfigLogging(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Cleanup(func() { logger.InitLogger(false, false) })
+
+	// Taken before flags are parsed, as package-level loggers used to be
+	early := logger.GetLogger()
+	logger.InitLogger(true, false)
+
+	var buf bytes.Buffer
+	early.SetOutput(&buf)
+
+	if _, err := config.Load(); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Configuration loaded") {
+		t.Errorf("Expected debug output from the config package, got %q", buf.String())
+	}
+}
+
+func TestReadConfigFile(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+		found   bool
+		invalid bool
+	}{
+		{"missing", "", false, false},
+		{"valid", "ai:\n  default_model: \"mistral:7b\"\n", true, false},
+		{"malformed", "ai:\n  default_model: [unclosed\n\tbad: indent\n", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+
+			path := filepath.Join(dir, tt.name+".yaml")
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+					t.Fatalf("Failed to write config: %v", err)
+				}
+			}
+			viper.SetConfigFile(path)
+
+			found, err := config.ReadConfigFile()
+			if found != tt.found {
+				t.Errorf("Expected found=%v, got %v", tt.found, found)
+			}
+			if tt.invalid {
+				if !errors.Is(err, config.ErrInvalidConfigFile) || !strings.Contains(err.Error(), path) {
+					t.Errorf("Expected an invalid config error naming %s, got %v", path, err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
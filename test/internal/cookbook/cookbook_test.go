@@ -0,0 +1,159 @@
+package cookbook
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/cookbook"
+	"github.com/kodelint/shell-agent/internal/feedback"
+	"github.com/spf13/viper"
+)
+
+func newTestStore(t *testing.T) *cookbook.Store {
+	t.Helper()
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.Set("data_dir", t.TempDir())
+
+	store, err := cookbook.NewStore()
+	if err != nil {
+		t.Fatalf("Failed to create cookbook store: %v", err)
+	}
+	return store
+}
+
+func names(list []cookbook.Entry) []string {
+	var result []string
+	for _, e := range list {
+		result = append(result, e.Name)
+	}
+	return result
+}
+
+func TestCookbookCRUD(t *testing.T) {
+	store := newTestStore(t)
+
+	if _, err := store.Add(cookbook.Entry{Name: "ports", Command: "lsof -i -P", Tags: []string{" Network ", "network"}}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if _, err := store.Add(cookbook.Entry{Name: "disk-usage", Command: "du -sh .", Description: "disk usage"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	got, err := store.Get("Disk-Usage")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Command != "du -sh ." || got.Description != "disk usage" || got.Verified || got.CreatedAt.IsZero() {
+		t.Errorf("Get returned %+v", got)
+	}
+
+	// Names are unique, ignoring case
+	if _, err := store.Add(cookbook.Entry{Name: "PORTS", Command: "ss -tlnp"}); !errors.Is(err, cookbook.ErrExists) {
+		t.Errorf("Expected ErrExists, got %v", err)
+	}
+
+	list, err := store.List("")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if want := []string{"disk-usage", "ports"}; !reflect.DeepEqual(names(list), want) {
+		t.Errorf("Expected %v, got %v", want, names(list))
+	}
+
+	list, err = store.List("NETWORK")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(list) != 1 || !reflect.DeepEqual(list[0].Tags, []string{"network"}) {
+		t.Errorf("Expected one normalized network entry, got %+v", list)
+	}
+
+	if err := store.Remove("ports"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := store.Get("ports"); !errors.Is(err, cookbook.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a removed entry, got %v", err)
+	}
+	if err := store.Remove("ports"); !errors.Is(err, cookbook.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound removing a missing entry, got %v", err)
+	}
+}
+
+func TestCookbookRejectsInvalidEntries(t *testing.T) {
+	store := newTestStore(t)
+
+	for _, entry := range []cookbook.Entry{
+		{Name: "", Command: "ls"},
+		{Name: "two words", Command: "ls"},
+		{Name: "empty", Command: "   "},
+	} {
+		if _, err := store.Add(entry); err == nil {
+			t.Errorf("Expected error adding %+v", entry)
+		}
+	}
+}
+
+func TestFromFeedback(t *testing.T) {
+	worked := feedback.Feedback{UserPrompt: "show disk usage", GeneratedCommand: "du -sh .", Status: "worked"}
+
+	entry, err := cookbook.FromFeedback("disk-usage", worked)
+	if err != nil {
+		t.Fatalf("FromFeedback failed: %v", err)
+	}
+	want := cookbook.Entry{Name: "disk-usage", Command: "du -sh .", Description: "show disk usage", Verified: true}
+	if !reflect.DeepEqual(entry, want) {
+		t.Errorf("Expected %+v, got %+v", want, entry)
+	}
+
+	for _, status := range []string{"failed", "incorrect"} {
+		worked.Status = status
+		if _, err := cookbook.FromFeedback("disk-usage", worked); err == nil {
+			t.Errorf("Expected error promoting %q feedback", status)
+		}
+	}
+}
+
+func TestLastWorked(t *testing.T) {
+	list := []feedback.Feedback{
+		{GeneratedCommand: "du -sh .", Status: "worked"},
+		{GeneratedCommand: "df -h", Status: "worked"},
+		{GeneratedCommand: "rm -rf build", Status: "failed"},
+	}
+
+	tests := []struct {
+		command string
+		want    string
+		ok      bool
+	}{
+		{"", "df -h", true},
+		{"du -sh .", "du -sh .", true},
+		{" df -h ", "df -h", true},
+		{"rm -rf build", "", false},
+		{"uptime", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := cookbook.LastWorked(list, tt.command)
+		if ok != tt.ok || got.GeneratedCommand != tt.want {
+			t.Errorf("LastWorked(%q) = %q, %v; want %q, %v", tt.command, got.GeneratedCommand, ok, tt.want, tt.ok)
+		}
+	}
+
+	// A promoted entry keeps its verified status through the store
+	store := newTestStore(t)
+	f, _ := cookbook.LastWorked(list, "")
+	entry, err := cookbook.FromFeedback("free-space", f)
+	if err != nil {
+		t.Fatalf("FromFeedback failed: %v", err)
+	}
+	if _, err := store.Add(entry); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	saved, err := store.Get("free-space")
+	if err != nil || !saved.Verified {
+		t.Errorf("Expected a verified entry, got %+v, %v", saved, err)
+	}
+}
@@ -0,0 +1,136 @@
+package favorites
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/favorites"
+	"github.com/spf13/viper"
+)
+
+func newTestStore(t *testing.T) *favorites.Store {
+	t.Helper()
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.Set("data_dir", t.TempDir())
+
+	store, err := favorites.NewStore()
+	if err != nil {
+		t.Fatalf("Failed to create favorites store: %v", err)
+	}
+	return store
+}
+
+func commands(list []favorites.Favorite) []string {
+	var result []string
+	for _, f := range list {
+		result = append(result, f.Command)
+	}
+	return result
+}
+
+func TestFavoritesCRUD(t *testing.T) {
+	store := newTestStore(t)
+
+	first, err := store.Add("tar -czf backup.tar.gz ~/docs", "back up my docs", []string{"backup"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	second, err := store.Add("df -h", "", nil)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if first.ID != 1 || second.ID != 2 {
+		t.Errorf("Expected IDs 1 and 2, got %d and %d", first.ID, second.ID)
+	}
+
+	got, err := store.Get(first.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Command != first.Command || got.Prompt != "back up my docs" {
+		t.Errorf("Get returned %+v", got)
+	}
+
+	if err := store.Remove(first.ID); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := store.Get(first.ID); err == nil {
+		t.Error("Expected error getting a removed favorite")
+	}
+	if err := store.Remove(first.ID); err == nil {
+		t.Error("Expected error removing a missing favorite")
+	}
+
+	// IDs are not reused after a removal
+	third, err := store.Add("uptime", "", nil)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if third.ID != 3 {
+		t.Errorf("Expected ID 3, got %d", third.ID)
+	}
+
+	list, err := store.List("")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if want := []string{"df -h", "uptime"}; !reflect.DeepEqual(commands(list), want) {
+		t.Errorf("Expected %v, got %v", want, commands(list))
+	}
+}
+
+func TestFavoritesRejectEmptyCommand(t *testing.T) {
+	store := newTestStore(t)
+
+	if _, err := store.Add("   ", "", []string{"x"}); err == nil {
+		t.Error("Expected error adding an empty command")
+	}
+}
+
+func TestFavoritesTagFilter(t *testing.T) {
+	store := newTestStore(t)
+
+	for _, add := range []struct {
+		command string
+		tags    []string
+	}{
+		{"rsync -a ~/docs /mnt/backup", []string{"backup", " Nightly "}},
+		{"df -h", []string{"disk"}},
+		{"tar -czf etc.tar.gz /etc", []string{"BACKUP", "backup"}},
+	} {
+		if _, err := store.Add(add.command, "", add.tags); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		tag  string
+		want []string
+	}{
+		{"backup", []string{"rsync -a ~/docs /mnt/backup", "tar -czf etc.tar.gz /etc"}},
+		{"Nightly", []string{"rsync -a ~/docs /mnt/backup"}},
+		{"disk", []string{"df -h"}},
+		{"missing", nil},
+	}
+
+	for _, tt := range tests {
+		list, err := store.List(tt.tag)
+		if err != nil {
+			t.Fatalf("List(%q) failed: %v", tt.tag, err)
+		}
+		if !reflect.DeepEqual(commands(list), tt.want) {
+			t.Errorf("List(%q): expected %v, got %v", tt.tag, tt.want, commands(list))
+		}
+	}
+
+	// Tags are normalized when saved
+	favorite, err := store.Get(3)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if want := []string{"backup"}; !reflect.DeepEqual(favorite.Tags, want) {
+		t.Errorf("Expected tags %v, got %v", want, favorite.Tags)
+	}
+}
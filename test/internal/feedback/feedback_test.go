@@ -0,0 +1,42 @@
+package feedback
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/feedback"
+	"github.com/spf13/viper"
+)
+
+func TestDedupCollapsesRepeatedFeedback(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.Set("data_dir", t.TempDir())
+	viper.Set("feedback.dedup", true)
+
+	manager, err := feedback.NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create feedback manager: %v", err)
+	}
+
+	now := time.Now()
+	for i, status := range []string{"worked", "worked", "failed"} {
+		entry := feedback.Feedback{
+			Timestamp:        now.Add(time.Duration(i) * time.Second),
+			UserPrompt:       "list files",
+			GeneratedCommand: "ls -la",
+			Status:           status,
+		}
+		if err := manager.SaveFeedback(entry); err != nil {
+			t.Fatalf("SaveFeedback failed: %v", err)
+		}
+	}
+
+	entries, err := manager.LoadFeedback()
+	if err != nil {
+		t.Fatalf("LoadFeedback failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Count != 2 || entries[1].Status != "failed" {
+		t.Errorf("Expected the repeated feedback to collapse with count 2, got %+v", entries)
+	}
+}
@@ -0,0 +1,120 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/history"
+	"github.com/spf13/viper"
+)
+
+func TestLastReturnsMostRecentEntry(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.Set("data_dir", t.TempDir())
+
+	store, err := history.NewStore()
+	if err != nil {
+		t.Fatalf("Failed to create history store: %v", err)
+	}
+
+	if _, err := store.Last(); !errors.Is(err, history.ErrEmpty) {
+		t.Errorf("Expected ErrEmpty for an empty history, got %v", err)
+	}
+
+	for _, command := range []string{"ls -la", "df -h"} {
+		if err := store.Record(history.Entry{Command: command}); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	last, err := store.Last()
+	if err != nil {
+		t.Fatalf("Last failed: %v", err)
+	}
+	if last.Command != "df -h" {
+		t.Errorf("Expected last command 'df -h', got %q", last.Command)
+	}
+}
+
+func TestLoadToleratesWindowsLineEndings(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	dir := t.TempDir()
+	viper.Set("data_dir", dir)
+
+	// A history file edited on Windows, with a lone \r from an older editor
+	content := "{\"command\":\"ls -la\"}\r\n" +
+		"{\"command\":\"pwd\"}\r" +
+		"{\"command\":\"df -h\"}\n" +
+		"\r\n"
+	if err := os.WriteFile(filepath.Join(dir, "history.jsonl"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to seed history: %v", err)
+	}
+
+	store, err := history.NewStore()
+	if err != nil {
+		t.Fatalf("Failed to create history store: %v", err)
+	}
+	if err := store.Record(history.Entry{Command: "uptime"}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	entries, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	want := []string{"ls -la", "pwd", "df -h", "uptime"}
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %d entries, got %+v", len(want), entries)
+	}
+	for i, command := range want {
+		if entries[i].Command != command {
+			t.Errorf("Entry %d: expected %q, got %q", i, command, entries[i].Command)
+		}
+	}
+}
+
+func TestDedupCollapsesRepeats(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.Set("data_dir", t.TempDir())
+	viper.Set("history.dedup", true)
+
+	store, err := history.NewStore()
+	if err != nil {
+		t.Fatalf("Failed to create history store: %v", err)
+	}
+
+	start := time.Now()
+	records := []history.Entry{
+		{Prompt: "find big files", Command: "du -ah . | sort -rh | head", Timestamp: start},
+		{Prompt: "Find  big files", Command: "du -ah .  | sort -rh | head", Timestamp: start.Add(time.Minute)},
+		{Prompt: "find big files", Command: "du -ah . | sort -rh | head", Timestamp: start.Add(2 * time.Minute)},
+		{Prompt: "find big files", Command: "find . -size +100M", Timestamp: start.Add(3 * time.Minute)},
+		{Prompt: "find big files", Command: "find . -size +100M", Timestamp: start.Add(time.Hour)},
+	}
+	for _, entry := range records {
+		if err := store.Record(entry); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	entries, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %+v", entries)
+	}
+	if entries[0].Count != 3 || !entries[0].Timestamp.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("Expected the repeats to collapse with count 3 and the latest timestamp, got %+v", entries[0])
+	}
+	if entries[1].Count != 0 || entries[2].Count != 0 {
+		t.Errorf("Expected a different command and a late repeat to be kept, got %+v", entries[1:])
+	}
+}
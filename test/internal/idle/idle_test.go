@@ -0,0 +1,131 @@
+package idle
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/idle"
+)
+
+// fakeClock is a clock whose time only moves when the test advances it
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	fired chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0), fired: make(chan time.Time)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// After ignores the duration; the test decides when the wait ends
+func (c *fakeClock) After(time.Duration) <-chan time.Time { return c.fired }
+
+func TestTimerResetAndExpiry(t *testing.T) {
+	clock := newFakeClock()
+	timer := idle.NewTimer(5*time.Minute, clock.Now, clock.After)
+
+	clock.Advance(4 * time.Minute)
+	if timer.Expired() || timer.Remaining() != time.Minute {
+		t.Errorf("Expected 1m remaining after 4m, got %v", timer.Remaining())
+	}
+
+	timer.Reset()
+	clock.Advance(4 * time.Minute)
+	if timer.Expired() {
+		t.Error("Expected input to restart the timeout")
+	}
+
+	clock.Advance(time.Minute)
+	if !timer.Expired() {
+		t.Error("Expected the timer to expire 5m after the last input")
+	}
+}
+
+func TestTimerDisabled(t *testing.T) {
+	clock := newFakeClock()
+	timer := idle.NewTimer(0, clock.Now, clock.After)
+
+	clock.Advance(24 * time.Hour)
+	if timer.Expired() {
+		t.Error("Expected a zero timeout never to expire")
+	}
+}
+
+func TestScanReturnsInputAndResets(t *testing.T) {
+	clock := newFakeClock()
+	timer := idle.NewTimer(5*time.Minute, clock.Now, clock.After)
+
+	reader, writer := io.Pipe()
+	scanner := bufio.NewScanner(reader)
+
+	result := make(chan error, 1)
+	var line string
+	go func() {
+		var err error
+		line, err = timer.Scan(scanner)
+		result <- err
+	}()
+
+	// A wait that ends before the timeout has passed keeps waiting
+	clock.Advance(3 * time.Minute)
+	clock.fired <- clock.Now()
+
+	writer.Write([]byte("list files\n"))
+	if err := <-result; err != nil || line != "list files" {
+		t.Fatalf("Expected the input line, got %q, %v", line, err)
+	}
+	if timer.Remaining() != 5*time.Minute {
+		t.Errorf("Expected input to reset the timer, got %v remaining", timer.Remaining())
+	}
+}
+
+func TestScanFiresOnIdle(t *testing.T) {
+	clock := newFakeClock()
+	timer := idle.NewTimer(5*time.Minute, clock.Now, clock.After)
+
+	// Nothing is ever written, as on an abandoned terminal
+	reader, _ := io.Pipe()
+	scanner := bufio.NewScanner(reader)
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := timer.Scan(scanner)
+		result <- err
+	}()
+
+	clock.Advance(5 * time.Minute)
+	clock.fired <- clock.Now()
+
+	if err := <-result; !errors.Is(err, idle.ErrIdle) {
+		t.Errorf("Expected ErrIdle, got %v", err)
+	}
+}
+
+func TestScanEndOfInput(t *testing.T) {
+	clock := newFakeClock()
+	timer := idle.NewTimer(5*time.Minute, clock.Now, clock.After)
+
+	reader, writer := io.Pipe()
+	writer.Close()
+
+	if _, err := timer.Scan(bufio.NewScanner(reader)); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/logger"
+)
+
+// Run with -race to catch unsynchronized initialization
+func TestGetLoggerConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(debug bool) {
+			defer wg.Done()
+			if debug {
+				logger.InitLogger(false, true)
+			}
+			if logger.GetLogger() == nil {
+				t.Error("GetLogger returned nil")
+			}
+		}(i%4 == 0)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,97 @@
+package output
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/output"
+)
+
+// fakeTimer fires immediately and counts how many ticks were requested. Ticks
+// after stallAfter never fire; onStall runs instead, e.g. to press Ctrl+C.
+type fakeTimer struct {
+	ticks      int
+	stallAfter int
+	onStall    func()
+}
+
+func (f *fakeTimer) after(d time.Duration) <-chan time.Time {
+	f.ticks++
+	if f.stallAfter > 0 && f.ticks > f.stallAfter {
+		if f.onStall != nil {
+			f.onStall()
+		}
+		return nil
+	}
+	ch := make(chan time.Time, 1)
+	ch <- time.Time{}
+	return ch
+}
+
+func TestCountdownCompletes(t *testing.T) {
+	stdout, _ := captureOutput(t)
+
+	timer := &fakeTimer{}
+	if err := output.Countdown(3, make(chan os.Signal), timer.after); err != nil {
+		t.Fatalf("Expected countdown to complete, got %v", err)
+	}
+
+	if timer.ticks != 3 {
+		t.Errorf("Expected 3 ticks, got %d", timer.ticks)
+	}
+	for _, n := range []string{"3", "2", "1"} {
+		if !strings.Contains(stdout.String(), "Running in "+n) {
+			t.Errorf("Expected countdown to show %s, got %q", n, stdout.String())
+		}
+	}
+}
+
+func TestCountdownAbort(t *testing.T) {
+	captureOutput(t)
+
+	// Ctrl+C arrives during the second second
+	abort := make(chan os.Signal, 1)
+	timer := &fakeTimer{stallAfter: 1, onStall: func() { abort <- syscall.SIGINT }}
+
+	err := output.Countdown(5, abort, timer.after)
+	if !errors.Is(err, output.ErrAborted) {
+		t.Fatalf("Expected ErrAborted, got %v", err)
+	}
+	if timer.ticks != 2 {
+		t.Errorf("Expected countdown to stop after 2 ticks, got %d", timer.ticks)
+	}
+}
+
+func TestCountdownZeroSecondsRunsImmediately(t *testing.T) {
+	captureOutput(t)
+
+	timer := &fakeTimer{}
+	if err := output.Countdown(0, nil, timer.after); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if timer.ticks != 0 {
+		t.Errorf("Expected no ticks, got %d", timer.ticks)
+	}
+}
+
+func TestPrintFinalCheck(t *testing.T) {
+	stdout, _ := captureOutput(t)
+
+	output.PrintFinalCheck(&ai.CommandResponse{
+		Command:     "tar -czf logs.tgz /var/log",
+		Explanation: "Archives the system logs. The archive is gzip-compressed.",
+	})
+
+	rendered := stdout.String()
+	if !strings.Contains(rendered, "tar -czf logs.tgz /var/log") || !strings.Contains(rendered, "Archives the system logs.") {
+		t.Errorf("Expected command and summary, got %q", rendered)
+	}
+	if strings.Contains(rendered, "gzip-compressed") {
+		t.Errorf("Expected only the first sentence, got %q", rendered)
+	}
+}
@@ -0,0 +1,98 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/clipboard"
+	"github.com/kodelint/shell-agent/internal/favorites"
+	"github.com/kodelint/shell-agent/internal/output"
+	"github.com/spf13/viper"
+)
+
+// captureClipboard records clipboard writes for the duration of a test
+func captureClipboard(t *testing.T) *[]string {
+	t.Helper()
+
+	var copied []string
+	clipboard.SetWriter(func(text string) error {
+		copied = append(copied, text)
+		return nil
+	})
+	t.Cleanup(func() { clipboard.SetWriter(nil) })
+	return &copied
+}
+
+func TestDeclineCopiesToClipboard(t *testing.T) {
+	captureOutput(t)
+	copied := captureClipboard(t)
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.Set("interactive.on_decline", "copy")
+
+	action, err := output.ParseDeclineAction(viper.GetString("interactive.on_decline"))
+	if err != nil {
+		t.Fatalf("ParseDeclineAction failed: %v", err)
+	}
+	if err := output.HandleDecline(action, "list files", "ls -la"); err != nil {
+		t.Fatalf("HandleDecline failed: %v", err)
+	}
+
+	if len(*copied) != 1 || (*copied)[0] != "ls -la" {
+		t.Errorf("Expected the command to be copied once, got %q", *copied)
+	}
+}
+
+func TestDeclineNothingLeavesClipboardAlone(t *testing.T) {
+	captureOutput(t)
+	copied := captureClipboard(t)
+
+	if err := output.HandleDecline(output.DeclineNothing, "list files", "ls -la"); err != nil {
+		t.Fatalf("HandleDecline failed: %v", err)
+	}
+	if len(*copied) != 0 {
+		t.Errorf("Expected no clipboard writes, got %q", *copied)
+	}
+}
+
+func TestDeclineSavesFavorite(t *testing.T) {
+	captureOutput(t)
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.Set("data_dir", t.TempDir())
+
+	if err := output.HandleDecline(output.DeclineSave, "disk usage", "du -sh ."); err != nil {
+		t.Fatalf("HandleDecline failed: %v", err)
+	}
+
+	store, err := favorites.NewStore()
+	if err != nil {
+		t.Fatalf("Failed to open favorites: %v", err)
+	}
+	list, err := store.List("")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(list) != 1 || list[0].Command != "du -sh ." || list[0].Prompt != "disk usage" {
+		t.Errorf("Expected the declined command to be saved, got %+v", list)
+	}
+}
+
+func TestParseDeclineAction(t *testing.T) {
+	for value, want := range map[string]output.DeclineAction{
+		"":        output.DeclineNothing,
+		"nothing": output.DeclineNothing,
+		"Copy":    output.DeclineCopy,
+		" save ":  output.DeclineSave,
+	} {
+		got, err := output.ParseDeclineAction(value)
+		if err != nil || got != want {
+			t.Errorf("ParseDeclineAction(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+
+	if _, err := output.ParseDeclineAction("print"); err == nil {
+		t.Error("Expected error for an unknown action")
+	}
+}
@@ -0,0 +1,78 @@
+package output
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/output"
+)
+
+// captureExecutions records commands instead of running them
+func captureExecutions(t *testing.T) *[]string {
+	t.Helper()
+
+	var executed []string
+	output.SetExecutor(func(command string) error {
+		executed = append(executed, command)
+		return nil
+	})
+	t.Cleanup(func() { output.SetExecutor(nil) })
+	return &executed
+}
+
+func TestExecAllowlist(t *testing.T) {
+	captureOutput(t)
+	executed := captureExecutions(t)
+
+	opts := output.ExecOptions{
+		AutoExecute: true,
+		Allowlist:   []string{"ls", "cat", "grep", "find", "git"},
+	}
+
+	allowed := []string{"ls -la", "git log --oneline | grep fix", "LANG=C /bin/ls"}
+	for _, command := range allowed {
+		if err := output.RunResponse(&ai.CommandResponse{Command: command}, opts); err != nil {
+			t.Errorf("%q: expected to run, got %v", command, err)
+		}
+	}
+	if len(*executed) != len(allowed) {
+		t.Fatalf("Expected %d commands to run, got %q", len(allowed), *executed)
+	}
+
+	refused := []string{"rm -rf ./build", "ls && curl example.com", "cat $(which rm)", "find . | xargs rm"}
+	for _, command := range refused {
+		if err := output.RunResponse(&ai.CommandResponse{Command: command}, opts); !errors.Is(err, output.ErrNotAllowed) {
+			t.Errorf("%q: expected ErrNotAllowed, got %v", command, err)
+		}
+	}
+	if len(*executed) != len(allowed) {
+		t.Errorf("Expected refused commands not to run, got %q", *executed)
+	}
+}
+
+func TestRequireConfirmForCriticalCommands(t *testing.T) {
+	captureOutput(t)
+	executed := captureExecutions(t)
+	output.SetTerminalCheck(func() bool { return false })
+	t.Cleanup(func() { output.SetTerminalCheck(nil) })
+
+	critical := &ai.CommandResponse{
+		Command:  "rm -rf ./build",
+		Findings: []ai.Finding{{Rule: ai.RuleDangerousPattern, Severity: ai.SeverityCritical}},
+	}
+
+	// Without a terminal the confirmation can't be given, so the command doesn't run
+	if err := output.RunResponse(critical, output.ExecOptions{AutoExecute: true, RequireConfirm: true}); !errors.Is(err, output.ErrDeclined) {
+		t.Errorf("Expected a critical command to need confirmation, got %v", err)
+	}
+	if err := output.RunResponse(critical, output.ExecOptions{AutoExecute: true}); err != nil {
+		t.Errorf("Expected the command to run with require_confirm off, got %v", err)
+	}
+	if err := output.RunResponse(critical, output.ExecOptions{AutoExecute: true, BlockDestructive: true}); !errors.Is(err, output.ErrBlocked) {
+		t.Errorf("Expected block_destructive to apply with require_confirm off, got %v", err)
+	}
+	if len(*executed) != 1 {
+		t.Errorf("Expected one command to run, got %q", *executed)
+	}
+}
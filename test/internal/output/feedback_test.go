@@ -0,0 +1,57 @@
+package output_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/output"
+)
+
+func TestShouldAskFeedback(t *testing.T) {
+	failed := errors.New("exit status 1")
+	sample := func() float64 { return 0.5 }
+
+	tests := []struct {
+		name   string
+		mode   output.FeedbackMode
+		runErr error
+		rate   float64
+		want   bool
+	}{
+		{"always after success", output.FeedbackAlways, nil, 0, true},
+		{"always after failure", output.FeedbackAlways, failed, 0, true},
+		{"on_failure after zero exit", output.FeedbackOnFailure, nil, 0, false},
+		{"on_failure after non-zero exit", output.FeedbackOnFailure, failed, 0, true},
+		{"never after failure", output.FeedbackNever, failed, 1, false},
+		{"sampled below rate", output.FeedbackSampled, nil, 0.6, true},
+		{"sampled above rate", output.FeedbackSampled, nil, 0.4, false},
+		{"sampled with zero rate", output.FeedbackSampled, failed, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := output.ShouldAskFeedback(tt.mode, tt.runErr, tt.rate, sample); got != tt.want {
+				t.Errorf("ShouldAskFeedback() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFeedbackMode(t *testing.T) {
+	for value, want := range map[string]output.FeedbackMode{
+		"":           output.FeedbackAlways,
+		"always":     output.FeedbackAlways,
+		"On_Failure": output.FeedbackOnFailure,
+		" never ":    output.FeedbackNever,
+		"sampled":    output.FeedbackSampled,
+	} {
+		got, err := output.ParseFeedbackMode(value)
+		if err != nil || got != want {
+			t.Errorf("ParseFeedbackMode(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+
+	if _, err := output.ParseFeedbackMode("sometimes"); err == nil {
+		t.Error("Expected error for an unknown mode")
+	}
+}
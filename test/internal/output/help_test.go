@@ -0,0 +1,34 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/output"
+	"github.com/kodelint/shell-agent/internal/system"
+)
+
+func TestHelpExamplesFollowOS(t *testing.T) {
+	t.Cleanup(func() { system.SetGOOS("") })
+
+	system.SetGOOS("windows")
+	stdout, _ := captureOutput(t)
+	output.PrintHelp()
+
+	help := stdout.String()
+	for _, want := range []string{"dir /a", "findstr"} {
+		if !strings.Contains(help, want) {
+			t.Errorf("Expected cmd.exe example %q in Windows help:\n%s", want, help)
+		}
+	}
+	if strings.Contains(help, "ls -la") {
+		t.Error("Expected no Unix examples in Windows help")
+	}
+
+	system.SetGOOS("linux")
+	stdout.Reset()
+	output.PrintHelp()
+	if help := stdout.String(); !strings.Contains(help, "ls -la") || strings.Contains(help, "dir /a") {
+		t.Errorf("Expected Unix examples in Linux help:\n%s", help)
+	}
+}
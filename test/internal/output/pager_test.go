@@ -0,0 +1,58 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/output"
+)
+
+func TestIsInteractive(t *testing.T) {
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"top", true},
+		{"sudo /usr/bin/vim /etc/hosts", true},
+		{"psql -U postgres", true},
+		{"ls -la", false},
+		{"find . -name '*.log'", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := output.IsInteractive(tt.command); got != tt.want {
+			t.Errorf("IsInteractive(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestPagedCommandWithoutTerminal(t *testing.T) {
+	// A pager that marks what it shows, which must not run without a terminal
+	t.Setenv("PAGER", "sed s/^/paged:/")
+
+	path := filepath.Join(t.TempDir(), "stdout")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = file
+	t.Cleanup(func() { os.Stdout = stdout })
+
+	err = output.ExecuteCommandPaged("echo hello")
+	os.Stdout = stdout
+	file.Close()
+	if err != nil {
+		t.Fatalf("ExecuteCommandPaged failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if string(data) != "hello\n" {
+		t.Errorf("Expected the unpaged output, got %q", data)
+	}
+}
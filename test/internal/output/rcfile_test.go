@@ -0,0 +1,65 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/output"
+)
+
+func TestAppendFunction(t *testing.T) {
+	rcfile := filepath.Join(t.TempDir(), ".bashrc")
+	if err := os.WriteFile(rcfile, []byte("export EDITOR=vim\n"), 0644); err != nil {
+		t.Fatalf("Failed to create rc file: %v", err)
+	}
+
+	body := "backup_folder() {\n  tar -czf \"$1.tar.gz\" \"$1\"\n}\n"
+	if err := output.AppendFunction(rcfile, "back up\na folder", body); err != nil {
+		t.Fatalf("AppendFunction failed: %v", err)
+	}
+	if err := output.AppendFunction(rcfile, "", "hello() { echo hi; }"); err != nil {
+		t.Fatalf("AppendFunction failed: %v", err)
+	}
+
+	data, err := os.ReadFile(rcfile)
+	if err != nil {
+		t.Fatalf("Failed to read rc file: %v", err)
+	}
+
+	want := "export EDITOR=vim\n" +
+		"\n# Added by shell-agent: back up a folder\nbackup_folder() {\n  tar -czf \"$1.tar.gz\" \"$1\"\n}\n" +
+		"\n# Added by shell-agent\nhello() { echo hi; }\n"
+	if string(data) != want {
+		t.Errorf("Unexpected rc file contents:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestAppendFunctionExpandsHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if err := output.AppendFunction("~/.zshrc", "greet", "greet() { echo hi; }"); err != nil {
+		t.Fatalf("AppendFunction failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".zshrc")); err != nil {
+		t.Errorf("Expected ~/.zshrc to be created: %v", err)
+	}
+}
+
+func TestAppendFunctionLeavesOtherUsersHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Chdir(t.TempDir())
+
+	// ~user is not the current user's home, so it is a relative path here
+	if err := output.AppendFunction("~nobody", "greet", "greet() { echo hi; }"); err != nil {
+		t.Fatalf("AppendFunction failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, "nobody")); !os.IsNotExist(err) {
+		t.Error("Expected ~nobody not to be expanded into the home directory")
+	}
+	if _, err := os.Stat("~nobody"); err != nil {
+		t.Errorf("Expected ~nobody to be written as given: %v", err)
+	}
+}
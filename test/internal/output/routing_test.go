@@ -0,0 +1,96 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/output"
+)
+
+// captureOutput redirects both streams to buffers for the duration of a test
+func captureOutput(t *testing.T) (stdout, stderr *bytes.Buffer) {
+	t.Helper()
+
+	stdout, stderr = &bytes.Buffer{}, &bytes.Buffer{}
+	output.SetOutput(stdout)
+	output.SetErrorOutput(stderr)
+	t.Cleanup(func() {
+		output.SetOutput(nil)
+		output.SetErrorOutput(nil)
+		output.ResetRoutes()
+	})
+	return stdout, stderr
+}
+
+func TestRouteExplanationToStderr(t *testing.T) {
+	stdout, stderr := captureOutput(t)
+
+	for category, stream := range map[output.Category]string{
+		output.CategoryCommand:     "stdout",
+		output.CategoryExplanation: "stderr",
+		output.CategoryWarning:     "stderr",
+		output.CategoryStatus:      "stderr",
+	} {
+		if err := output.SetRoute(category, stream); err != nil {
+			t.Fatalf("SetRoute failed: %v", err)
+		}
+	}
+
+	output.PrintResponse(&ai.CommandResponse{
+		Command:     "ls -la",
+		Explanation: "Lists all files",
+		Warning:     "Shows hidden files",
+		Confidence:  0.9,
+	})
+
+	if stdout.String() != "ls -la\n" {
+		t.Errorf("Expected stdout to contain only the command, got %q", stdout.String())
+	}
+	for _, want := range []string{"Lists all files", "Shows hidden files", "90%"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("Expected stderr to contain %q, got %q", want, stderr.String())
+		}
+	}
+}
+
+func TestDefaultRoutesToStdout(t *testing.T) {
+	stdout, stderr := captureOutput(t)
+
+	output.PrintResponse(&ai.CommandResponse{Command: "ls -la", Explanation: "Lists all files"})
+	output.PrintWarning("careful")
+
+	if stderr.Len() != 0 {
+		t.Errorf("Expected nothing on stderr by default, got %q", stderr.String())
+	}
+	for _, want := range []string{"Generated Command", "ls -la", "Lists all files", "careful"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("Expected stdout to contain %q, got %q", want, stdout.String())
+		}
+	}
+}
+
+func TestSetRouteRejectsInvalidValues(t *testing.T) {
+	captureOutput(t)
+
+	if err := output.SetRoute("banner", "stdout"); err == nil {
+		t.Error("Expected error for unknown category")
+	}
+	if err := output.SetRoute(output.CategoryCommand, "file"); err == nil {
+		t.Error("Expected error for unknown stream")
+	}
+}
+
+func TestDiagnosticsGoToStderr(t *testing.T) {
+	stdout, stderr := captureOutput(t)
+
+	output.PrintDiagnostic("ai.temperature is out of range")
+
+	if stdout.Len() != 0 {
+		t.Errorf("Expected nothing on stdout, got %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "ai.temperature is out of range") {
+		t.Errorf("Expected the diagnostic on stderr, got %q", stderr.String())
+	}
+}
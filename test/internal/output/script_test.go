@@ -0,0 +1,88 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/output"
+)
+
+func TestRenderScript(t *testing.T) {
+	response := &ai.CommandResponse{
+		Command:     "mkdir -p backup && cp -r notes backup/",
+		Explanation: "Copies your notes into a backup folder",
+		Warning:     "Existing files in backup/ are overwritten",
+	}
+	opts := output.ScriptOptions{Shebang: "#!/usr/bin/env bash", StrictMode: "set -euo pipefail"}
+
+	script := output.RenderScript("back up my notes", response, opts)
+
+	if !strings.HasPrefix(script, "#!/usr/bin/env bash\n") {
+		t.Errorf("Expected the script to start with the shebang, got %q", script)
+	}
+	for _, want := range []string{
+		"# Generated by shell-agent: back up my notes\n",
+		"# Copies your notes into a backup folder\n",
+		"# WARNING: Existing files in backup/ are overwritten\n",
+		"\nset -euo pipefail\n",
+		"# Step 1\nmkdir -p backup && cp -r notes backup/\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("Expected %q in script:\n%s", want, script)
+		}
+	}
+	if strings.Index(script, "set -euo pipefail") > strings.Index(script, "mkdir") {
+		t.Error("Expected strict mode before the first step")
+	}
+
+	// Steps from the model carry their own explanations, and strict mode can be turned off
+	response = &ai.CommandResponse{Steps: []ai.Step{
+		{Command: "git fetch origin", Explanation: "Get the latest branches"},
+		{Command: "git rebase origin/main", Explanation: "Replay your work on top"},
+	}}
+	script = output.RenderScript("update my branch", response, output.ScriptOptions{Shebang: "#!/bin/bash"})
+	if strings.Contains(script, "set -") {
+		t.Errorf("Expected no strict mode line, got:\n%s", script)
+	}
+	if !strings.Contains(script, "# Step 2: Replay your work on top\ngit rebase origin/main\n") {
+		t.Errorf("Expected commented steps, got:\n%s", script)
+	}
+}
+
+func TestRenderScriptKeepsCommandsWhole(t *testing.T) {
+	// Splitting any of these into lines under set -e would change what they do
+	for _, command := range []string{
+		"for f in *.log; do gzip \"$f\"; done",
+		"test -d build && echo yes",
+		"make || make clean && make",
+		"[[ -f a && -f b ]] && diff a b",
+	} {
+		script := output.RenderScript("", &ai.CommandResponse{Command: command}, output.ScriptOptions{StrictMode: "set -e"})
+
+		if !strings.Contains(script, "# Step 1\n"+command+"\n") || strings.Contains(script, "Step 2") {
+			t.Errorf("Expected %q as a single step, got:\n%s", command, script)
+		}
+	}
+}
+
+func TestWriteScriptIsExecutable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backup.sh")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	if err := output.WriteScript(path, "#!/bin/sh\nls\n"); err != nil {
+		t.Fatalf("WriteScript failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.Mode().Perm()&0100 == 0 {
+		t.Errorf("Expected the script to be executable, got %v", info.Mode())
+	}
+}
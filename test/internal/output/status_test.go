@@ -0,0 +1,63 @@
+package output
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/output"
+	"github.com/spf13/viper"
+)
+
+func TestPrintStatusJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ai.OllamaListResponse{Models: []ai.OllamaModel{{Name: "llama3.2:3b"}}})
+	}))
+	t.Cleanup(server.Close)
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Setenv("OLLAMA_HOST", server.URL)
+	viper.Set("data_dir", t.TempDir())
+	viper.Set("ai.model_path", t.TempDir())
+
+	stdout, _ := captureOutput(t)
+
+	if err := output.PrintStatusJSON(output.BuildStatus()); err != nil {
+		t.Fatalf("PrintStatusJSON failed: %v", err)
+	}
+
+	var status struct {
+		Model *struct {
+			Name       string `json:"name"`
+			Downloaded bool   `json:"downloaded"`
+			Path       string `json:"path"`
+		} `json:"model"`
+		Ollama struct {
+			URL       string `json:"url"`
+			Available *bool  `json:"available"`
+		} `json:"ollama"`
+		System *struct {
+			OS string `json:"os"`
+		} `json:"system"`
+		FeedbackCount *int `json:"feedback_count"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &status); err != nil {
+		t.Fatalf("Expected valid JSON, got %q: %v", stdout.String(), err)
+	}
+
+	if status.Model == nil || status.Model.Name == "" || status.Model.Path == "" {
+		t.Errorf("Expected model name and path, got %+v", status.Model)
+	}
+	if status.Ollama.Available == nil || !*status.Ollama.Available {
+		t.Errorf("Expected Ollama to be reported available, got %q", stdout.String())
+	}
+	if status.Ollama.URL != server.URL {
+		t.Errorf("Expected Ollama URL %q, got %q", server.URL, status.Ollama.URL)
+	}
+	if status.System == nil || status.System.OS == "" || status.FeedbackCount == nil {
+		t.Errorf("Expected system info and feedback count, got %q", stdout.String())
+	}
+}
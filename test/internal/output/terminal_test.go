@@ -0,0 +1,57 @@
+package output
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/output"
+)
+
+// withoutTerminal makes prompts behave as if stdin were piped
+func withoutTerminal(t *testing.T) {
+	t.Helper()
+
+	output.SetTerminalCheck(func() bool { return false })
+	t.Cleanup(func() { output.SetTerminalCheck(nil) })
+}
+
+func TestPromptsWithoutTerminalUseSafeDefaults(t *testing.T) {
+	captureOutput(t)
+	withoutTerminal(t)
+
+	if output.PromptExecuteCommand() {
+		t.Error("Expected execution to be declined without a terminal")
+	}
+	if output.PromptRiskConfirmation(80, 50) {
+		t.Error("Expected risk confirmation to be declined without a terminal")
+	}
+
+	status, err := output.PromptForFeedback()
+	if status != "" || err != nil {
+		t.Errorf("Expected feedback to be skipped, got %q, %v", status, err)
+	}
+
+	if _, err := output.PromptModelSelection(nil); !errors.Is(err, output.ErrNoTerminal) {
+		t.Errorf("Expected ErrNoTerminal from model selection, got %v", err)
+	}
+
+	selected, err := output.PromptCandidateSelection([]ai.Candidate{{Command: "ls -la"}, {Command: "ls"}})
+	if err != nil || selected != "ls -la" {
+		t.Errorf("Expected the best candidate, got %q, %v", selected, err)
+	}
+}
+
+func TestRunResponseWithoutTerminalDeclines(t *testing.T) {
+	captureOutput(t)
+	withoutTerminal(t)
+	executed := captureExecutions(t)
+
+	err := output.RunResponse(&ai.CommandResponse{Command: "ls"}, output.ExecOptions{ConfirmCommands: true})
+	if !errors.Is(err, output.ErrDeclined) {
+		t.Errorf("Expected ErrDeclined, got %v", err)
+	}
+	if len(*executed) != 0 {
+		t.Errorf("Expected nothing to run, got %q", *executed)
+	}
+}
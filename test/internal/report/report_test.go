@@ -0,0 +1,133 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/output"
+	"github.com/kodelint/shell-agent/internal/report"
+	"github.com/kodelint/shell-agent/internal/system"
+	"github.com/spf13/viper"
+)
+
+func TestRedact(t *testing.T) {
+	settings := map[string]any{
+		"ai": map[string]any{
+			"provider": "openai",
+			"openai":   map[string]any{"api_key": "sk-secret", "base_url": "https://api.openai.com/v1"},
+			"ollama":   map[string]any{"api_key": ""},
+		},
+		"debug": true,
+	}
+
+	redacted := report.Redact(settings)
+	aiSettings := redacted["ai"].(map[string]any)
+	if got := aiSettings["openai"].(map[string]any)["api_key"]; got != report.Redacted {
+		t.Errorf("Expected the API key to be redacted, got %v", got)
+	}
+	if got := aiSettings["openai"].(map[string]any)["base_url"]; got != "https://api.openai.com/v1" {
+		t.Errorf("Expected other settings to be kept, got %v", got)
+	}
+	if got := aiSettings["ollama"].(map[string]any)["api_key"]; got != "" {
+		t.Errorf("Expected an unset secret to stay empty, got %v", got)
+	}
+
+	// The original settings are left unchanged
+	if settings["ai"].(map[string]any)["openai"].(map[string]any)["api_key"] != "sk-secret" {
+		t.Error("Expected Redact to copy the settings")
+	}
+}
+
+func TestLoadLastWithoutRequest(t *testing.T) {
+	viper.Reset()
+	viper.Set("data_dir", t.TempDir())
+
+	if _, err := report.LoadLast(); !errors.Is(err, report.ErrNoLastRequest) {
+		t.Errorf("Expected ErrNoLastRequest, got %v", err)
+	}
+}
+
+func TestBundleForLastRequest(t *testing.T) {
+	viper.Reset()
+	viper.Set("data_dir", t.TempDir())
+	viper.Set("ai.provider", "openai")
+	viper.Set("ai.openai.api_key", "sk-secret")
+
+	raw := `{"command": "find . -name '*.log' -size +100M", "confidence": 0.8}`
+	err := report.SaveLast(report.LastRequest{
+		Request: "find large log files",
+		Trace: &ai.Trace{
+			Provider:    "openai",
+			Model:       "gpt-4o-mini",
+			Prompt:      "Generate a command: find large log files",
+			RawResponse: raw,
+		},
+		Result: &ai.CommandResponse{Command: "find . -name '*.log' -size +100M", Confidence: 0.8},
+	})
+	if err != nil {
+		t.Fatalf("SaveLast failed: %v", err)
+	}
+
+	last, err := report.LoadLast()
+	if err != nil {
+		t.Fatalf("LoadLast failed: %v", err)
+	}
+	status := &output.Status{System: system.NewSystemInfo().GetInfo()}
+	path, err := report.Write(t.TempDir(), report.Build("1.2.3", viper.AllSettings(), status, last))
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read bundle: %v", err)
+	}
+	if strings.Contains(string(data), "sk-secret") {
+		t.Fatal("Expected the API key to be redacted from the bundle")
+	}
+
+	var bundle struct {
+		Version string `json:"version"`
+		Config  struct {
+			AI struct {
+				Provider string `json:"provider"`
+				OpenAI   struct {
+					APIKey string `json:"api_key"`
+				} `json:"openai"`
+			} `json:"ai"`
+		} `json:"config"`
+		Status struct {
+			System *system.Info `json:"system"`
+		} `json:"status"`
+		LastRequest struct {
+			Request string    `json:"request"`
+			Trace   *ai.Trace `json:"trace"`
+			Result  struct {
+				Command string `json:"command"`
+			} `json:"result"`
+		} `json:"last_request"`
+	}
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		t.Fatalf("Bundle is not valid JSON: %v", err)
+	}
+
+	if bundle.Version != "1.2.3" {
+		t.Errorf("Expected the version, got %q", bundle.Version)
+	}
+	if bundle.Config.AI.Provider != "openai" || bundle.Config.AI.OpenAI.APIKey != report.Redacted {
+		t.Errorf("Expected the resolved config with the key redacted, got %+v", bundle.Config.AI)
+	}
+	if bundle.Status.System == nil || bundle.Status.System.OS == "" {
+		t.Error("Expected system information")
+	}
+	if bundle.LastRequest.Trace == nil || bundle.LastRequest.Trace.RawResponse != raw {
+		t.Errorf("Expected the raw model response, got %+v", bundle.LastRequest.Trace)
+	}
+	if bundle.LastRequest.Result.Command != "find . -name '*.log' -size +100M" {
+		t.Errorf("Expected the parsed result, got %q", bundle.LastRequest.Result.Command)
+	}
+}
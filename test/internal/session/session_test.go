@@ -0,0 +1,73 @@
+package session
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/ai"
+	"github.com/kodelint/shell-agent/internal/session"
+	"github.com/spf13/viper"
+)
+
+func newTestStore(t *testing.T) *session.Store {
+	t.Helper()
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.Set("data_dir", t.TempDir())
+
+	store, err := session.NewStore()
+	if err != nil {
+		t.Fatalf("Failed to create session store: %v", err)
+	}
+	return store
+}
+
+func TestSessionRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+
+	messages := []ai.Message{
+		{Role: "user", Content: "find large log files"},
+		{Role: "assistant", Content: "find . -name '*.log' -size +100M"},
+		{Role: "user", Content: "now delete them"},
+		{Role: "assistant", Content: "find . -name '*.log' -size +100M -delete"},
+	}
+
+	if err := store.Save("cleanup", messages); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := store.Load("cleanup")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if loaded.Name != "cleanup" {
+		t.Errorf("Expected name 'cleanup', got %q", loaded.Name)
+	}
+	if !reflect.DeepEqual(loaded.Messages, messages) {
+		t.Errorf("Messages did not round-trip:\nwant %+v\ngot  %+v", messages, loaded.Messages)
+	}
+
+	names, err := store.List()
+	if err != nil || len(names) != 1 || names[0] != "cleanup" {
+		t.Errorf("Expected [cleanup], got %v (%v)", names, err)
+	}
+
+	if err := store.Delete("cleanup"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Load("cleanup"); err == nil {
+		t.Error("Expected error loading a deleted session")
+	}
+}
+
+func TestSessionRejectsUnsafeNames(t *testing.T) {
+	store := newTestStore(t)
+
+	for _, name := range []string{"", "../escape", "a/b", "name with spaces"} {
+		if err := store.Save(name, nil); err == nil {
+			t.Errorf("Expected invalid name %q to be rejected", name)
+		}
+	}
+}
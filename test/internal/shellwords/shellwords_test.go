@@ -0,0 +1,90 @@
+package shellwords
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/kodelint/shell-agent/internal/shellwords"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    []string
+	}{
+		{"empty", "", nil},
+		{"whitespace only", "  \t ", nil},
+		{"simple", "ls -la /tmp", []string{"ls", "-la", "/tmp"}},
+		{"extra whitespace", "  ls \t -la\n/tmp  ", []string{"ls", "-la", "/tmp"}},
+		{"single quoted spaces", "grep 'hello world' file.txt", []string{"grep", "hello world", "file.txt"}},
+		{"double quoted spaces", `find . -name "my file.txt"`, []string{"find", ".", "-name", "my file.txt"}},
+		{"adjacent quotes join", `echo 'a b'"c d"e`, []string{"echo", "a bc de"}},
+		{"empty quoted words", `printf '' ""`, []string{"printf", "", ""}},
+		{"escaped space", `cat my\ file.txt`, []string{"cat", "my file.txt"}},
+		{"escaped quote outside quotes", `echo it\'s`, []string{"echo", "it's"}},
+		{"backslash literal in single quotes", `echo 'a\nb'`, []string{"echo", `a\nb`}},
+		{"escaped double quote", `echo "say \"hi\""`, []string{"echo", `say "hi"`}},
+		{"escaped dollar in double quotes", `echo "\$HOME"`, []string{"echo", "$HOME"}},
+		{"other backslash kept in double quotes", `echo "a\tb"`, []string{"echo", `a\tb`}},
+		{"escaped backslash", `echo "C:\\temp"`, []string{"echo", `C:\temp`}},
+		{"single quote inside double quotes", `echo "don't"`, []string{"echo", "don't"}},
+		{"double quote inside single quotes", `echo 'say "hi"'`, []string{"echo", `say "hi"`}},
+		{"line continuation", "tar -czf a.tgz \\\n  dir", []string{"tar", "-czf", "a.tgz", "dir"}},
+		{"operators are words", "ps aux | grep ssh", []string{"ps", "aux", "|", "grep", "ssh"}},
+		{"unicode", "echo 'héllo wörld'", []string{"echo", "héllo wörld"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := shellwords.SplitCommand(tt.command)
+			if err != nil {
+				t.Fatalf("SplitCommand(%q) failed: %v", tt.command, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitCommand(%q) = %q, want %q", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitCommandErrors(t *testing.T) {
+	tests := []struct {
+		command string
+		want    error
+	}{
+		{`echo 'unterminated`, shellwords.ErrUnbalancedQuote},
+		{`echo "unterminated`, shellwords.ErrUnbalancedQuote},
+		{`echo "escaped at end\"`, shellwords.ErrUnbalancedQuote},
+		{`echo trailing\`, shellwords.ErrTrailingEscape},
+	}
+
+	for _, tt := range tests {
+		if _, err := shellwords.SplitCommand(tt.command); !errors.Is(err, tt.want) {
+			t.Errorf("SplitCommand(%q): expected %v, got %v", tt.command, tt.want, err)
+		}
+	}
+}
+
+func TestQuoteRoundTrips(t *testing.T) {
+	tests := map[string]string{
+		"":             "''",
+		"build/a.log":  "build/a.log",
+		"my file.txt":  "'my file.txt'",
+		"*.tmp":        "'*.tmp'",
+		"it's":         `'it'\''s'`,
+		"$HOME":        "'$HOME'",
+		"--name=a,b@c": "--name=a,b@c",
+	}
+
+	for word, want := range tests {
+		quoted := shellwords.Quote(word)
+		if quoted != want {
+			t.Errorf("Quote(%q) = %q, want %q", word, quoted, want)
+		}
+		if got, err := shellwords.SplitCommand(quoted); err != nil || len(got) != 1 || got[0] != word {
+			t.Errorf("SplitCommand(%q) = %q, %v, want [%q]", quoted, got, err, word)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package shutdown
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/shutdown"
+)
+
+func TestSignalFlushesBufferedWriter(t *testing.T) {
+	manager := shutdown.New()
+
+	var file bytes.Buffer
+	writer := bufio.NewWriter(&file)
+	manager.OnShutdown(writer.Flush)
+
+	// Simulate a write that is still in progress when the signal arrives
+	done := manager.Begin()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		writer.WriteString(`{"status":"worked"}` + "\n")
+		done()
+	}()
+
+	signals := make(chan os.Signal, 1)
+	exited := make(chan string, 1)
+	go manager.HandleSignals(signals, time.Second, func() {
+		exited <- file.String()
+	})
+
+	signals <- syscall.SIGTERM
+
+	select {
+	case content := <-exited:
+		if content != `{"status":"worked"}`+"\n" {
+			t.Errorf("Expected flushed entry before exit, got %q", content)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Shutdown did not exit")
+	}
+
+	if manager.Context().Err() == nil {
+		t.Error("Expected in-flight context to be cancelled")
+	}
+}
+
+func TestShutdownGracePeriod(t *testing.T) {
+	manager := shutdown.New()
+
+	// A write that never completes must not block shutdown past the grace period
+	manager.Begin()
+
+	flushed := false
+	manager.OnShutdown(func() error {
+		flushed = true
+		return nil
+	})
+
+	start := time.Now()
+	manager.Shutdown(100 * time.Millisecond)
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Shutdown took %v, expected it to honor the grace period", elapsed)
+	}
+	if !flushed {
+		t.Error("Expected shutdown hooks to run after the grace period")
+	}
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/kodelint/shell-agent/internal/store"
+)
+
+type record struct {
+	ID      int    `json:"id"`
+	Command string `json:"command"`
+}
+
+const mixedEndings = "{\"id\":1,\"command\":\"ls\"}\r\n" +
+	"{\"id\":2,\"command\":\"pwd\"}\n" +
+	"{\"id\":3,\"command\":\"df -h\"}\r" +
+	"\r\n" +
+	"  \n" +
+	"{\"id\":4,\"command\":\"uptime\"}"
+
+func readRecords(t *testing.T, data string, oneByte bool) []record {
+	t.Helper()
+
+	var reader io.Reader = strings.NewReader(data)
+	if oneByte {
+		// Reading a byte at a time splits \r\n across reads
+		reader = iotest.OneByteReader(reader)
+	}
+
+	var records []record
+	err := store.ReadLines(reader, func(line []byte) {
+		var rec record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			t.Errorf("Failed to parse line %q: %v", line, err)
+			return
+		}
+		records = append(records, rec)
+	})
+	if err != nil {
+		t.Fatalf("ReadLines failed: %v", err)
+	}
+	return records
+}
+
+func TestReadLinesMixedLineEndings(t *testing.T) {
+	want := []record{{1, "ls"}, {2, "pwd"}, {3, "df -h"}, {4, "uptime"}}
+
+	for _, oneByte := range []bool{false, true} {
+		if got := readRecords(t, mixedEndings, oneByte); !reflect.DeepEqual(got, want) {
+			t.Errorf("oneByte=%v: expected %+v, got %+v", oneByte, want, got)
+		}
+	}
+}
+
+func TestAppendJSONLineUsesLF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records.jsonl")
+
+	// Start from a file written on Windows
+	if err := os.WriteFile(path, []byte("{\"id\":1,\"command\":\"ls\"}\r\n"), 0644); err != nil {
+		t.Fatalf("Failed to seed file: %v", err)
+	}
+	if err := store.AppendJSONLine(path, record{ID: 2, Command: "pwd"}); err != nil {
+		t.Fatalf("AppendJSONLine failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if !strings.HasSuffix(string(data), "{\"id\":2,\"command\":\"pwd\"}\n") || strings.Count(string(data), "\r") != 1 {
+		t.Errorf("Expected the appended record to end with \\n only, got %q", data)
+	}
+
+	if got := readRecords(t, string(data), false); len(got) != 2 {
+		t.Errorf("Expected 2 records, got %+v", got)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+
+	for _, content := range []string{"first", "second"} {
+		if err := store.WriteFileAtomic(path, []byte(content)); err != nil {
+			t.Fatalf("WriteFileAtomic failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "second" {
+		t.Errorf("Expected the last write, got %q, %v", data, err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("Expected no temporary file to be left behind")
+	}
+}
@@ -0,0 +1,134 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kodelint/shell-agent/internal/watch"
+)
+
+// fakeTimers hands each debounce timer to the test, which decides when it fires
+type fakeTimers struct {
+	started chan chan time.Time
+}
+
+func newFakeTimers() *fakeTimers {
+	return &fakeTimers{started: make(chan chan time.Time, 16)}
+}
+
+func (f *fakeTimers) After(time.Duration) <-chan time.Time {
+	timer := make(chan time.Time, 1)
+	f.started <- timer
+	return timer
+}
+
+// next returns the timer started by the latest change
+func (f *fakeTimers) next(t *testing.T) chan time.Time {
+	t.Helper()
+	select {
+	case timer := <-f.started:
+		return timer
+	case <-time.After(time.Second):
+		t.Fatal("Expected a debounce timer to start")
+		return nil
+	}
+}
+
+// expectTrigger fails unless a trigger arrives
+func expectTrigger(t *testing.T, triggers <-chan struct{}) {
+	t.Helper()
+	select {
+	case _, ok := <-triggers:
+		if !ok {
+			t.Fatal("Expected a trigger, the channel was closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a trigger")
+	}
+}
+
+// expectNoTrigger fails if a trigger arrives shortly
+func expectNoTrigger(t *testing.T, triggers <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-triggers:
+		t.Fatal("Expected no trigger")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestDebounceCoalescesRapidSaves(t *testing.T) {
+	timers := newFakeTimers()
+	changes := make(chan struct{})
+	triggers := watch.Debounce(changes, time.Second, timers.After)
+
+	// Three saves in quick succession restart the quiet period each time
+	var stale []chan time.Time
+	for i := 0; i < 3; i++ {
+		changes <- struct{}{}
+		stale = append(stale, timers.next(t))
+	}
+	last := stale[len(stale)-1]
+
+	// Timers from earlier saves no longer count
+	stale[0] <- time.Time{}
+	expectNoTrigger(t, triggers)
+
+	last <- time.Time{}
+	expectTrigger(t, triggers)
+	expectNoTrigger(t, triggers)
+
+	// A later save triggers again
+	changes <- struct{}{}
+	timers.next(t) <- time.Time{}
+	expectTrigger(t, triggers)
+}
+
+func TestDebounceFlushesPendingOnClose(t *testing.T) {
+	timers := newFakeTimers()
+	changes := make(chan struct{})
+	triggers := watch.Debounce(changes, time.Second, timers.After)
+
+	changes <- struct{}{}
+	timers.next(t)
+	close(changes)
+
+	expectTrigger(t, triggers)
+	if _, ok := <-triggers; ok {
+		t.Error("Expected the triggers channel to close")
+	}
+}
+
+func TestDebounceWithoutChanges(t *testing.T) {
+	changes := make(chan struct{})
+	triggers := watch.Debounce(changes, time.Second, newFakeTimers().After)
+
+	close(changes)
+	if _, ok := <-triggers; ok {
+		t.Error("Expected no trigger without changes")
+	}
+}
+
+func TestPollDetectsChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "req.txt")
+	if err := os.WriteFile(path, []byte("list files"), 0644); err != nil {
+		t.Fatalf("Failed to write prompt file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := watch.Poll(ctx, path, 5*time.Millisecond)
+
+	expectNoTrigger(t, changes)
+
+	if err := os.WriteFile(path, []byte("list all files"), 0644); err != nil {
+		t.Fatalf("Failed to write prompt file: %v", err)
+	}
+	expectTrigger(t, changes)
+
+	cancel()
+	for range changes {
+	}
+}